@@ -29,11 +29,13 @@ import (
 	"k8s.io/client-go/rest"
 
 	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis"
+	monitoringv1alpha1 "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
 	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/controller"
 	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/version"
 
 	promev1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
 	certmgr "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	routev1 "github.com/openshift/api/route/v1"
 	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
 	kubemetrics "github.com/operator-framework/operator-sdk/pkg/kube-metrics"
 	"github.com/operator-framework/operator-sdk/pkg/leader"
@@ -54,6 +56,7 @@ var (
 	metricsHost               = "0.0.0.0"
 	metricsPort         int32 = 8383
 	operatorMetricsPort int32 = 8686
+	webhookPort               = 9443
 )
 var log = logf.Log.WithName("cmd")
 
@@ -65,6 +68,9 @@ func printVersion() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == renderCmd {
+		os.Exit(runRender(os.Args[2:]))
+	}
 	// Add the zap logger flag set to the CLI. The flag set must
 	// be added before calling pflag.Parse().
 	pflag.CommandLine.AddFlagSet(zap.FlagSet())
@@ -112,6 +118,8 @@ func main() {
 	mgr, err := manager.New(cfg, manager.Options{
 		Namespace:          namespace,
 		MetricsBindAddress: fmt.Sprintf("%s:%d", metricsHost, metricsPort),
+		Port:               webhookPort,
+		CertDir:            os.Getenv("WEBHOOK_CERT_DIR"),
 	})
 	if err != nil {
 		log.Error(err, "")
@@ -137,14 +145,28 @@ func main() {
 		log.Error(err, "")
 		os.Exit(1)
 	}
+
+	// Register OpenShift route schema. Routes are used only if cluster serves them
+	if err := routev1.Install(mgr.GetScheme()); err != nil {
+		log.Error(err, "Failed to register schema of OpenShift routes")
+		os.Exit(1)
+	}
 	// Setup all Controllers
 	if err := controller.AddToManager(mgr); err != nil {
 		log.Error(err, "")
 		os.Exit(1)
 	}
 
+	// Serve admission webhooks only when they are enabled because they need a tls certificate
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err := (&monitoringv1alpha1.PrometheusExt{}).SetupWebhookWithManager(mgr); err != nil {
+			log.Error(err, "Failed to create webhooks of PrometheusExt")
+			os.Exit(1)
+		}
+	}
+
 	// Add the Metrics Service
-	addMetrics(ctx, cfg, namespace)
+	addMetrics(ctx, cfg)
 
 	log.Info("Starting the Cmd.")
 
@@ -155,9 +177,8 @@ func main() {
 	}
 }
 
-// addMetrics will create the Services and Service Monitors to allow the operator export the metrics by using
-// the Prometheus operator
-func addMetrics(ctx context.Context, cfg *rest.Config, namespace string) {
+// addMetrics will create the Service to allow the operator export the metrics.
+func addMetrics(ctx context.Context, cfg *rest.Config) {
 	if err := serveCRMetrics(cfg); err != nil {
 		if errors.Is(err, k8sutil.ErrRunLocal) {
 			log.Info("Skipping CR metrics server creation; not running in a cluster.")
@@ -173,23 +194,10 @@ func addMetrics(ctx context.Context, cfg *rest.Config, namespace string) {
 	}
 
 	// Create Service object to expose the metrics port(s).
-	service, err := metrics.CreateMetricsService(ctx, cfg, servicePorts)
-	if err != nil {
+	// ServiceMonitor of the Service is shipped in deploy/operator_monitoring.yaml together with alert rules.
+	if _, err := metrics.CreateMetricsService(ctx, cfg, servicePorts); err != nil {
 		log.Info("Could not create metrics Service", "error", err.Error())
 	}
-
-	// CreateServiceMonitors will automatically create the prometheus-operator ServiceMonitor resources
-	// necessary to configure Prometheus to scrape metrics from this operator.
-	services := []*v1.Service{service}
-	_, err = metrics.CreateServiceMonitors(cfg, namespace, services)
-	if err != nil {
-		log.Info("Could not create ServiceMonitor object", "error", err.Error())
-		// If this operator is deployed to a cluster without the prometheus-operator running, it will return
-		// ErrServiceMonitorNotPresent, which can be used to safely skip ServiceMonitor creation.
-		if err == metrics.ErrServiceMonitorNotPresent {
-			log.Info("Install prometheus-operator in your cluster to create ServiceMonitor objects", "error", err.Error())
-		}
-	}
 }
 
 // serveCRMetrics gets the Operator/CustomResource GVKs and generates metrics based on those types.
@@ -0,0 +1,86 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/controller/prometheusext/model"
+	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/controller/prometheusext/render"
+)
+
+const renderCmd = "render"
+
+// runRender prints objects which the controller creates for a PrometheusExt yaml file without connecting to cluster.
+// Images are read from the same environment variables as the controller.
+func runRender(args []string) int {
+	flags := pflag.NewFlagSet(renderCmd, pflag.ContinueOnError)
+	file := flags.StringP("file", "f", "", "PrometheusExt yaml file, - for stdin")
+	namespace := flags.StringP("namespace", "n", "", "Namespace of objects, namespace of PrometheusExt or default if it is empty")
+	opts := render.Options{}
+	flags.StringVar(&opts.StorageClass, "storage-class", "", "Storage class of volumes if storageClassName is not set in PrometheusExt")
+	flags.StringVar(&opts.ClusterHost, "cluster-host", "", "Host name of cluster used in external urls of prometheus and alertmanager")
+	openshift := flags.Bool("openshift", true, "Render objects for OpenShift, or for plain Kubernetes if it is false")
+	ingressV1 := flags.Bool("ingress-v1", false, "Render networking.k8s.io/v1 ingresses instead of extensions/v1beta1 ingresses")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s %s -f prometheusext.yaml [flags]\n", os.Args[0], renderCmd)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *file == "" {
+		flags.Usage()
+		return 2
+	}
+	model.SetPlatform(model.Platform{OpenShift: *openshift, Routes: *openshift, IngressV1: *ingressV1})
+	if err := renderFile(*file, *namespace, opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func renderFile(file string, namespace string, opts render.Options) error {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return err
+	}
+	cr, err := render.ReadCR(data, namespace)
+	if err != nil {
+		return err
+	}
+	objs, err := render.Objects(cr, opts)
+	if err != nil {
+		return err
+	}
+	scheme, err := render.NewScheme()
+	if err != nil {
+		return err
+	}
+	return render.WriteYaml(os.Stdout, scheme, objs)
+}
@@ -0,0 +1,102 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AlertRouteSpec defines receivers and routes for alerts of the namespace of AlertRoute
+type AlertRouteSpec struct {
+	// Receivers used by routes of this AlertRoute. Secrets referred by receivers must be in the namespace of AlertRoute
+	Receivers []AlertmanagerReceiver `json:"receivers"`
+	// Routes for alerts of this namespace. Every route must have a receiver of this AlertRoute
+	// A namespace matcher is added to every route and its child routes
+	Routes []AlertmanagerRoute `json:"routes"`
+}
+
+// AlertRouteState is state of AlertRoute
+type AlertRouteState string
+
+const (
+	// AlertRouteAccepted means all routes are merged into alertmanager configuration
+	AlertRouteAccepted AlertRouteState = "Accepted"
+	// AlertRoutePartiallyAccepted means some routes are invalid and only valid routes are merged
+	AlertRoutePartiallyAccepted AlertRouteState = "PartiallyAccepted"
+	// AlertRouteRejected means no route is merged
+	AlertRouteRejected AlertRouteState = "Rejected"
+	// AlertRouteNotApplied means alertmanager configuration is not managed by the operator
+	AlertRouteNotApplied AlertRouteState = "NotApplied"
+)
+
+// AlertRouteStatus defines the observed state of AlertRoute
+type AlertRouteStatus struct {
+	// Whether routes are merged into alertmanager configuration of every PrometheusExt
+	// +listType=map
+	// +listMapKey=prometheusExt
+	PrometheusExts []AlertRoutePrometheusExtStatus `json:"prometheusExts,omitempty"`
+}
+
+// AlertRoutePrometheusExtStatus is state of AlertRoute in alertmanager configuration of one PrometheusExt
+type AlertRoutePrometheusExtStatus struct {
+	// Namespace and name of PrometheusExt, like ibm-common-services/ibm-monitoring
+	PrometheusExt string `json:"prometheusExt"`
+	// Whether routes are merged into alertmanager configuration
+	State AlertRouteState `json:"state,omitempty"`
+	// Human readable message about the state
+	Message string `json:"message,omitempty"`
+	// Validation errors of receivers and routes
+	Errors []AlertRouteError `json:"errors,omitempty"`
+	// The generation of AlertRoute which was merged last time
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// AlertRouteError describes why a route or receiver is rejected
+type AlertRouteError struct {
+	// Path of the invalid field, for example routes[0] or receivers[1]
+	Field string `json:"field"`
+	// Error message
+	Message string `json:"message"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AlertRoute declares alertmanager receivers and routes for alerts of its namespace
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=alertroutes,scope=Namespaced
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.prometheusExts[*].state`
+// +operator-sdk:gen-csv:customresourcedefinitions.displayName="Alert Route"
+type AlertRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AlertRouteSpec   `json:"spec,omitempty"`
+	Status AlertRouteStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AlertRouteList contains a list of AlertRoute
+type AlertRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AlertRoute `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AlertRoute{}, &AlertRouteList{})
+}
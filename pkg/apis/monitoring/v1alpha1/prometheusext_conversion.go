@@ -0,0 +1,24 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v1alpha1
+
+// ConversionDataAnn is annotation which keeps fields not existing in the other API version during conversion,
+// so that objects are not changed by a round trip between versions
+const ConversionDataAnn = "monitoring.operator.ibm.com/conversion-data"
+
+// Hub marks v1alpha1 as the version which other versions of PrometheusExt are converted to and from
+func (*PrometheusExt) Hub() {}
@@ -31,7 +31,7 @@ type PrometheusExtSpec struct {
 	// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
 	// Add custom validation using kubebuilder tags: https://book-v1.book.kubebuilder.io/beyond_basics/generating_crd.html
 
-	//Host value of route cp-console
+	//Host value of route cp-console. It is used when management-ingress-info configmap does not exist, like on plain Kubernetes
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
 	ClusterAddress string `json:"clusterAddress,omitempty"`
 	//Port value of route cp-console
@@ -61,6 +61,9 @@ type PrometheusExtSpec struct {
 	//Storage class name used by Prometheus and Alertmanager
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
 	StorageClassName string `json:"storageClassName"`
+	//Keep persistent volume claims of Prometheus and Alertmanager when CR is deleted, true by default
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	RetainStorage *bool `json:"retainStorage,omitempty"`
 	//Configurations for mcm monitor controller
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
 	MCMMonitor `json:"mcmMonitor,omitempty"`
@@ -81,7 +84,28 @@ type PrometheusExtSpec struct {
 	HelmReleasesMonitor `json:"helmReleasesMonitor,omitempty"`
 
 	PrometheusOperator `json:"prometheusOperator,omitempty"`
-	NodeSelector       map[string]string `json:"nodeSelector,omitempty"`
+	//Node selector of pods of components which have no node selector in their scheduling
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+}
+
+// SchedulingSpec defines where pods of a component are scheduled
+type SchedulingSpec struct {
+	// Node selector of pods
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations of pods
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+	// Affinity of pods
+	Affinity *v1.Affinity `json:"affinity,omitempty"`
+	// Priority class name of pods
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+}
+
+// DeploymentSchedulingSpec defines where pods of a component deployed by a Deployment are scheduled.
+// Pods of prometheus and alertmanager are created by prometheus operator, which does not support topology spread constraints
+type DeploymentSchedulingSpec struct {
+	SchedulingSpec `json:",inline"`
+	// Topology spread constraints of pods
+	TopologySpreadConstraints []v1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
 }
 
 //PrometheusOperator defines inforamtion for prometheus operator deployment
@@ -95,6 +119,8 @@ type PrometheusOperator struct {
 	//Image of prometheus config reloader
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
 	PrometheusConfigImage string `json:"prometheusConfigImage,omitempty"`
+	//Scheduling of prometheus operator pod
+	Scheduling DeploymentSchedulingSpec `json:"scheduling,omitempty"`
 }
 
 //IAMProvider defines information for iam
@@ -127,6 +153,75 @@ type PrometheusConfig struct {
 	NodeMemoryThreshold int                     `json:"nodeMemoryThreshold"`
 	NodeCPUThreshold    int                     `json:"nodeCPUThreshold"`
 	LogLevel            string                  `json:"logLevel,omitempty"`
+	// Customization of default prometheus rules by rule name, for example node-memory-usage
+	DefaultRules map[string]DefaultRule `json:"defaultRules,omitempty"`
+	// How prometheus is exposed out of cluster. It is an Ingress of IBM Cloud Pak management ingress by default
+	Exposure *Exposure `json:"exposure,omitempty"`
+	// Number of prometheus pods, 1 by default. Every replica scrapes all targets and has its own persistent volume
+	// +kubebuilder:validation:Minimum=1
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Scheduling of prometheus pods
+	Scheduling SchedulingSpec `json:"scheduling,omitempty"`
+	// Endpoints which samples are sent to. Their health is reported in status
+	RemoteWrite []RemoteWrite `json:"remoteWrite,omitempty"`
+	// Endpoints which samples are read from by queries
+	RemoteRead []RemoteRead `json:"remoteRead,omitempty"`
+	// Thanos sidecar of prometheus pods. It is not added if it is not set
+	Thanos *ThanosConfig `json:"thanos,omitempty"`
+}
+
+// ThanosConfig defines Thanos sidecar which serves Store API of prometheus data and uploads it to object storage
+type ThanosConfig struct {
+	// Image of Thanos sidecar. THANOS_IMAGE environment variable of the operator or quay.io/thanos/thanos:v0.10.1 by default
+	Image string `json:"image,omitempty"`
+	// Resources of Thanos sidecar container
+	Resources v1.ResourceRequirements `json:"resources,omitempty"`
+	// Secret key which stores Thanos object storage configuration, for example of a S3 bucket.
+	// If it is not set, blocks are not uploaded and sidecar only serves data of local storage
+	ObjectStorageConfig *v1.SecretKeySelector `json:"objectStorageConfig,omitempty"`
+	// External labels added to all series besides cluster label with cluster name and replica label with pod name
+	ExternalLabels map[string]string `json:"externalLabels,omitempty"`
+}
+
+// ExposureType is kind of object which exposes a component out of cluster
+type ExposureType string
+
+const (
+	// ExposureIngress exposes component with an Ingress
+	ExposureIngress ExposureType = "Ingress"
+	// ExposureRoute exposes component with an OpenShift Route with reencrypt tls termination
+	ExposureRoute ExposureType = "Route"
+	// ExposureNone does not expose component
+	ExposureNone ExposureType = "None"
+)
+
+// Exposure defines how a component is exposed out of cluster
+type Exposure struct {
+	// Kind of object which exposes the component, one of Ingress, Route and None. Default is Ingress
+	// +kubebuilder:validation:Enum=Ingress;Route;None
+	Type ExposureType `json:"type,omitempty"`
+	// Class of Ingress. If it is empty, ingress class annotation of IBM Cloud Pak management ingress is used
+	IngressClassName string `json:"ingressClassName,omitempty"`
+	// Host of Ingress or Route. Ingress accepts all hosts and OpenShift generates host of Route if it is empty
+	Host string `json:"host,omitempty"`
+	// Name of tls secret of Ingress
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+	// Annotations of Ingress or Route. If they are not set, Ingress has annotations of IBM Cloud Pak management ingress
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// DefaultRule customizes a default prometheus rule
+type DefaultRule struct {
+	// Whether the rule is created, true by default. Disabled rules are deleted
+	Enabled *bool `json:"enabled,omitempty"`
+	// Threshold of the rule. It is ignored by rules without threshold
+	Threshold *int32 `json:"threshold,omitempty"`
+	// How long the condition must be true before alert fires, for example 10m
+	For string `json:"for,omitempty"`
+	// Value of severity label of alerts
+	Severity string `json:"severity,omitempty"`
+	// URL of runbook which is added as runbook_url annotation of alerts
+	RunbookURL string `json:"runbookURL,omitempty"`
 }
 
 // AlertManagerConfig defines configuration of AlertManager object
@@ -138,16 +233,54 @@ type AlertManagerConfig struct {
 	ServicePort        int32                   `json:"servicePort"`
 	Resources          v1.ResourceRequirements `json:"resource,omitempty"`
 	LogLevel           string                  `json:"logLevel,omitempty"`
+	// Receivers, routes and inhibit rules of alertmanager. If it is not set the operator creates a default configuration
+	// once and never changes it
+	Routing *AlertmanagerRouting `json:"routing,omitempty"`
+	// How alertmanager is exposed out of cluster. It is an Ingress of IBM Cloud Pak management ingress by default
+	Exposure *Exposure `json:"exposure,omitempty"`
+	// Number of alertmanager pods, 1 by default. Replicas form a cluster which shares silences and notifications
+	// +kubebuilder:validation:Minimum=1
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Scheduling of alertmanager pods
+	Scheduling SchedulingSpec `json:"scheduling,omitempty"`
 }
 
+const (
+	// CertsModeCertManager means tls certificates are created by cert-manager
+	CertsModeCertManager = "certManager"
+	// CertsModeSelfManaged means tls certificates are created by the operator with its own CA
+	CertsModeSelfManaged = "selfManaged"
+)
+
 // Certs defines certification used by monitoring stack
 type Certs struct {
+	// Who creates tls certificates, certManager or selfManaged. certManager by default
+	// If it is selfManaged, operator creates its own CA and key pairs and rotates them before expiry
+	// +kubebuilder:validation:Enum=certManager;selfManaged
+	Mode string `json:"mode,omitempty"`
 	// Prometheus and AlertManager' tls cert. Define the secret name. It is created by cert manager
 	MonitoringSecret string `json:"monitoringSecret"`
 	//Define monitoring stack client(prometheus, exporters)'s tls cert secret. It is created by cert manager
 	MonitoringClientSecret string `json:"monitoringClientSecret"`
 	// The issure name. It is used to generated tls certificates. All tls certificates of monitoring operators need to use same Issuer
+	// cs-ca-issuer by default
 	Issuer string `json:"issuer"`
+	// Kind of the issuer, Issuer or ClusterIssuer. Issuer by default
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	IssuerKind string `json:"issuerKind,omitempty"`
+	// Duration of certificates, cert-manager default value is used if it is not set
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// How long before expiry certificates are renewed, cert-manager default value is used if it is not set
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+	// Private key algorithm of certificates, rsa or ecdsa
+	// +kubebuilder:validation:Enum=rsa;ecdsa
+	KeyAlgorithm string `json:"keyAlgorithm,omitempty"`
+	// Private key size of certificates
+	KeySize int `json:"keySize,omitempty"`
+	// Extra dns names added to monitoring certificate
+	ExtraDNSNames []string `json:"extraDNSNames,omitempty"`
+	// IP addresses added to monitoring certificate
+	IPAddresses []string `json:"ipAddresses,omitempty"`
 	// If it is false, user can create secret manually before creating CR and operator will not recreate it if secret exists already
 	// If it is true, operator will recreate secret if it is not created by certificate (cert-manager)
 	AutoClean bool `json:"autoClean,omitempty"`
@@ -163,6 +296,8 @@ type MCMMonitor struct {
 	HelperImage        string                  `json:"helpeImage,omitempty"`
 	ServiceAccountName string                  `json:"serviceAccount,omitempty"`
 	Resources          v1.ResourceRequirements `json:"resource,omitempty"`
+	// Scheduling of mcm monitoring controller pod
+	Scheduling DeploymentSchedulingSpec `json:"scheduling,omitempty"`
 }
 
 // PrometheusExtStatus defines the observed state of PrometheusExt
@@ -189,6 +324,91 @@ type PrometheusExtStatus struct {
 	//Status of required configmaps, created or not
 	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
 	Configmaps string `json:"configmaps,omitempty"`
+	//The generation of the CR which was fully reconciled last time
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	//Expiry dates of tls certificates
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	Certificates []CertificateStatus `json:"certificates,omitempty"`
+	//Hash of secrets and configmaps currently applied to pods of each component
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	ConfigHashes map[string]string `json:"configHashes,omitempty"`
+	//Conditions of the monitoring stack and of each of its components
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	Conditions []PrometheusExtCondition `json:"conditions,omitempty"`
+	//What reconciliation is waiting for when it is requeued
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	Waiting *WaitingStatus `json:"waiting,omitempty"`
+	//Alertmanager silence created for maintenance window
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	Maintenance *MaintenanceStatus `json:"maintenance,omitempty"`
+	//Health of remote write endpoints of prometheus
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	RemoteWrite []RemoteWriteStatus `json:"remoteWrite,omitempty"`
+}
+
+// CertificateStatus shows expiry date of tls certificate stored in a secret
+type CertificateStatus struct {
+	// Name of the secret
+	SecretName string `json:"secretName"`
+	// Expiry date of the certificate
+	NotAfter metav1.Time `json:"notAfter,omitempty"`
+}
+
+// WaitingStatus shows what reconciliation is waiting for and since when
+type WaitingStatus struct {
+	// Category of the requeue, one of WaitingForDependency, Conflict and Transient
+	Category string `json:"category"`
+	// What reconciliation is waiting for
+	Reason string `json:"reason"`
+	// Time when reconciliation started to wait for it
+	Since metav1.Time `json:"since"`
+}
+
+// MaintenanceStatus shows Alertmanager silence created for maintenance window
+type MaintenanceStatus struct {
+	// ID of the silence
+	SilenceID string `json:"silenceID"`
+	// End time of the maintenance window and the silence
+	EndsAt metav1.Time `json:"endsAt"`
+}
+
+// ConditionType is type of PrometheusExt condition
+type ConditionType string
+
+const (
+	// ConditionAvailable is true when all components are created and ready
+	ConditionAvailable ConditionType = "Available"
+	// ConditionProgressing is true when operator is still rolling out the spec
+	ConditionProgressing ConditionType = "Progressing"
+	// ConditionDegraded is true when reconciliation failed with an unexpected error
+	ConditionDegraded ConditionType = "Degraded"
+	// ConditionPrometheus reports status of the managed Prometheus
+	ConditionPrometheus ConditionType = "Prometheus"
+	// ConditionAlertmanager reports status of the managed Alertmanager
+	ConditionAlertmanager ConditionType = "Alertmanager"
+	// ConditionRouter reports status of router configmaps
+	ConditionRouter ConditionType = "Router"
+	// ConditionCertificates reports status of tls secrets
+	ConditionCertificates ConditionType = "Certificates"
+	// ConditionMCMController reports status of mcm monitoring controller deployment
+	ConditionMCMController ConditionType = "MCMController"
+	// ConditionPrometheusOperator reports status of prometheus operator deployment
+	ConditionPrometheusOperator ConditionType = "PrometheusOperator"
+)
+
+// PrometheusExtCondition describes state of PrometheusExt or one of its components
+type PrometheusExtCondition struct {
+	// Type of condition
+	Type ConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown
+	Status v1.ConditionStatus `json:"status"`
+	// One word reason for the condition's last transition
+	Reason string `json:"reason,omitempty"`
+	// Human readable message indicating details about the transition
+	Message string `json:"message,omitempty"`
+	// Last time the condition transitioned from one status to another
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -196,6 +416,7 @@ type PrometheusExtStatus struct {
 // PrometheusExt will start Prometheus and Alertmanager instances with RBAC enabled. It will also enable Multicloud monitoring
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:path=prometheusexts,scope=Namespaced
+// +kubebuilder:storageversion
 // +operator-sdk:gen-csv:customresourcedefinitions.displayName="Prometheus Operator Extension"
 type PrometheusExt struct {
 	metav1.TypeMeta   `json:",inline"`
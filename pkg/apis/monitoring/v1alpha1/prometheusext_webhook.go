@@ -0,0 +1,438 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v1alpha1
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+
+	prommodel "github.com/prometheus/common/model"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+const (
+	// DefaultRetention is default retention of prometheus data
+	DefaultRetention = "24h"
+	// DefaultScrapeInterval is default scrape interval of prometheus
+	DefaultScrapeInterval = "1m"
+	// DefaultEvaluationInterval is default rule evaluation interval of prometheus
+	DefaultEvaluationInterval = "1m"
+	// DefaultPVSize is default storage size for alertmanager and prometheus
+	DefaultPVSize = "10Gi"
+	// DefaultClusterDomain is default cluster domain name
+	DefaultClusterDomain = "cluster.local"
+	// DefaultClusterName is default cluster name
+	DefaultClusterName = "mycluster"
+	// DefaultIssuer is default issuer of tls certificates
+	DefaultIssuer = "cs-ca-issuer"
+	// DefaultIssuerKind is default kind of issuer of tls certificates
+	DefaultIssuerKind = "Issuer"
+)
+
+// imageRegexp matches image references like quay.io/opencloudio/prometheus:v2.15.2 or prometheus@sha256:<digest>
+var (
+	imageRegexp    = regexp.MustCompile(`^(?:[a-zA-Z0-9.-]+(?::[0-9]+)?/)?[a-z0-9]+(?:[._-]+[a-z0-9]+)*(?:/[a-z0-9]+(?:[._-]+[a-z0-9]+)*)*(?::[\w][\w.-]{0,127})?(?:@sha256:[a-f0-9]{64})?$`)
+	imageTagRegexp = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+)
+
+// SetupWebhookWithManager registers defaulting and validating webhooks of PrometheusExt in webhook server of manager
+func (r *PrometheusExt) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(r).Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-monitoring-operator-ibm-com-v1alpha1-prometheusext,mutating=true,failurePolicy=fail,groups=monitoring.operator.ibm.com,resources=prometheusexts,verbs=create;update,versions=v1alpha1,name=mprometheusext.monitoring.operator.ibm.com
+
+var _ webhook.Defaulter = &PrometheusExt{}
+
+// Default fills in default values which are used by the operator when fields are empty
+func (r *PrometheusExt) Default() {
+	spec := &r.Spec
+	setDefault(&spec.ClusterName, DefaultClusterName)
+	setDefault(&spec.ClusterDomain, DefaultClusterDomain)
+	setDefault(&spec.PrometheusConfig.Retention, DefaultRetention)
+	setDefault(&spec.PrometheusConfig.ScrapeInterval, DefaultScrapeInterval)
+	setDefault(&spec.PrometheusConfig.EvaluationInterval, DefaultEvaluationInterval)
+	setDefault(&spec.PrometheusConfig.PVSize, DefaultPVSize)
+	setDefault(&spec.AlertManagerConfig.PVSize, DefaultPVSize)
+	setDefault(&spec.Certs.Mode, CertsModeCertManager)
+	setDefault(&spec.Certs.Issuer, DefaultIssuer)
+	setDefault(&spec.Certs.IssuerKind, DefaultIssuerKind)
+}
+
+func setDefault(value *string, defaultValue string) {
+	if *value == "" {
+		*value = defaultValue
+	}
+}
+
+// +kubebuilder:webhook:path=/validate-monitoring-operator-ibm-com-v1alpha1-prometheusext,mutating=false,failurePolicy=fail,groups=monitoring.operator.ibm.com,resources=prometheusexts,verbs=create;update,versions=v1alpha1,name=vprometheusext.monitoring.operator.ibm.com
+
+var _ webhook.Validator = &PrometheusExt{}
+
+// ValidateCreate validates PrometheusExt when it is created
+func (r *PrometheusExt) ValidateCreate() error {
+	return r.invalid(r.validate())
+}
+
+// ValidateUpdate validates PrometheusExt when it is updated. CR being deleted is not validated, so its finalizer can
+// always be removed. Only invalid fields which are changed are rejected, so CRs created before a validation was added
+// can still be updated
+func (r *PrometheusExt) ValidateUpdate(old runtime.Object) error {
+	if r.DeletionTimestamp != nil {
+		return nil
+	}
+	errs := r.validate()
+	if oldCR, ok := old.(*PrometheusExt); ok {
+		errs = changedErrors(errs, oldCR.validate())
+	}
+	return r.invalid(errs)
+}
+
+// ValidateDelete does nothing because PrometheusExt can always be deleted
+func (r *PrometheusExt) ValidateDelete() error {
+	return nil
+}
+
+func (r *PrometheusExt) validate() field.ErrorList {
+	var errs field.ErrorList
+	spec := field.NewPath("spec")
+
+	prom := spec.Child("prometheusConfig")
+	errs = append(errs, validateDuration(prom.Child("retention"), r.Spec.PrometheusConfig.Retention)...)
+	errs = append(errs, validateDuration(prom.Child("scrapeInterval"), r.Spec.PrometheusConfig.ScrapeInterval)...)
+	errs = append(errs, validateDuration(prom.Child("evaluationInterval"), r.Spec.PrometheusConfig.EvaluationInterval)...)
+	errs = append(errs, validateQuantity(prom.Child("pvSize"), r.Spec.PrometheusConfig.PVSize)...)
+	errs = append(errs, validatePort(prom.Child("servicePort"), r.Spec.PrometheusConfig.ServicePort, true)...)
+	errs = append(errs, validateImage(prom.Child("imageRepo"), r.Spec.PrometheusConfig.ImageRepo)...)
+	errs = append(errs, validateImageTag(prom.Child("imageTag"), r.Spec.PrometheusConfig.ImageTag)...)
+	errs = append(errs, validateReplicas(prom.Child("replicas"), r.Spec.PrometheusConfig.Replicas)...)
+	errs = append(errs, validateScheduling(prom.Child("scheduling"), r.Spec.PrometheusConfig.Scheduling)...)
+	for name, rule := range r.Spec.PrometheusConfig.DefaultRules {
+		errs = append(errs, validateDuration(prom.Child("defaultRules").Key(name).Child("for"), rule.For)...)
+	}
+	for i, rw := range r.Spec.PrometheusConfig.RemoteWrite {
+		errs = append(errs, validateRemoteWrite(prom.Child("remoteWrite").Index(i), rw)...)
+	}
+	for i, rr := range r.Spec.PrometheusConfig.RemoteRead {
+		errs = append(errs, validateRemoteRead(prom.Child("remoteRead").Index(i), rr)...)
+	}
+	if thanos := r.Spec.PrometheusConfig.Thanos; thanos != nil {
+		errs = append(errs, validateThanos(prom.Child("thanos"), thanos)...)
+	}
+
+	am := spec.Child("alertManagerConfig")
+	errs = append(errs, validateQuantity(am.Child("pvSize"), r.Spec.AlertManagerConfig.PVSize)...)
+	errs = append(errs, validatePort(am.Child("servicePort"), r.Spec.AlertManagerConfig.ServicePort, true)...)
+	errs = append(errs, validateImage(am.Child("imageRepo"), r.Spec.AlertManagerConfig.ImageRepo)...)
+	errs = append(errs, validateImageTag(am.Child("imageTag"), r.Spec.AlertManagerConfig.ImageTag)...)
+	errs = append(errs, validateReplicas(am.Child("replicas"), r.Spec.AlertManagerConfig.Replicas)...)
+	errs = append(errs, validateScheduling(am.Child("scheduling"), r.Spec.AlertManagerConfig.Scheduling)...)
+
+	errs = append(errs, validatePort(spec.Child("grafanaSvcPort"), r.Spec.GrafanaSvcPort, false)...)
+	errs = append(errs, validatePort(spec.Child("iamProvider", "idProviderSvcPort"), r.Spec.IAMProvider.IDProviderSvcPort, false)...)
+	errs = append(errs, validatePort(spec.Child("iamProvider", "idManagementSvcPort"), r.Spec.IAMProvider.IDManagementSvcPort, false)...)
+	errs = append(errs, validatePort(spec.Child("helmReleasesMonitor", "port"), r.Spec.HelmReleasesMonitor.Port, false)...)
+
+	errs = append(errs, validateImage(spec.Child("routerImage"), r.Spec.RouterImage)...)
+	errs = append(errs, validateImage(spec.Child("prometheusOperator", "image"), r.Spec.PrometheusOperator.Image)...)
+	errs = append(errs, validateImage(spec.Child("prometheusOperator", "configmapReloadImage"), r.Spec.PrometheusOperator.ConfigmapReloadImage)...)
+	errs = append(errs, validateImage(spec.Child("prometheusOperator", "prometheusConfigImage"), r.Spec.PrometheusOperator.PrometheusConfigImage)...)
+	errs = append(errs, validateImage(spec.Child("mcmMonitor", "image"), r.Spec.MCMMonitor.Image)...)
+	errs = append(errs, validateImage(spec.Child("mcmMonitor", "helpeImage"), r.Spec.MCMMonitor.HelperImage)...)
+	for i, secret := range r.Spec.ImagePullSecrets {
+		errs = append(errs, validateName(spec.Child("imagePullSecrets").Index(i), secret)...)
+	}
+
+	errs = append(errs, validateScheduling(spec.Child("prometheusOperator", "scheduling"), r.Spec.PrometheusOperator.Scheduling.SchedulingSpec)...)
+	errs = append(errs, validateScheduling(spec.Child("mcmMonitor", "scheduling"), r.Spec.MCMMonitor.Scheduling.SchedulingSpec)...)
+	errs = append(errs, validateNodeSelector(spec.Child("nodeSelector"), r.Spec.NodeSelector)...)
+	errs = append(errs, r.validateCerts(spec.Child("certs"))...)
+	return errs
+}
+
+func (r *PrometheusExt) invalid(errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.NewInvalid(SchemeGroupVersion.WithKind("PrometheusExt").GroupKind(), r.Name, errs)
+}
+
+//changedErrors returns errors which old object does not have with the same field and value
+func changedErrors(errs, oldErrs field.ErrorList) field.ErrorList {
+	var changed field.ErrorList
+	for _, err := range errs {
+		found := false
+		for _, oldErr := range oldErrs {
+			if err.Type == oldErr.Type && err.Field == oldErr.Field && reflect.DeepEqual(err.BadValue, oldErr.BadValue) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			changed = append(changed, err)
+		}
+	}
+	return changed
+}
+
+func (r *PrometheusExt) validateCerts(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	certs := r.Spec.Certs
+	if certs.MonitoringSecret == "" {
+		errs = append(errs, field.Required(path.Child("monitoringSecret"), "name of tls secret is required"))
+	} else {
+		errs = append(errs, validateName(path.Child("monitoringSecret"), certs.MonitoringSecret)...)
+	}
+	if certs.MonitoringClientSecret == "" {
+		errs = append(errs, field.Required(path.Child("monitoringClientSecret"), "name of tls secret is required"))
+	} else {
+		errs = append(errs, validateName(path.Child("monitoringClientSecret"), certs.MonitoringClientSecret)...)
+	}
+	if certs.MonitoringSecret != "" && certs.MonitoringSecret == certs.MonitoringClientSecret {
+		errs = append(errs, field.Invalid(path.Child("monitoringClientSecret"), certs.MonitoringClientSecret, "must be different from monitoringSecret"))
+	}
+	if certs.Duration != nil && certs.Duration.Duration <= 0 {
+		errs = append(errs, field.Invalid(path.Child("duration"), certs.Duration.String(), "must be positive"))
+	}
+	if certs.RenewBefore != nil && certs.RenewBefore.Duration <= 0 {
+		errs = append(errs, field.Invalid(path.Child("renewBefore"), certs.RenewBefore.String(), "must be positive"))
+	}
+	if certs.Duration != nil && certs.RenewBefore != nil && certs.RenewBefore.Duration >= certs.Duration.Duration {
+		errs = append(errs, field.Invalid(path.Child("renewBefore"), certs.RenewBefore.String(), "must be less than duration"))
+	}
+	return errs
+}
+
+func validateDuration(path *field.Path, value string) field.ErrorList {
+	if value == "" {
+		return nil
+	}
+	if _, err := prommodel.ParseDuration(value); err != nil {
+		return field.ErrorList{field.Invalid(path, value, err.Error())}
+	}
+	return nil
+}
+
+func validateQuantity(path *field.Path, value string) field.ErrorList {
+	if value == "" {
+		return nil
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, value, err.Error())}
+	}
+	if quantity.Sign() <= 0 {
+		return field.ErrorList{field.Invalid(path, value, "must be positive")}
+	}
+	return nil
+}
+
+func validatePort(path *field.Path, port int32, required bool) field.ErrorList {
+	if port == 0 && !required {
+		return nil
+	}
+	var errs field.ErrorList
+	for _, msg := range validation.IsValidPortNum(int(port)) {
+		errs = append(errs, field.Invalid(path, port, msg))
+	}
+	return errs
+}
+
+func validateReplicas(path *field.Path, replicas *int32) field.ErrorList {
+	if replicas != nil && *replicas < 1 {
+		return field.ErrorList{field.Invalid(path, *replicas, "must be at least 1")}
+	}
+	return nil
+}
+
+func validateImage(path *field.Path, image string) field.ErrorList {
+	if image == "" || imageRegexp.MatchString(image) {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(path, image, "must be an image reference like registry/repository:tag or registry/repository@sha256:digest")}
+}
+
+func validateImageTag(path *field.Path, tag string) field.ErrorList {
+	if tag == "" || imageTagRegexp.MatchString(tag) {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(path, tag, "must be a valid image tag")}
+}
+
+func validateName(path *field.Path, name string) field.ErrorList {
+	var errs field.ErrorList
+	for _, msg := range validation.IsDNS1123Subdomain(name) {
+		errs = append(errs, field.Invalid(path, name, msg))
+	}
+	return errs
+}
+
+//validateScheduling validates scheduling of a component
+func validateScheduling(path *field.Path, scheduling SchedulingSpec) field.ErrorList {
+	return validateNodeSelector(path.Child("nodeSelector"), scheduling.NodeSelector)
+}
+
+func validateNodeSelector(path *field.Path, selector map[string]string) field.ErrorList {
+	var errs field.ErrorList
+	for key, value := range selector {
+		for _, msg := range validation.IsQualifiedName(key) {
+			errs = append(errs, field.Invalid(path.Key(key), key, fmt.Sprintf("invalid label key: %s", msg)))
+		}
+		for _, msg := range validation.IsValidLabelValue(value) {
+			errs = append(errs, field.Invalid(path.Key(key), value, fmt.Sprintf("invalid label value: %s", msg)))
+		}
+	}
+	return errs
+}
+
+func validateRemoteWrite(path *field.Path, rw RemoteWrite) field.ErrorList {
+	errs := validateURL(path.Child("url"), rw.URL, true)
+	errs = append(errs, validateDuration(path.Child("remoteTimeout"), rw.RemoteTimeout)...)
+	errs = append(errs, validateURL(path.Child("proxyURL"), rw.ProxyURL, false)...)
+	errs = append(errs, validateRemoteAuth(path, rw.BasicAuth, rw.BearerToken, rw.TLSConfig)...)
+	for i, c := range rw.WriteRelabelConfigs {
+		errs = append(errs, validateRelabelConfig(path.Child("writeRelabelConfigs").Index(i), c)...)
+	}
+	if q := rw.QueueConfig; q != nil {
+		qpath := path.Child("queueConfig")
+		for name, value := range map[string]int{"capacity": q.Capacity, "minShards": q.MinShards, "maxShards": q.MaxShards, "maxSamplesPerSend": q.MaxSamplesPerSend} {
+			if value < 0 {
+				errs = append(errs, field.Invalid(qpath.Child(name), value, "must not be negative"))
+			}
+		}
+		if q.MaxShards != 0 && q.MinShards > q.MaxShards {
+			errs = append(errs, field.Invalid(qpath.Child("minShards"), q.MinShards, "must not be greater than maxShards"))
+		}
+		errs = append(errs, validateDuration(qpath.Child("batchSendDeadline"), q.BatchSendDeadline)...)
+		errs = append(errs, validateDuration(qpath.Child("minBackoff"), q.MinBackoff)...)
+		errs = append(errs, validateDuration(qpath.Child("maxBackoff"), q.MaxBackoff)...)
+	}
+	return errs
+}
+
+func validateRemoteRead(path *field.Path, rr RemoteRead) field.ErrorList {
+	errs := validateURL(path.Child("url"), rr.URL, true)
+	errs = append(errs, validateDuration(path.Child("remoteTimeout"), rr.RemoteTimeout)...)
+	errs = append(errs, validateURL(path.Child("proxyURL"), rr.ProxyURL, false)...)
+	errs = append(errs, validateRemoteAuth(path, rr.BasicAuth, rr.BearerToken, rr.TLSConfig)...)
+	return errs
+}
+
+//validateRemoteAuth validates credentials of a remote endpoint. Prometheus accepts only one of basic auth and bearer token
+func validateRemoteAuth(path *field.Path, basicAuth *RemoteBasicAuth, bearerToken *v1.SecretKeySelector, tls *RemoteTLSConfig) field.ErrorList {
+	var errs field.ErrorList
+	if basicAuth != nil {
+		errs = append(errs, validateSecretKey(path.Child("basicAuth", "username"), &basicAuth.Username)...)
+		errs = append(errs, validateSecretKey(path.Child("basicAuth", "password"), &basicAuth.Password)...)
+		if bearerToken != nil {
+			errs = append(errs, field.Forbidden(path.Child("bearerToken"), "must not be set with basicAuth"))
+		}
+	}
+	errs = append(errs, validateSecretKey(path.Child("bearerToken"), bearerToken)...)
+	if tls != nil {
+		errs = append(errs, validateSecretKey(path.Child("tlsConfig", "ca"), tls.CA)...)
+		errs = append(errs, validateSecretKey(path.Child("tlsConfig", "cert"), tls.Cert)...)
+		errs = append(errs, validateSecretKey(path.Child("tlsConfig", "key"), tls.Key)...)
+		if (tls.Cert == nil) != (tls.Key == nil) {
+			errs = append(errs, field.Required(path.Child("tlsConfig"), "cert and key must be set together"))
+		}
+	}
+	return errs
+}
+
+func validateRelabelConfig(path *field.Path, c RelabelConfig) field.ErrorList {
+	var errs field.ErrorList
+	if c.Regex != "" {
+		if _, err := regexp.Compile("^(?:" + c.Regex + ")$"); err != nil {
+			errs = append(errs, field.Invalid(path.Child("regex"), c.Regex, err.Error()))
+		}
+	}
+	for i, label := range c.SourceLabels {
+		if !prommodel.LabelName(label).IsValid() {
+			errs = append(errs, field.Invalid(path.Child("sourceLabels").Index(i), label, "invalid label name"))
+		}
+	}
+	action := c.Action
+	if action == "" {
+		action = "replace"
+	}
+	if (action == "replace" || action == "hashmod") && c.TargetLabel == "" {
+		errs = append(errs, field.Required(path.Child("targetLabel"), "required by "+action+" action"))
+	}
+	if action == "hashmod" && c.Modulus == 0 {
+		errs = append(errs, field.Required(path.Child("modulus"), "required by hashmod action"))
+	}
+	return errs
+}
+
+func validateURL(path *field.Path, value string, required bool) field.ErrorList {
+	if value == "" {
+		if required {
+			return field.ErrorList{field.Required(path, "url is required")}
+		}
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, value, err.Error())}
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return field.ErrorList{field.Invalid(path, value, "must be an absolute http or https url")}
+	}
+	return nil
+}
+
+func validateSecretKey(path *field.Path, selector *v1.SecretKeySelector) field.ErrorList {
+	if selector == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	if selector.Name == "" {
+		errs = append(errs, field.Required(path.Child("name"), "name of secret is required"))
+	} else {
+		errs = append(errs, validateName(path.Child("name"), selector.Name)...)
+	}
+	if selector.Key == "" {
+		errs = append(errs, field.Required(path.Child("key"), "key of secret is required"))
+	}
+	return errs
+}
+
+//validateThanos validates Thanos sidecar. Prometheus operator sets replica external label and the operator sets cluster label,
+//so they can not be overridden
+func validateThanos(path *field.Path, thanos *ThanosConfig) field.ErrorList {
+	errs := validateImage(path.Child("image"), thanos.Image)
+	errs = append(errs, validateSecretKey(path.Child("objectStorageConfig"), thanos.ObjectStorageConfig)...)
+	for name := range thanos.ExternalLabels {
+		if !prommodel.LabelName(name).IsValid() {
+			errs = append(errs, field.Invalid(path.Child("externalLabels").Key(name), name, "invalid label name"))
+		}
+		if name == "replica" || name == "cluster" {
+			errs = append(errs, field.Forbidden(path.Child("externalLabels").Key(name), "label is set by the operator"))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,59 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v1alpha1
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateUpdate(t *testing.T) {
+	newCR := func(retention, scrapeInterval string) *PrometheusExt {
+		return &PrometheusExt{
+			ObjectMeta: metav1.ObjectMeta{Name: "monitoring", Namespace: "ns"},
+			Spec: PrometheusExtSpec{
+				PrometheusConfig: PrometheusConfig{Retention: retention, ScrapeInterval: scrapeInterval},
+				Certs:            Certs{MonitoringSecret: "monitoring-certs", MonitoringClientSecret: "monitoring-client-certs"},
+			},
+		}
+	}
+	deleting := newCR("1m", "invalid")
+	now := metav1.Now()
+	deleting.DeletionTimestamp = &now
+	tests := []struct {
+		name    string
+		old     *PrometheusExt
+		new     *PrometheusExt
+		wantErr bool
+	}{
+		{name: "valid", old: newCR("24h", "1m"), new: newCR("48h", "1m"), wantErr: false},
+		{name: "invalid field added", old: newCR("24h", "1m"), new: newCR("24h", "invalid"), wantErr: true},
+		{name: "invalid field changed", old: newCR("24h", "invalid"), new: newCR("24h", "still-invalid"), wantErr: true},
+		{name: "unchanged invalid field", old: newCR("24h", "invalid"), new: newCR("48h", "invalid"), wantErr: false},
+		{name: "unchanged invalid field and another invalid field", old: newCR("24h", "invalid"), new: newCR("invalid", "invalid"), wantErr: true},
+		{name: "deleting", old: newCR("24h", "1m"), new: deleting, wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.new.ValidateUpdate(tt.old)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateUpdate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
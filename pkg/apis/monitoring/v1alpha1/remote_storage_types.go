@@ -0,0 +1,141 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RemoteWrite defines an endpoint which prometheus sends samples to
+// Secrets referred by it must be in the namespace of the CR
+type RemoteWrite struct {
+	// URL of the endpoint
+	URL string `json:"url"`
+	// Timeout of requests to the endpoint, 30s by default
+	RemoteTimeout string `json:"remoteTimeout,omitempty"`
+	// Basic authentication credentials of the endpoint
+	BasicAuth *RemoteBasicAuth `json:"basicAuth,omitempty"`
+	// Secret key which stores bearer token of the endpoint
+	BearerToken *v1.SecretKeySelector `json:"bearerToken,omitempty"`
+	// TLS configuration of the endpoint
+	TLSConfig *RemoteTLSConfig `json:"tlsConfig,omitempty"`
+	// URL of proxy used to connect to the endpoint
+	ProxyURL string `json:"proxyURL,omitempty"`
+	// Relabel configs applied to samples before they are sent
+	WriteRelabelConfigs []RelabelConfig `json:"writeRelabelConfigs,omitempty"`
+	// Tuning of the queue which buffers samples before they are sent
+	QueueConfig *RemoteWriteQueueConfig `json:"queueConfig,omitempty"`
+}
+
+// RemoteRead defines an endpoint which prometheus reads samples from
+// Secrets referred by it must be in the namespace of the CR
+type RemoteRead struct {
+	// URL of the endpoint
+	URL string `json:"url"`
+	// Timeout of requests to the endpoint, 30s by default
+	RemoteTimeout string `json:"remoteTimeout,omitempty"`
+	// Equality matchers which must be present in selectors of queries to read from the endpoint
+	RequiredMatchers map[string]string `json:"requiredMatchers,omitempty"`
+	// Whether the endpoint is read for queries of recent data which is in local storage too
+	ReadRecent bool `json:"readRecent,omitempty"`
+	// Basic authentication credentials of the endpoint
+	BasicAuth *RemoteBasicAuth `json:"basicAuth,omitempty"`
+	// Secret key which stores bearer token of the endpoint
+	BearerToken *v1.SecretKeySelector `json:"bearerToken,omitempty"`
+	// TLS configuration of the endpoint
+	TLSConfig *RemoteTLSConfig `json:"tlsConfig,omitempty"`
+	// URL of proxy used to connect to the endpoint
+	ProxyURL string `json:"proxyURL,omitempty"`
+}
+
+// RemoteBasicAuth defines basic authentication credentials stored in secrets
+type RemoteBasicAuth struct {
+	// Secret key which stores user name
+	Username v1.SecretKeySelector `json:"username"`
+	// Secret key which stores password
+	Password v1.SecretKeySelector `json:"password"`
+}
+
+// RemoteTLSConfig defines tls configuration of a remote endpoint. Secrets are mounted into prometheus pods
+type RemoteTLSConfig struct {
+	// Secret key which stores CA certificate used to verify the endpoint
+	CA *v1.SecretKeySelector `json:"ca,omitempty"`
+	// Secret key which stores client certificate
+	Cert *v1.SecretKeySelector `json:"cert,omitempty"`
+	// Secret key which stores private key of client certificate
+	Key *v1.SecretKeySelector `json:"key,omitempty"`
+	// Server name used to verify certificate of the endpoint
+	ServerName string `json:"serverName,omitempty"`
+	// Disable verification of certificate of the endpoint
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// RelabelConfig defines relabeling of samples
+type RelabelConfig struct {
+	// Labels whose values are concatenated and matched by regex
+	SourceLabels []string `json:"sourceLabels,omitempty"`
+	// Separator of concatenated source label values, ; by default
+	Separator string `json:"separator,omitempty"`
+	// Label which is written by replace and hashmod actions
+	TargetLabel string `json:"targetLabel,omitempty"`
+	// Regular expression matched against concatenated source label values, (.*) by default
+	Regex string `json:"regex,omitempty"`
+	// Modulus of hash of source label values used by hashmod action
+	Modulus uint64 `json:"modulus,omitempty"`
+	// Replacement of replace action, $1 by default
+	Replacement string `json:"replacement,omitempty"`
+	// Relabel action, replace by default
+	// +kubebuilder:validation:Enum=replace;keep;drop;hashmod;labelmap;labeldrop;labelkeep
+	Action string `json:"action,omitempty"`
+}
+
+// RemoteWriteQueueConfig defines tuning of remote write queue. Prometheus default value is used for fields which are not set
+type RemoteWriteQueueConfig struct {
+	// Number of samples buffered per shard before reading from WAL is blocked
+	Capacity int `json:"capacity,omitempty"`
+	// Minimum number of shards which send samples concurrently
+	MinShards int `json:"minShards,omitempty"`
+	// Maximum number of shards which send samples concurrently
+	MaxShards int `json:"maxShards,omitempty"`
+	// Maximum number of samples per request
+	MaxSamplesPerSend int `json:"maxSamplesPerSend,omitempty"`
+	// Maximum time a sample waits in buffer before it is sent
+	BatchSendDeadline string `json:"batchSendDeadline,omitempty"`
+	// Initial wait time before failed request is retried
+	MinBackoff string `json:"minBackoff,omitempty"`
+	// Maximum wait time before failed request is retried
+	MaxBackoff string `json:"maxBackoff,omitempty"`
+}
+
+// RemoteWriteStatus shows health of a remote write endpoint reported by prometheus_remote_storage_* metrics of prometheus
+type RemoteWriteStatus struct {
+	// URL of the endpoint
+	URL string `json:"url"`
+	// Whether samples are sent without failures and in time, one of True, False, Unknown
+	Healthy v1.ConditionStatus `json:"healthy"`
+	// Samples sent successfully per second in last 5 minutes
+	SucceededSamplesRate string `json:"succeededSamplesRate,omitempty"`
+	// Samples failed to send per second in last 5 minutes
+	FailedSamplesRate string `json:"failedSamplesRate,omitempty"`
+	// Seconds between newest sample ingested by prometheus and newest sample sent to the endpoint
+	LagSeconds *int64 `json:"lagSeconds,omitempty"`
+	// Human readable details of the health
+	Message string `json:"message,omitempty"`
+	// Last time the metrics were queried
+	LastProbeTime metav1.Time `json:"lastProbeTime,omitempty"`
+}
@@ -21,6 +21,8 @@
 package v1alpha1
 
 import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -28,31 +30,664 @@ import (
 func (in *AlertManagerConfig) DeepCopyInto(out *AlertManagerConfig) {
 	*out = *in
 	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Routing != nil {
+		in, out := &in.Routing, &out.Routing
+		*out = new(AlertmanagerRouting)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Exposure != nil {
+		in, out := &in.Exposure, &out.Exposure
+		*out = new(Exposure)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	in.Scheduling.DeepCopyInto(&out.Scheduling)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertManagerConfig.
+func (in *AlertManagerConfig) DeepCopy() *AlertManagerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertManagerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertRoute) DeepCopyInto(out *AlertRoute) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertRoute.
+func (in *AlertRoute) DeepCopy() *AlertRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AlertRoute) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertRouteError) DeepCopyInto(out *AlertRouteError) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertRouteError.
+func (in *AlertRouteError) DeepCopy() *AlertRouteError {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertRouteError)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertRouteList) DeepCopyInto(out *AlertRouteList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AlertRoute, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertRouteList.
+func (in *AlertRouteList) DeepCopy() *AlertRouteList {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertRouteList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AlertRouteList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertRoutePrometheusExtStatus) DeepCopyInto(out *AlertRoutePrometheusExtStatus) {
+	*out = *in
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]AlertRouteError, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertRoutePrometheusExtStatus.
+func (in *AlertRoutePrometheusExtStatus) DeepCopy() *AlertRoutePrometheusExtStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertRoutePrometheusExtStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertRouteSpec) DeepCopyInto(out *AlertRouteSpec) {
+	*out = *in
+	if in.Receivers != nil {
+		in, out := &in.Receivers, &out.Receivers
+		*out = make([]AlertmanagerReceiver, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Routes != nil {
+		in, out := &in.Routes, &out.Routes
+		*out = make([]AlertmanagerRoute, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertRouteSpec.
+func (in *AlertRouteSpec) DeepCopy() *AlertRouteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertRouteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertRouteStatus) DeepCopyInto(out *AlertRouteStatus) {
+	*out = *in
+	if in.PrometheusExts != nil {
+		in, out := &in.PrometheusExts, &out.PrometheusExts
+		*out = make([]AlertRoutePrometheusExtStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertRouteStatus.
+func (in *AlertRouteStatus) DeepCopy() *AlertRouteStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertRouteStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertmanagerEmailConfig) DeepCopyInto(out *AlertmanagerEmailConfig) {
+	*out = *in
+	if in.SendResolved != nil {
+		in, out := &in.SendResolved, &out.SendResolved
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AuthPassword != nil {
+		in, out := &in.AuthPassword, &out.AuthPassword
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RequireTLS != nil {
+		in, out := &in.RequireTLS, &out.RequireTLS
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertmanagerEmailConfig.
+func (in *AlertmanagerEmailConfig) DeepCopy() *AlertmanagerEmailConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertmanagerEmailConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertmanagerGlobal) DeepCopyInto(out *AlertmanagerGlobal) {
+	*out = *in
+	if in.SMTPAuthPassword != nil {
+		in, out := &in.SMTPAuthPassword, &out.SMTPAuthPassword
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SMTPRequireTLS != nil {
+		in, out := &in.SMTPRequireTLS, &out.SMTPRequireTLS
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SlackAPIURL != nil {
+		in, out := &in.SlackAPIURL, &out.SlackAPIURL
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertmanagerGlobal.
+func (in *AlertmanagerGlobal) DeepCopy() *AlertmanagerGlobal {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertmanagerGlobal)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertmanagerInhibitRule) DeepCopyInto(out *AlertmanagerInhibitRule) {
+	*out = *in
+	if in.SourceMatch != nil {
+		in, out := &in.SourceMatch, &out.SourceMatch
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SourceMatchRE != nil {
+		in, out := &in.SourceMatchRE, &out.SourceMatchRE
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TargetMatch != nil {
+		in, out := &in.TargetMatch, &out.TargetMatch
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TargetMatchRE != nil {
+		in, out := &in.TargetMatchRE, &out.TargetMatchRE
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Equal != nil {
+		in, out := &in.Equal, &out.Equal
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertmanagerInhibitRule.
+func (in *AlertmanagerInhibitRule) DeepCopy() *AlertmanagerInhibitRule {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertmanagerInhibitRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertmanagerOpsgenieConfig) DeepCopyInto(out *AlertmanagerOpsgenieConfig) {
+	*out = *in
+	if in.SendResolved != nil {
+		in, out := &in.SendResolved, &out.SendResolved
+		*out = new(bool)
+		**out = **in
+	}
+	if in.APIKey != nil {
+		in, out := &in.APIKey, &out.APIKey
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertmanagerOpsgenieConfig.
+func (in *AlertmanagerOpsgenieConfig) DeepCopy() *AlertmanagerOpsgenieConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertmanagerOpsgenieConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertmanagerPagerdutyConfig) DeepCopyInto(out *AlertmanagerPagerdutyConfig) {
+	*out = *in
+	if in.SendResolved != nil {
+		in, out := &in.SendResolved, &out.SendResolved
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RoutingKey != nil {
+		in, out := &in.RoutingKey, &out.RoutingKey
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceKey != nil {
+		in, out := &in.ServiceKey, &out.ServiceKey
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertmanagerPagerdutyConfig.
+func (in *AlertmanagerPagerdutyConfig) DeepCopy() *AlertmanagerPagerdutyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertmanagerPagerdutyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertmanagerReceiver) DeepCopyInto(out *AlertmanagerReceiver) {
+	*out = *in
+	if in.WebhookConfigs != nil {
+		in, out := &in.WebhookConfigs, &out.WebhookConfigs
+		*out = make([]AlertmanagerWebhookConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EmailConfigs != nil {
+		in, out := &in.EmailConfigs, &out.EmailConfigs
+		*out = make([]AlertmanagerEmailConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SlackConfigs != nil {
+		in, out := &in.SlackConfigs, &out.SlackConfigs
+		*out = make([]AlertmanagerSlackConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PagerdutyConfigs != nil {
+		in, out := &in.PagerdutyConfigs, &out.PagerdutyConfigs
+		*out = make([]AlertmanagerPagerdutyConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.OpsgenieConfigs != nil {
+		in, out := &in.OpsgenieConfigs, &out.OpsgenieConfigs
+		*out = make([]AlertmanagerOpsgenieConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertmanagerReceiver.
+func (in *AlertmanagerReceiver) DeepCopy() *AlertmanagerReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertmanagerReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertmanagerRoute) DeepCopyInto(out *AlertmanagerRoute) {
+	*out = *in
+	if in.GroupBy != nil {
+		in, out := &in.GroupBy, &out.GroupBy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Match != nil {
+		in, out := &in.Match, &out.Match
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MatchRE != nil {
+		in, out := &in.MatchRE, &out.MatchRE
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Routes != nil {
+		in, out := &in.Routes, &out.Routes
+		*out = make([]AlertmanagerRoute, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertmanagerRoute.
+func (in *AlertmanagerRoute) DeepCopy() *AlertmanagerRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertmanagerRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertmanagerRouting) DeepCopyInto(out *AlertmanagerRouting) {
+	*out = *in
+	if in.Global != nil {
+		in, out := &in.Global, &out.Global
+		*out = new(AlertmanagerGlobal)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Receivers != nil {
+		in, out := &in.Receivers, &out.Receivers
+		*out = make([]AlertmanagerReceiver, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Route.DeepCopyInto(&out.Route)
+	if in.InhibitRules != nil {
+		in, out := &in.InhibitRules, &out.InhibitRules
+		*out = make([]AlertmanagerInhibitRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertmanagerRouting.
+func (in *AlertmanagerRouting) DeepCopy() *AlertmanagerRouting {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertmanagerRouting)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertmanagerSlackConfig) DeepCopyInto(out *AlertmanagerSlackConfig) {
+	*out = *in
+	if in.SendResolved != nil {
+		in, out := &in.SendResolved, &out.SendResolved
+		*out = new(bool)
+		**out = **in
+	}
+	if in.APIURL != nil {
+		in, out := &in.APIURL, &out.APIURL
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertmanagerSlackConfig.
+func (in *AlertmanagerSlackConfig) DeepCopy() *AlertmanagerSlackConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertmanagerSlackConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertmanagerWebhookConfig) DeepCopyInto(out *AlertmanagerWebhookConfig) {
+	*out = *in
+	if in.SendResolved != nil {
+		in, out := &in.SendResolved, &out.SendResolved
+		*out = new(bool)
+		**out = **in
+	}
+	if in.URLSecret != nil {
+		in, out := &in.URLSecret, &out.URLSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertmanagerWebhookConfig.
+func (in *AlertmanagerWebhookConfig) DeepCopy() *AlertmanagerWebhookConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertmanagerWebhookConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateStatus) DeepCopyInto(out *CertificateStatus) {
+	*out = *in
+	in.NotAfter.DeepCopyInto(&out.NotAfter)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateStatus.
+func (in *CertificateStatus) DeepCopy() *CertificateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Certs) DeepCopyInto(out *Certs) {
+	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ExtraDNSNames != nil {
+		in, out := &in.ExtraDNSNames, &out.ExtraDNSNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPAddresses != nil {
+		in, out := &in.IPAddresses, &out.IPAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Certs.
+func (in *Certs) DeepCopy() *Certs {
+	if in == nil {
+		return nil
+	}
+	out := new(Certs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultRule) DeepCopyInto(out *DefaultRule) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Threshold != nil {
+		in, out := &in.Threshold, &out.Threshold
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertManagerConfig.
-func (in *AlertManagerConfig) DeepCopy() *AlertManagerConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultRule.
+func (in *DefaultRule) DeepCopy() *DefaultRule {
 	if in == nil {
 		return nil
 	}
-	out := new(AlertManagerConfig)
+	out := new(DefaultRule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Certs) DeepCopyInto(out *Certs) {
+func (in *DeploymentSchedulingSpec) DeepCopyInto(out *DeploymentSchedulingSpec) {
 	*out = *in
+	in.SchedulingSpec.DeepCopyInto(&out.SchedulingSpec)
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Certs.
-func (in *Certs) DeepCopy() *Certs {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentSchedulingSpec.
+func (in *DeploymentSchedulingSpec) DeepCopy() *DeploymentSchedulingSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Certs)
+	out := new(DeploymentSchedulingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Exposure) DeepCopyInto(out *Exposure) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Exposure.
+func (in *Exposure) DeepCopy() *Exposure {
+	if in == nil {
+		return nil
+	}
+	out := new(Exposure)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -93,6 +728,7 @@ func (in *IAMProvider) DeepCopy() *IAMProvider {
 func (in *MCMMonitor) DeepCopyInto(out *MCMMonitor) {
 	*out = *in
 	in.Resources.DeepCopyInto(&out.Resources)
+	in.Scheduling.DeepCopyInto(&out.Scheduling)
 	return
 }
 
@@ -106,11 +742,65 @@ func (in *MCMMonitor) DeepCopy() *MCMMonitor {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceStatus) DeepCopyInto(out *MaintenanceStatus) {
+	*out = *in
+	in.EndsAt.DeepCopyInto(&out.EndsAt)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceStatus.
+func (in *MaintenanceStatus) DeepCopy() *MaintenanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PrometheusConfig) DeepCopyInto(out *PrometheusConfig) {
 	*out = *in
 	in.Resources.DeepCopyInto(&out.Resources)
 	in.RouterResource.DeepCopyInto(&out.RouterResource)
+	if in.DefaultRules != nil {
+		in, out := &in.DefaultRules, &out.DefaultRules
+		*out = make(map[string]DefaultRule, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Exposure != nil {
+		in, out := &in.Exposure, &out.Exposure
+		*out = new(Exposure)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	in.Scheduling.DeepCopyInto(&out.Scheduling)
+	if in.RemoteWrite != nil {
+		in, out := &in.RemoteWrite, &out.RemoteWrite
+		*out = make([]RemoteWrite, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RemoteRead != nil {
+		in, out := &in.RemoteRead, &out.RemoteRead
+		*out = make([]RemoteRead, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Thanos != nil {
+		in, out := &in.Thanos, &out.Thanos
+		*out = new(ThanosConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -152,6 +842,23 @@ func (in *PrometheusExt) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusExtCondition) DeepCopyInto(out *PrometheusExtCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusExtCondition.
+func (in *PrometheusExtCondition) DeepCopy() *PrometheusExtCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusExtCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PrometheusExtList) DeepCopyInto(out *PrometheusExtList) {
 	*out = *in
@@ -195,11 +902,16 @@ func (in *PrometheusExtSpec) DeepCopyInto(out *PrometheusExtSpec) {
 	}
 	in.AlertManagerConfig.DeepCopyInto(&out.AlertManagerConfig)
 	in.PrometheusConfig.DeepCopyInto(&out.PrometheusConfig)
+	if in.RetainStorage != nil {
+		in, out := &in.RetainStorage, &out.RetainStorage
+		*out = new(bool)
+		**out = **in
+	}
 	in.MCMMonitor.DeepCopyInto(&out.MCMMonitor)
-	out.Certs = in.Certs
+	in.Certs.DeepCopyInto(&out.Certs)
 	out.IAMProvider = in.IAMProvider
 	out.HelmReleasesMonitor = in.HelmReleasesMonitor
-	out.PrometheusOperator = in.PrometheusOperator
+	in.PrometheusOperator.DeepCopyInto(&out.PrometheusOperator)
 	if in.NodeSelector != nil {
 		in, out := &in.NodeSelector, &out.NodeSelector
 		*out = make(map[string]string, len(*in))
@@ -224,6 +936,44 @@ func (in *PrometheusExtSpec) DeepCopy() *PrometheusExtSpec {
 func (in *PrometheusExtStatus) DeepCopyInto(out *PrometheusExtStatus) {
 	*out = *in
 	in.PrometheusOperator.DeepCopyInto(&out.PrometheusOperator)
+	if in.Certificates != nil {
+		in, out := &in.Certificates, &out.Certificates
+		*out = make([]CertificateStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ConfigHashes != nil {
+		in, out := &in.ConfigHashes, &out.ConfigHashes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]PrometheusExtCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Waiting != nil {
+		in, out := &in.Waiting, &out.Waiting
+		*out = new(WaitingStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(MaintenanceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RemoteWrite != nil {
+		in, out := &in.RemoteWrite, &out.RemoteWrite
+		*out = make([]RemoteWriteStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -240,6 +990,7 @@ func (in *PrometheusExtStatus) DeepCopy() *PrometheusExtStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PrometheusOperator) DeepCopyInto(out *PrometheusOperator) {
 	*out = *in
+	in.Scheduling.DeepCopyInto(&out.Scheduling)
 	return
 }
 
@@ -252,3 +1003,273 @@ func (in *PrometheusOperator) DeepCopy() *PrometheusOperator {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RelabelConfig) DeepCopyInto(out *RelabelConfig) {
+	*out = *in
+	if in.SourceLabels != nil {
+		in, out := &in.SourceLabels, &out.SourceLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RelabelConfig.
+func (in *RelabelConfig) DeepCopy() *RelabelConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RelabelConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteBasicAuth) DeepCopyInto(out *RemoteBasicAuth) {
+	*out = *in
+	in.Username.DeepCopyInto(&out.Username)
+	in.Password.DeepCopyInto(&out.Password)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteBasicAuth.
+func (in *RemoteBasicAuth) DeepCopy() *RemoteBasicAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteBasicAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteRead) DeepCopyInto(out *RemoteRead) {
+	*out = *in
+	if in.RequiredMatchers != nil {
+		in, out := &in.RequiredMatchers, &out.RequiredMatchers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.BasicAuth != nil {
+		in, out := &in.BasicAuth, &out.BasicAuth
+		*out = new(RemoteBasicAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BearerToken != nil {
+		in, out := &in.BearerToken, &out.BearerToken
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLSConfig != nil {
+		in, out := &in.TLSConfig, &out.TLSConfig
+		*out = new(RemoteTLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteRead.
+func (in *RemoteRead) DeepCopy() *RemoteRead {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteRead)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteTLSConfig) DeepCopyInto(out *RemoteTLSConfig) {
+	*out = *in
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cert != nil {
+		in, out := &in.Cert, &out.Cert
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Key != nil {
+		in, out := &in.Key, &out.Key
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteTLSConfig.
+func (in *RemoteTLSConfig) DeepCopy() *RemoteTLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteTLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteWrite) DeepCopyInto(out *RemoteWrite) {
+	*out = *in
+	if in.BasicAuth != nil {
+		in, out := &in.BasicAuth, &out.BasicAuth
+		*out = new(RemoteBasicAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BearerToken != nil {
+		in, out := &in.BearerToken, &out.BearerToken
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLSConfig != nil {
+		in, out := &in.TLSConfig, &out.TLSConfig
+		*out = new(RemoteTLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WriteRelabelConfigs != nil {
+		in, out := &in.WriteRelabelConfigs, &out.WriteRelabelConfigs
+		*out = make([]RelabelConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.QueueConfig != nil {
+		in, out := &in.QueueConfig, &out.QueueConfig
+		*out = new(RemoteWriteQueueConfig)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteWrite.
+func (in *RemoteWrite) DeepCopy() *RemoteWrite {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteWrite)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteWriteQueueConfig) DeepCopyInto(out *RemoteWriteQueueConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteWriteQueueConfig.
+func (in *RemoteWriteQueueConfig) DeepCopy() *RemoteWriteQueueConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteWriteQueueConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteWriteStatus) DeepCopyInto(out *RemoteWriteStatus) {
+	*out = *in
+	if in.LagSeconds != nil {
+		in, out := &in.LagSeconds, &out.LagSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	in.LastProbeTime.DeepCopyInto(&out.LastProbeTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteWriteStatus.
+func (in *RemoteWriteStatus) DeepCopy() *RemoteWriteStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteWriteStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchedulingSpec) DeepCopyInto(out *SchedulingSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingSpec.
+func (in *SchedulingSpec) DeepCopy() *SchedulingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SchedulingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThanosConfig) DeepCopyInto(out *ThanosConfig) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.ObjectStorageConfig != nil {
+		in, out := &in.ObjectStorageConfig, &out.ObjectStorageConfig
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExternalLabels != nil {
+		in, out := &in.ExternalLabels, &out.ExternalLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosConfig.
+func (in *ThanosConfig) DeepCopy() *ThanosConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ThanosConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaitingStatus) DeepCopyInto(out *WaitingStatus) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaitingStatus.
+func (in *WaitingStatus) DeepCopy() *WaitingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WaitingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
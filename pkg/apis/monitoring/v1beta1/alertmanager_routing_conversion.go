@@ -0,0 +1,132 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v1beta1
+
+import (
+	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+)
+
+// Types of alertmanager routing have the same fields in both versions. Types without nested types of this package are
+// converted with Go type conversion, so a change of a v1alpha1 type fails to compile instead of changing v1beta1
+
+func routingToHub(in *AlertmanagerRouting) *v1alpha1.AlertmanagerRouting {
+	if in == nil {
+		return nil
+	}
+	out := &v1alpha1.AlertmanagerRouting{
+		Global: (*v1alpha1.AlertmanagerGlobal)(in.Global),
+		Route:  routeToHub(in.Route),
+	}
+	for _, r := range in.Receivers {
+		out.Receivers = append(out.Receivers, receiverToHub(r))
+	}
+	for _, r := range in.InhibitRules {
+		out.InhibitRules = append(out.InhibitRules, v1alpha1.AlertmanagerInhibitRule(r))
+	}
+	return out
+}
+
+func routingFromHub(in *v1alpha1.AlertmanagerRouting) *AlertmanagerRouting {
+	if in == nil {
+		return nil
+	}
+	out := &AlertmanagerRouting{
+		Global: (*AlertmanagerGlobal)(in.Global),
+		Route:  routeFromHub(in.Route),
+	}
+	for _, r := range in.Receivers {
+		out.Receivers = append(out.Receivers, receiverFromHub(r))
+	}
+	for _, r := range in.InhibitRules {
+		out.InhibitRules = append(out.InhibitRules, AlertmanagerInhibitRule(r))
+	}
+	return out
+}
+
+func receiverToHub(in AlertmanagerReceiver) v1alpha1.AlertmanagerReceiver {
+	out := v1alpha1.AlertmanagerReceiver{Name: in.Name}
+	for _, c := range in.WebhookConfigs {
+		out.WebhookConfigs = append(out.WebhookConfigs, v1alpha1.AlertmanagerWebhookConfig(c))
+	}
+	for _, c := range in.EmailConfigs {
+		out.EmailConfigs = append(out.EmailConfigs, v1alpha1.AlertmanagerEmailConfig(c))
+	}
+	for _, c := range in.SlackConfigs {
+		out.SlackConfigs = append(out.SlackConfigs, v1alpha1.AlertmanagerSlackConfig(c))
+	}
+	for _, c := range in.PagerdutyConfigs {
+		out.PagerdutyConfigs = append(out.PagerdutyConfigs, v1alpha1.AlertmanagerPagerdutyConfig(c))
+	}
+	for _, c := range in.OpsgenieConfigs {
+		out.OpsgenieConfigs = append(out.OpsgenieConfigs, v1alpha1.AlertmanagerOpsgenieConfig(c))
+	}
+	return out
+}
+
+func receiverFromHub(in v1alpha1.AlertmanagerReceiver) AlertmanagerReceiver {
+	out := AlertmanagerReceiver{Name: in.Name}
+	for _, c := range in.WebhookConfigs {
+		out.WebhookConfigs = append(out.WebhookConfigs, AlertmanagerWebhookConfig(c))
+	}
+	for _, c := range in.EmailConfigs {
+		out.EmailConfigs = append(out.EmailConfigs, AlertmanagerEmailConfig(c))
+	}
+	for _, c := range in.SlackConfigs {
+		out.SlackConfigs = append(out.SlackConfigs, AlertmanagerSlackConfig(c))
+	}
+	for _, c := range in.PagerdutyConfigs {
+		out.PagerdutyConfigs = append(out.PagerdutyConfigs, AlertmanagerPagerdutyConfig(c))
+	}
+	for _, c := range in.OpsgenieConfigs {
+		out.OpsgenieConfigs = append(out.OpsgenieConfigs, AlertmanagerOpsgenieConfig(c))
+	}
+	return out
+}
+
+func routeToHub(in AlertmanagerRoute) v1alpha1.AlertmanagerRoute {
+	out := v1alpha1.AlertmanagerRoute{
+		Receiver:       in.Receiver,
+		GroupBy:        in.GroupBy,
+		GroupWait:      in.GroupWait,
+		GroupInterval:  in.GroupInterval,
+		RepeatInterval: in.RepeatInterval,
+		Match:          in.Match,
+		MatchRE:        in.MatchRE,
+		Continue:       in.Continue,
+	}
+	for _, r := range in.Routes {
+		out.Routes = append(out.Routes, routeToHub(r))
+	}
+	return out
+}
+
+func routeFromHub(in v1alpha1.AlertmanagerRoute) AlertmanagerRoute {
+	out := AlertmanagerRoute{
+		Receiver:       in.Receiver,
+		GroupBy:        in.GroupBy,
+		GroupWait:      in.GroupWait,
+		GroupInterval:  in.GroupInterval,
+		RepeatInterval: in.RepeatInterval,
+		Match:          in.Match,
+		MatchRE:        in.MatchRE,
+		Continue:       in.Continue,
+	}
+	for _, r := range in.Routes {
+		out.Routes = append(out.Routes, routeFromHub(r))
+	}
+	return out
+}
@@ -0,0 +1,173 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v1beta1
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// AlertmanagerRouting defines receivers, routes and inhibit rules of alertmanager
+// Secrets referred by receivers must be in the namespace of the CR
+type AlertmanagerRouting struct {
+	// Global settings shared by receivers
+	Global *AlertmanagerGlobal `json:"global,omitempty"`
+	// Receivers of notifications
+	Receivers []AlertmanagerReceiver `json:"receivers"`
+	// Root of the routing tree
+	Route AlertmanagerRoute `json:"route"`
+	// Rules to mute alerts when other alerts are firing
+	InhibitRules []AlertmanagerInhibitRule `json:"inhibitRules,omitempty"`
+}
+
+// AlertmanagerGlobal defines global settings of alertmanager
+type AlertmanagerGlobal struct {
+	// Time after which an alert is declared resolved if it has not been updated
+	ResolveTimeout string `json:"resolveTimeout,omitempty"`
+	// Default sender address of email notifications
+	SMTPFrom string `json:"smtpFrom,omitempty"`
+	// Default SMTP host:port of email notifications
+	SMTPSmarthost string `json:"smtpSmarthost,omitempty"`
+	// Default SMTP user name
+	SMTPAuthUsername string `json:"smtpAuthUsername,omitempty"`
+	// Secret key which stores default SMTP password
+	SMTPAuthPassword *v1.SecretKeySelector `json:"smtpAuthPassword,omitempty"`
+	// Require TLS for SMTP, true by default
+	SMTPRequireTLS *bool `json:"smtpRequireTLS,omitempty"`
+	// Secret key which stores default Slack webhook URL
+	SlackAPIURL *v1.SecretKeySelector `json:"slackAPIURL,omitempty"`
+	// Default PagerDuty API URL
+	PagerdutyURL string `json:"pagerdutyURL,omitempty"`
+	// Default OpsGenie API URL
+	OpsgenieAPIURL string `json:"opsgenieAPIURL,omitempty"`
+}
+
+// AlertmanagerReceiver defines a named set of notification integrations
+type AlertmanagerReceiver struct {
+	// Unique name of the receiver
+	Name             string                        `json:"name"`
+	WebhookConfigs   []AlertmanagerWebhookConfig   `json:"webhookConfigs,omitempty"`
+	EmailConfigs     []AlertmanagerEmailConfig     `json:"emailConfigs,omitempty"`
+	SlackConfigs     []AlertmanagerSlackConfig     `json:"slackConfigs,omitempty"`
+	PagerdutyConfigs []AlertmanagerPagerdutyConfig `json:"pagerdutyConfigs,omitempty"`
+	OpsgenieConfigs  []AlertmanagerOpsgenieConfig  `json:"opsgenieConfigs,omitempty"`
+}
+
+// AlertmanagerWebhookConfig defines a generic webhook receiver
+type AlertmanagerWebhookConfig struct {
+	// Whether to notify about resolved alerts
+	SendResolved *bool `json:"sendResolved,omitempty"`
+	// URL to send requests to. Either url or urlSecret is required
+	URL string `json:"url,omitempty"`
+	// Secret key which stores URL to send requests to
+	URLSecret *v1.SecretKeySelector `json:"urlSecret,omitempty"`
+	// Maximum number of alerts in one message, 0 means all
+	MaxAlerts int32 `json:"maxAlerts,omitempty"`
+}
+
+// AlertmanagerEmailConfig defines an email receiver
+type AlertmanagerEmailConfig struct {
+	// Whether to notify about resolved alerts
+	SendResolved *bool `json:"sendResolved,omitempty"`
+	// Email address to send notifications to
+	To string `json:"to"`
+	// Sender address, global smtpFrom by default
+	From string `json:"from,omitempty"`
+	// SMTP host:port, global smtpSmarthost by default
+	Smarthost string `json:"smarthost,omitempty"`
+	// SMTP user name
+	AuthUsername string `json:"authUsername,omitempty"`
+	// Secret key which stores SMTP password
+	AuthPassword *v1.SecretKeySelector `json:"authPassword,omitempty"`
+	// Require TLS for SMTP
+	RequireTLS *bool `json:"requireTLS,omitempty"`
+	// Extra email headers
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// AlertmanagerSlackConfig defines a Slack receiver
+type AlertmanagerSlackConfig struct {
+	// Whether to notify about resolved alerts
+	SendResolved *bool `json:"sendResolved,omitempty"`
+	// Secret key which stores Slack webhook URL, global slackAPIURL by default
+	APIURL *v1.SecretKeySelector `json:"apiURL,omitempty"`
+	// Channel or user to send notifications to
+	Channel  string `json:"channel"`
+	Username string `json:"username,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Text     string `json:"text,omitempty"`
+}
+
+// AlertmanagerPagerdutyConfig defines a PagerDuty receiver
+type AlertmanagerPagerdutyConfig struct {
+	// Whether to notify about resolved alerts
+	SendResolved *bool `json:"sendResolved,omitempty"`
+	// Secret key which stores integration key of events API v2. Either routingKey or serviceKey is required
+	RoutingKey *v1.SecretKeySelector `json:"routingKey,omitempty"`
+	// Secret key which stores integration key of Prometheus integration type
+	ServiceKey *v1.SecretKeySelector `json:"serviceKey,omitempty"`
+	// PagerDuty API URL, global pagerdutyURL by default
+	URL      string `json:"url,omitempty"`
+	Severity string `json:"severity,omitempty"`
+}
+
+// AlertmanagerOpsgenieConfig defines an OpsGenie receiver
+type AlertmanagerOpsgenieConfig struct {
+	// Whether to notify about resolved alerts
+	SendResolved *bool `json:"sendResolved,omitempty"`
+	// Secret key which stores OpsGenie API key
+	APIKey *v1.SecretKeySelector `json:"apiKey"`
+	// OpsGenie API URL, global opsgenieAPIURL by default
+	APIURL   string `json:"apiURL,omitempty"`
+	Message  string `json:"message,omitempty"`
+	Priority string `json:"priority,omitempty"`
+	// Comma separated list of tags
+	Tags string `json:"tags,omitempty"`
+}
+
+// AlertmanagerRoute defines a node of the routing tree
+type AlertmanagerRoute struct {
+	// Name of receiver. It is inherited from parent route if it is not set. Root route must have a receiver
+	Receiver string `json:"receiver,omitempty"`
+	// Labels to group alerts by
+	GroupBy []string `json:"groupBy,omitempty"`
+	// How long to wait before sending first notification of a group
+	GroupWait string `json:"groupWait,omitempty"`
+	// How long to wait before sending notification about new alerts of a group
+	GroupInterval string `json:"groupInterval,omitempty"`
+	// How long to wait before sending a notification again
+	RepeatInterval string `json:"repeatInterval,omitempty"`
+	// Labels which alerts must have to match this route
+	Match map[string]string `json:"match,omitempty"`
+	// Label regular expressions which alerts must match to match this route
+	MatchRE map[string]string `json:"matchRE,omitempty"`
+	// Whether to continue matching sibling routes after this route matches
+	Continue bool `json:"continue,omitempty"`
+	// Child routes, they have same fields as this route
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Routes []AlertmanagerRoute `json:"routes,omitempty"`
+}
+
+// AlertmanagerInhibitRule mutes target alerts when source alerts are firing
+type AlertmanagerInhibitRule struct {
+	SourceMatch   map[string]string `json:"sourceMatch,omitempty"`
+	SourceMatchRE map[string]string `json:"sourceMatchRE,omitempty"`
+	TargetMatch   map[string]string `json:"targetMatch,omitempty"`
+	TargetMatchRE map[string]string `json:"targetMatchRE,omitempty"`
+	// Labels which must have same value in source and target alerts
+	Equal []string `json:"equal,omitempty"`
+}
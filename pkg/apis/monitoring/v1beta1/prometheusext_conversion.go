@@ -0,0 +1,402 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+)
+
+// alphaOnlyFields are fields of v1alpha1 which are removed from v1beta1
+type alphaOnlyFields struct {
+	ClusterAddress string `json:"clusterAddress,omitempty"`
+	ClusterPort    int32  `json:"clusterPort,omitempty"`
+	ExporterStatus string `json:"exporterStatus,omitempty"`
+	// Node selector of components without node selector. v1beta1 only has node selectors of each component
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Components which have no node selector in v1alpha1 and use NodeSelector
+	InheritedNodeSelector []string `json:"inheritedNodeSelector,omitempty"`
+}
+
+// names of components in v1beta1 spec which have scheduling
+const (
+	prometheusComponent         = "prometheus"
+	alertmanagerComponent       = "alertmanager"
+	prometheusOperatorComponent = "prometheusOperator"
+	mcmControllerComponent      = "mcmController"
+)
+
+var _ conversion.Convertible = &PrometheusExt{}
+
+// ConvertTo converts this PrometheusExt to the hub version v1alpha1
+func (src *PrometheusExt) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.PrometheusExt)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	alphaOnly := &alphaOnlyFields{}
+	if err := popConversionData(&dst.ObjectMeta, alphaOnly); err != nil {
+		return err
+	}
+
+	in := src.Spec.DeepCopy()
+	out := &dst.Spec
+	out.ClusterAddress = alphaOnly.ClusterAddress
+	out.ClusterPort = alphaOnly.ClusterPort
+	out.ClusterName = in.Cluster.Name
+	out.ClusterDomain = in.Cluster.Domain
+	out.ImagePolicy = in.ImagePullPolicy
+	out.ImagePullSecrets = in.ImagePullSecrets
+	out.StorageClassName = in.StorageClassName
+	out.RetainStorage = in.RetainStorage
+	out.PrometheusConfig = v1alpha1.PrometheusConfig{
+		ServiceAccountName:  in.Prometheus.ServiceAccountName,
+		ImageRepo:           in.Prometheus.Image,
+		ImageTag:            in.Prometheus.ImageTag,
+		Retention:           in.Prometheus.Retention,
+		ScrapeInterval:      in.Prometheus.ScrapeInterval,
+		EvaluationInterval:  in.Prometheus.EvaluationInterval,
+		Resources:           in.Prometheus.Resources,
+		RouterResource:      in.Router.Resources,
+		PVSize:              in.Prometheus.StorageSize,
+		ServicePort:         in.Prometheus.ServicePort,
+		NodeMemoryThreshold: in.Prometheus.NodeMemoryThreshold,
+		NodeCPUThreshold:    in.Prometheus.NodeCPUThreshold,
+		LogLevel:            in.Prometheus.LogLevel,
+		DefaultRules:        defaultRulesToHub(in.Prometheus.DefaultRules),
+		Exposure:            exposureToHub(in.Prometheus.Exposure),
+		Replicas:            in.Prometheus.Replicas,
+		RemoteWrite:         remoteWriteToHub(in.Prometheus.RemoteWrite),
+		RemoteRead:          remoteReadToHub(in.Prometheus.RemoteRead),
+		Thanos:              (*v1alpha1.ThanosConfig)(in.Prometheus.Thanos),
+	}
+	out.AlertManagerConfig = v1alpha1.AlertManagerConfig{
+		ServiceAccountName: in.Alertmanager.ServiceAccountName,
+		ImageRepo:          in.Alertmanager.Image,
+		ImageTag:           in.Alertmanager.ImageTag,
+		PVSize:             in.Alertmanager.StorageSize,
+		ServicePort:        in.Alertmanager.ServicePort,
+		Resources:          in.Alertmanager.Resources,
+		LogLevel:           in.Alertmanager.LogLevel,
+		Routing:            routingToHub(in.Alertmanager.Routing),
+		Exposure:           exposureToHub(in.Alertmanager.Exposure),
+		Replicas:           in.Alertmanager.Replicas,
+	}
+	out.RouterImage = in.Router.Image
+	out.PrometheusOperator = v1alpha1.PrometheusOperator{
+		Image:                 in.PrometheusOperator.Image,
+		ConfigmapReloadImage:  in.PrometheusOperator.ConfigmapReloadImage,
+		PrometheusConfigImage: in.PrometheusOperator.PrometheusConfigReloaderImage,
+	}
+	out.MCMMonitor = v1alpha1.MCMMonitor{
+		IsHubCluster:       in.MCMController.HubCluster,
+		Image:              in.MCMController.Image,
+		HelperImage:        in.MCMController.HelperImage,
+		ServiceAccountName: in.MCMController.ServiceAccountName,
+		Resources:          in.MCMController.Resources,
+	}
+	out.HelmReleasesMonitor = v1alpha1.HelmReleasesMonitor{
+		Namespace: in.HelmReleasesMonitor.Namespace,
+		Port:      in.HelmReleasesMonitor.Port,
+	}
+	out.Certs = v1alpha1.Certs(in.Certs)
+	out.IAMProvider = v1alpha1.IAMProvider{
+		Namespace:           in.Auth.IAM.Namespace,
+		IDProviderSvc:       in.Auth.IAM.IDProvider.Name,
+		IDProviderSvcPort:   in.Auth.IAM.IDProvider.Port,
+		IDManagementSvc:     in.Auth.IAM.IDManagement.Name,
+		IDManagementSvcPort: in.Auth.IAM.IDManagement.Port,
+	}
+	out.GrafanaSvcName = in.Auth.Grafana.Name
+	out.GrafanaSvcPort = in.Auth.Grafana.Port
+
+	out.NodeSelector = alphaOnly.NodeSelector
+	inherited := alphaOnly.InheritedNodeSelector
+	out.PrometheusConfig.Scheduling = schedulingToHub(prometheusComponent, in.Prometheus.Scheduling, out.NodeSelector, inherited)
+	out.AlertManagerConfig.Scheduling = schedulingToHub(alertmanagerComponent, in.Alertmanager.Scheduling, out.NodeSelector, inherited)
+	out.PrometheusOperator.Scheduling = deploymentSchedulingToHub(prometheusOperatorComponent, in.PrometheusOperator.Scheduling, out.NodeSelector, inherited)
+	out.MCMMonitor.Scheduling = deploymentSchedulingToHub(mcmControllerComponent, in.MCMController.Scheduling, out.NodeSelector, inherited)
+
+	status := src.Status.DeepCopy()
+	dst.Status = v1alpha1.PrometheusExtStatus{
+		PrometheusOperator: status.PrometheusOperator,
+		Prometheus:         status.Prometheus,
+		Alertmanager:       status.Alertmanager,
+		Exporter:           alphaOnly.ExporterStatus,
+		Secrets:            status.Secrets,
+		Configmaps:         status.Configmaps,
+		ObservedGeneration: status.ObservedGeneration,
+		ConfigHashes:       status.ConfigHashes,
+		Waiting:            (*v1alpha1.WaitingStatus)(status.Waiting),
+		Maintenance:        (*v1alpha1.MaintenanceStatus)(status.Maintenance),
+		RemoteWrite:        remoteWriteStatusToHub(status.RemoteWrite),
+	}
+	for _, c := range status.Certificates {
+		dst.Status.Certificates = append(dst.Status.Certificates, v1alpha1.CertificateStatus(c))
+	}
+	for _, c := range status.Conditions {
+		dst.Status.Conditions = append(dst.Status.Conditions, v1alpha1.PrometheusExtCondition{
+			Type:               v1alpha1.ConditionType(c.Type),
+			Status:             c.Status,
+			Reason:             c.Reason,
+			Message:            c.Message,
+			LastTransitionTime: c.LastTransitionTime,
+		})
+	}
+	return nil
+}
+
+// ConvertFrom converts PrometheusExt of the hub version v1alpha1 to this version
+func (dst *PrometheusExt) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.PrometheusExt)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	in := src.Spec.DeepCopy()
+	out := &dst.Spec
+	out.Cluster = ClusterSpec{
+		Name:   in.ClusterName,
+		Domain: in.ClusterDomain,
+	}
+	out.ImagePullPolicy = in.ImagePolicy
+	out.ImagePullSecrets = in.ImagePullSecrets
+	out.StorageClassName = in.StorageClassName
+	out.RetainStorage = in.RetainStorage
+	out.Prometheus = PrometheusSpec{
+		ServiceAccountName:  in.PrometheusConfig.ServiceAccountName,
+		Image:               in.PrometheusConfig.ImageRepo,
+		ImageTag:            in.PrometheusConfig.ImageTag,
+		Retention:           in.PrometheusConfig.Retention,
+		ScrapeInterval:      in.PrometheusConfig.ScrapeInterval,
+		EvaluationInterval:  in.PrometheusConfig.EvaluationInterval,
+		Resources:           in.PrometheusConfig.Resources,
+		StorageSize:         in.PrometheusConfig.PVSize,
+		ServicePort:         in.PrometheusConfig.ServicePort,
+		NodeMemoryThreshold: in.PrometheusConfig.NodeMemoryThreshold,
+		NodeCPUThreshold:    in.PrometheusConfig.NodeCPUThreshold,
+		LogLevel:            in.PrometheusConfig.LogLevel,
+		DefaultRules:        defaultRulesFromHub(in.PrometheusConfig.DefaultRules),
+		Exposure:            exposureFromHub(in.PrometheusConfig.Exposure),
+		Replicas:            in.PrometheusConfig.Replicas,
+		RemoteWrite:         remoteWriteFromHub(in.PrometheusConfig.RemoteWrite),
+		RemoteRead:          remoteReadFromHub(in.PrometheusConfig.RemoteRead),
+		Thanos:              (*ThanosConfig)(in.PrometheusConfig.Thanos),
+	}
+	out.Alertmanager = AlertmanagerSpec{
+		ServiceAccountName: in.AlertManagerConfig.ServiceAccountName,
+		Image:              in.AlertManagerConfig.ImageRepo,
+		ImageTag:           in.AlertManagerConfig.ImageTag,
+		StorageSize:        in.AlertManagerConfig.PVSize,
+		ServicePort:        in.AlertManagerConfig.ServicePort,
+		Resources:          in.AlertManagerConfig.Resources,
+		LogLevel:           in.AlertManagerConfig.LogLevel,
+		Routing:            routingFromHub(in.AlertManagerConfig.Routing),
+		Exposure:           exposureFromHub(in.AlertManagerConfig.Exposure),
+		Replicas:           in.AlertManagerConfig.Replicas,
+	}
+	out.Router = RouterSpec{
+		Image:     in.RouterImage,
+		Resources: in.PrometheusConfig.RouterResource,
+	}
+	out.PrometheusOperator = PrometheusOperatorSpec{
+		Image:                         in.PrometheusOperator.Image,
+		ConfigmapReloadImage:          in.PrometheusOperator.ConfigmapReloadImage,
+		PrometheusConfigReloaderImage: in.PrometheusOperator.PrometheusConfigImage,
+	}
+	out.MCMController = MCMControllerSpec{
+		HubCluster:         in.MCMMonitor.IsHubCluster,
+		Image:              in.MCMMonitor.Image,
+		HelperImage:        in.MCMMonitor.HelperImage,
+		ServiceAccountName: in.MCMMonitor.ServiceAccountName,
+		Resources:          in.MCMMonitor.Resources,
+	}
+	out.HelmReleasesMonitor = HelmReleasesMonitorSpec{
+		Namespace: in.HelmReleasesMonitor.Namespace,
+		Port:      in.HelmReleasesMonitor.Port,
+	}
+	out.Certs = Certs(in.Certs)
+	out.Auth = AuthSpec{
+		IAM: IAMSpec{
+			Namespace:    in.IAMProvider.Namespace,
+			IDProvider:   ServiceReference{Name: in.IAMProvider.IDProviderSvc, Port: in.IAMProvider.IDProviderSvcPort},
+			IDManagement: ServiceReference{Name: in.IAMProvider.IDManagementSvc, Port: in.IAMProvider.IDManagementSvcPort},
+		},
+		Grafana: ServiceReference{Name: in.GrafanaSvcName, Port: in.GrafanaSvcPort},
+	}
+
+	alphaOnly := &alphaOnlyFields{
+		ClusterAddress: in.ClusterAddress,
+		ClusterPort:    in.ClusterPort,
+		ExporterStatus: src.Status.Exporter,
+		NodeSelector:   in.NodeSelector,
+	}
+	inherited := &alphaOnly.InheritedNodeSelector
+	out.Prometheus.Scheduling = schedulingFromHub(prometheusComponent, in.PrometheusConfig.Scheduling, in.NodeSelector, inherited)
+	out.Alertmanager.Scheduling = schedulingFromHub(alertmanagerComponent, in.AlertManagerConfig.Scheduling, in.NodeSelector, inherited)
+	out.PrometheusOperator.Scheduling = deploymentSchedulingFromHub(prometheusOperatorComponent, in.PrometheusOperator.Scheduling, in.NodeSelector, inherited)
+	out.MCMController.Scheduling = deploymentSchedulingFromHub(mcmControllerComponent, in.MCMMonitor.Scheduling, in.NodeSelector, inherited)
+
+	if !reflect.DeepEqual(alphaOnly, &alphaOnlyFields{}) {
+		if err := pushConversionData(&dst.ObjectMeta, alphaOnly); err != nil {
+			return err
+		}
+	}
+
+	status := src.Status.DeepCopy()
+	dst.Status = PrometheusExtStatus{
+		PrometheusOperator: status.PrometheusOperator,
+		Prometheus:         status.Prometheus,
+		Alertmanager:       status.Alertmanager,
+		Secrets:            status.Secrets,
+		Configmaps:         status.Configmaps,
+		ObservedGeneration: status.ObservedGeneration,
+		ConfigHashes:       status.ConfigHashes,
+		Waiting:            (*WaitingStatus)(status.Waiting),
+		Maintenance:        (*MaintenanceStatus)(status.Maintenance),
+		RemoteWrite:        remoteWriteStatusFromHub(status.RemoteWrite),
+	}
+	for _, c := range status.Certificates {
+		dst.Status.Certificates = append(dst.Status.Certificates, CertificateStatus(c))
+	}
+	for _, c := range status.Conditions {
+		dst.Status.Conditions = append(dst.Status.Conditions, PrometheusExtCondition{
+			Type:               ConditionType(c.Type),
+			Status:             c.Status,
+			Reason:             c.Reason,
+			Message:            c.Message,
+			LastTransitionTime: c.LastTransitionTime,
+		})
+	}
+	return nil
+}
+
+// schedulingFromHub returns scheduling of a v1beta1 component. A v1alpha1 component without node selector
+// uses the node selector of all components, and its name is added to inherited
+func schedulingFromHub(component string, in v1alpha1.SchedulingSpec, nodeSelector map[string]string, inherited *[]string) SchedulingSpec {
+	out := SchedulingSpec(in)
+	if len(out.NodeSelector) == 0 && len(nodeSelector) != 0 {
+		out.NodeSelector = nodeSelector
+		*inherited = append(*inherited, component)
+	}
+	return out
+}
+
+// schedulingToHub returns scheduling of a v1alpha1 component. Node selector is removed again from a component which
+// inherited node selector of all components if it is not changed, so that a round trip does not change v1alpha1 object
+func schedulingToHub(component string, in SchedulingSpec, nodeSelector map[string]string, inherited []string) v1alpha1.SchedulingSpec {
+	out := v1alpha1.SchedulingSpec(in)
+	for _, c := range inherited {
+		if c == component && reflect.DeepEqual(out.NodeSelector, nodeSelector) {
+			out.NodeSelector = nil
+		}
+	}
+	return out
+}
+
+func deploymentSchedulingFromHub(component string, in v1alpha1.DeploymentSchedulingSpec, nodeSelector map[string]string, inherited *[]string) DeploymentSchedulingSpec {
+	return DeploymentSchedulingSpec{
+		SchedulingSpec:            schedulingFromHub(component, in.SchedulingSpec, nodeSelector, inherited),
+		TopologySpreadConstraints: in.TopologySpreadConstraints,
+	}
+}
+
+func deploymentSchedulingToHub(component string, in DeploymentSchedulingSpec, nodeSelector map[string]string, inherited []string) v1alpha1.DeploymentSchedulingSpec {
+	return v1alpha1.DeploymentSchedulingSpec{
+		SchedulingSpec:            schedulingToHub(component, in.SchedulingSpec, nodeSelector, inherited),
+		TopologySpreadConstraints: in.TopologySpreadConstraints,
+	}
+}
+
+func exposureFromHub(in *v1alpha1.Exposure) *Exposure {
+	if in == nil {
+		return nil
+	}
+	return &Exposure{
+		Type:             ExposureType(in.Type),
+		IngressClassName: in.IngressClassName,
+		Host:             in.Host,
+		TLSSecretName:    in.TLSSecretName,
+		Annotations:      in.Annotations,
+	}
+}
+
+func exposureToHub(in *Exposure) *v1alpha1.Exposure {
+	if in == nil {
+		return nil
+	}
+	return &v1alpha1.Exposure{
+		Type:             v1alpha1.ExposureType(in.Type),
+		IngressClassName: in.IngressClassName,
+		Host:             in.Host,
+		TLSSecretName:    in.TLSSecretName,
+		Annotations:      in.Annotations,
+	}
+}
+
+func defaultRulesFromHub(in map[string]v1alpha1.DefaultRule) map[string]DefaultRule {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]DefaultRule, len(in))
+	for name, rule := range in {
+		out[name] = DefaultRule(rule)
+	}
+	return out
+}
+
+func defaultRulesToHub(in map[string]DefaultRule) map[string]v1alpha1.DefaultRule {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]v1alpha1.DefaultRule, len(in))
+	for name, rule := range in {
+		out[name] = v1alpha1.DefaultRule(rule)
+	}
+	return out
+}
+
+// pushConversionData stores fields which target version can not keep in annotation
+func pushConversionData(meta *metav1.ObjectMeta, data interface{}) error {
+	value, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	annotations := meta.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[v1alpha1.ConversionDataAnn] = string(value)
+	meta.SetAnnotations(annotations)
+	return nil
+}
+
+// popConversionData restores fields stored by pushConversionData and removes annotation
+func popConversionData(meta *metav1.ObjectMeta, data interface{}) error {
+	annotations := meta.GetAnnotations()
+	value, ok := annotations[v1alpha1.ConversionDataAnn]
+	if !ok {
+		return nil
+	}
+	delete(annotations, v1alpha1.ConversionDataAnn)
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	meta.SetAnnotations(annotations)
+	return json.Unmarshal([]byte(value), data)
+}
@@ -0,0 +1,161 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v1beta1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+)
+
+func TestConversionRoundTrip(t *testing.T) {
+	enabled := true
+	threshold := int32(90)
+	replicas := int32(2)
+	selector := map[string]string{"node-role": "monitoring"}
+	newCR := func(change func(*v1alpha1.PrometheusExt)) *v1alpha1.PrometheusExt {
+		cr := &v1alpha1.PrometheusExt{
+			ObjectMeta: metav1.ObjectMeta{Name: "monitoring", Namespace: "ns"},
+			Spec: v1alpha1.PrometheusExtSpec{
+				Certs: v1alpha1.Certs{MonitoringSecret: "monitoring-certs", MonitoringClientSecret: "monitoring-client-certs"},
+			},
+		}
+		if change != nil {
+			change(cr)
+		}
+		return cr
+	}
+	tests := []struct {
+		name string
+		cr   *v1alpha1.PrometheusExt
+	}{
+		{name: "minimal", cr: newCR(nil)},
+		{name: "fields removed in v1beta1", cr: newCR(func(cr *v1alpha1.PrometheusExt) {
+			cr.Spec.ClusterAddress = "monitoring.example.com"
+			cr.Spec.ClusterPort = 8443
+			cr.Status.Exporter = "ready"
+		})},
+		{name: "top-level node selector", cr: newCR(func(cr *v1alpha1.PrometheusExt) {
+			cr.Spec.NodeSelector = selector
+		})},
+		{name: "component node selector equal to top-level", cr: newCR(func(cr *v1alpha1.PrometheusExt) {
+			cr.Spec.NodeSelector = selector
+			cr.Spec.PrometheusConfig.Scheduling.NodeSelector = map[string]string{"node-role": "monitoring"}
+			cr.Spec.PrometheusOperator.Scheduling.NodeSelector = map[string]string{"node-role": "monitoring"}
+		})},
+		{name: "component node selector different from top-level", cr: newCR(func(cr *v1alpha1.PrometheusExt) {
+			cr.Spec.NodeSelector = selector
+			cr.Spec.AlertManagerConfig.Scheduling.NodeSelector = map[string]string{"node-role": "alerting"}
+			cr.Spec.MCMMonitor.Scheduling.TopologySpreadConstraints = []v1.TopologySpreadConstraint{
+				{MaxSkew: 1, TopologyKey: "zone", WhenUnsatisfiable: v1.DoNotSchedule},
+			}
+		})},
+		{name: "component fields", cr: newCR(func(cr *v1alpha1.PrometheusExt) {
+			cr.Spec.PrometheusConfig.PVSize = "20Gi"
+			cr.Spec.PrometheusConfig.Replicas = &replicas
+			cr.Spec.PrometheusConfig.DefaultRules = map[string]v1alpha1.DefaultRule{
+				"high-cpu-usage": {Enabled: &enabled, Threshold: &threshold, Severity: "critical"},
+			}
+			cr.Spec.PrometheusConfig.Exposure = &v1alpha1.Exposure{Type: v1alpha1.ExposureRoute, Host: "prometheus.example.com"}
+			cr.Spec.PrometheusConfig.RemoteWrite = []v1alpha1.RemoteWrite{{
+				URL:                 "https://remote.example.com/write",
+				BasicAuth:           &v1alpha1.RemoteBasicAuth{Username: v1.SecretKeySelector{Key: "user"}, Password: v1.SecretKeySelector{Key: "password"}},
+				WriteRelabelConfigs: []v1alpha1.RelabelConfig{{SourceLabels: []string{"__name__"}, Regex: "up", Action: "keep"}},
+				QueueConfig:         &v1alpha1.RemoteWriteQueueConfig{MaxShards: 10},
+			}}
+			cr.Spec.PrometheusConfig.RemoteRead = []v1alpha1.RemoteRead{{URL: "https://remote.example.com/read", ReadRecent: true}}
+			cr.Spec.PrometheusConfig.Thanos = &v1alpha1.ThanosConfig{ExternalLabels: map[string]string{"region": "eu"}}
+			cr.Spec.AlertManagerConfig.Routing = &v1alpha1.AlertmanagerRouting{
+				Global:    &v1alpha1.AlertmanagerGlobal{SMTPFrom: "alerts@example.com"},
+				Receivers: []v1alpha1.AlertmanagerReceiver{{Name: "ops", EmailConfigs: []v1alpha1.AlertmanagerEmailConfig{{To: "ops@example.com"}}}},
+				Route: v1alpha1.AlertmanagerRoute{
+					Receiver: "ops",
+					Routes:   []v1alpha1.AlertmanagerRoute{{Match: map[string]string{"severity": "critical"}, Routes: []v1alpha1.AlertmanagerRoute{{Continue: true}}}},
+				},
+				InhibitRules: []v1alpha1.AlertmanagerInhibitRule{{Equal: []string{"alertname"}}},
+			}
+			cr.Spec.Certs.Duration = &metav1.Duration{Duration: 24 * time.Hour}
+		})},
+		{name: "status", cr: newCR(func(cr *v1alpha1.PrometheusExt) {
+			now := metav1.NewTime(time.Unix(1600000000, 0))
+			cr.Status.Certificates = []v1alpha1.CertificateStatus{{SecretName: "monitoring-certs", NotAfter: now}}
+			cr.Status.Conditions = []v1alpha1.PrometheusExtCondition{{Type: v1alpha1.ConditionAvailable, Status: v1.ConditionTrue, LastTransitionTime: now}}
+			cr.Status.Waiting = &v1alpha1.WaitingStatus{Category: "Conflict", Reason: "conflict", Since: now}
+			cr.Status.Maintenance = &v1alpha1.MaintenanceStatus{SilenceID: "1", EndsAt: now}
+			cr.Status.RemoteWrite = []v1alpha1.RemoteWriteStatus{{URL: "https://remote.example.com/write", Healthy: v1.ConditionTrue}}
+		})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			beta := &PrometheusExt{}
+			if err := beta.ConvertFrom(tt.cr.DeepCopy()); err != nil {
+				t.Fatalf("ConvertFrom() error = %v", err)
+			}
+			alpha := &v1alpha1.PrometheusExt{}
+			if err := beta.DeepCopy().ConvertTo(alpha); err != nil {
+				t.Fatalf("ConvertTo() error = %v", err)
+			}
+			if !reflect.DeepEqual(alpha, tt.cr) {
+				t.Errorf("round trip changed object\ngot:  %+v\nwant: %+v", alpha, tt.cr)
+			}
+			again := &PrometheusExt{}
+			if err := again.ConvertFrom(alpha); err != nil {
+				t.Fatalf("ConvertFrom() error = %v", err)
+			}
+			if !reflect.DeepEqual(again, beta) {
+				t.Errorf("round trip changed v1beta1 object\ngot:  %+v\nwant: %+v", again, beta)
+			}
+		})
+	}
+}
+
+func TestConvertFromNodeSelector(t *testing.T) {
+	cr := &v1alpha1.PrometheusExt{
+		Spec: v1alpha1.PrometheusExtSpec{
+			NodeSelector:       map[string]string{"node-role": "monitoring"},
+			AlertManagerConfig: v1alpha1.AlertManagerConfig{Scheduling: v1alpha1.SchedulingSpec{NodeSelector: map[string]string{"node-role": "alerting"}}},
+		},
+	}
+	beta := &PrometheusExt{}
+	if err := beta.ConvertFrom(cr); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	if got := beta.Spec.Prometheus.Scheduling.NodeSelector["node-role"]; got != "monitoring" {
+		t.Errorf("prometheus node selector = %q, want top-level node selector", got)
+	}
+	if got := beta.Spec.Alertmanager.Scheduling.NodeSelector["node-role"]; got != "alerting" {
+		t.Errorf("alertmanager node selector = %q, want its own node selector", got)
+	}
+
+	// a node selector changed in v1beta1 is kept in v1alpha1 even for a component which inherited the top-level one
+	beta.Spec.Prometheus.Scheduling.NodeSelector = map[string]string{"node-role": "prometheus"}
+	alpha := &v1alpha1.PrometheusExt{}
+	if err := beta.ConvertTo(alpha); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if got := alpha.Spec.PrometheusConfig.Scheduling.NodeSelector["node-role"]; got != "prometheus" {
+		t.Errorf("prometheus node selector = %q, want node selector changed in v1beta1", got)
+	}
+	if alpha.Spec.MCMMonitor.Scheduling.NodeSelector != nil {
+		t.Errorf("mcm controller node selector = %v, want inherited from top-level", alpha.Spec.MCMMonitor.Scheduling.NodeSelector)
+	}
+}
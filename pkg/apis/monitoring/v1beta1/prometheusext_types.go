@@ -0,0 +1,454 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v1beta1
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PrometheusExtSpec defines the desired state of PrometheusExt
+type PrometheusExtSpec struct {
+	// Name and domain of the cluster
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	Cluster ClusterSpec `json:"cluster,omitempty"`
+	// Image pull policy of all components
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	ImagePullPolicy v1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// Extra image pull secrets
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	ImagePullSecrets []string `json:"imagePullSecrets,omitempty"`
+	// Storage class name used by Prometheus and Alertmanager. Default storage class of cluster is used if it is empty
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	StorageClassName string `json:"storageClassName,omitempty"`
+	// Keep persistent volume claims of Prometheus and Alertmanager when CR is deleted, true by default
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	RetainStorage *bool `json:"retainStorage,omitempty"`
+	// Configurations for prometheus
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	Prometheus PrometheusSpec `json:"prometheus"`
+	// Configurations for alertmanager
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	Alertmanager AlertmanagerSpec `json:"alertmanager"`
+	// Configurations for router containers in front of prometheus and alertmanager
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	Router RouterSpec `json:"router,omitempty"`
+	// Configurations for prometheus operator deployment
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	PrometheusOperator PrometheusOperatorSpec `json:"prometheusOperator,omitempty"`
+	// Configurations for mcm monitoring controller
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	MCMController MCMControllerSpec `json:"mcmController,omitempty"`
+	// Helm API service information
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	HelmReleasesMonitor HelmReleasesMonitorSpec `json:"helmReleasesMonitor,omitempty"`
+	// Configurations for tls certificates
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	Certs Certs `json:"certs"`
+	// Services which authenticate users and are trusted by prometheus
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	Auth AuthSpec `json:"auth"`
+}
+
+// ClusterSpec defines name and domain of the cluster
+type ClusterSpec struct {
+	// Cluster name, mycluster by default
+	Name string `json:"name,omitempty"`
+	// Cluster domain name, cluster.local by default
+	Domain string `json:"domain,omitempty"`
+}
+
+// PrometheusSpec defines configuration of Prometheus object
+type PrometheusSpec struct {
+	// Service account of prometheus pods
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// Image of prometheus. It is used only if it has a digest
+	Image string `json:"image,omitempty"`
+	// Image tag of prometheus
+	ImageTag string `json:"imageTag,omitempty"`
+	// How long data is kept, 24h by default
+	Retention string `json:"retention,omitempty"`
+	// Interval between scrapes, 1m by default
+	ScrapeInterval string `json:"scrapeInterval,omitempty"`
+	// Interval between rule evaluations, 1m by default
+	EvaluationInterval string `json:"evaluationInterval,omitempty"`
+	// Resources of prometheus container
+	Resources v1.ResourceRequirements `json:"resources,omitempty"`
+	// Size of persistent volume, 10Gi by default
+	StorageSize string `json:"storageSize,omitempty"`
+	// Port of prometheus service
+	ServicePort int32 `json:"servicePort"`
+	// Threshold of node-memory-usage default rule
+	NodeMemoryThreshold int `json:"nodeMemoryThreshold,omitempty"`
+	// Threshold of high-cpu-usage default rule
+	NodeCPUThreshold int `json:"nodeCPUThreshold,omitempty"`
+	// Log level of prometheus
+	LogLevel string `json:"logLevel,omitempty"`
+	// Customization of default prometheus rules by rule name, for example node-memory-usage
+	DefaultRules map[string]DefaultRule `json:"defaultRules,omitempty"`
+	// Scheduling of prometheus pods
+	Scheduling SchedulingSpec `json:"scheduling,omitempty"`
+	// How the component is exposed out of cluster. It is an Ingress of IBM Cloud Pak management ingress by default
+	Exposure *Exposure `json:"exposure,omitempty"`
+	// Number of prometheus pods, 1 by default. Every replica scrapes all targets and has its own persistent volume
+	// +kubebuilder:validation:Minimum=1
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Endpoints which samples are sent to. Their health is reported in status
+	RemoteWrite []RemoteWrite `json:"remoteWrite,omitempty"`
+	// Endpoints which samples are read from by queries
+	RemoteRead []RemoteRead `json:"remoteRead,omitempty"`
+	// Thanos sidecar of prometheus pods. It is not added if it is not set
+	Thanos *ThanosConfig `json:"thanos,omitempty"`
+}
+
+// AlertmanagerSpec defines configuration of Alertmanager object
+type AlertmanagerSpec struct {
+	// Service account of alertmanager pods
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// Image of alertmanager. It is used only if it has a digest
+	Image string `json:"image,omitempty"`
+	// Image tag of alertmanager
+	ImageTag string `json:"imageTag,omitempty"`
+	// Size of persistent volume, 10Gi by default
+	StorageSize string `json:"storageSize,omitempty"`
+	// Port of alertmanager service
+	ServicePort int32 `json:"servicePort"`
+	// Resources of alertmanager container
+	Resources v1.ResourceRequirements `json:"resources,omitempty"`
+	// Log level of alertmanager
+	LogLevel string `json:"logLevel,omitempty"`
+	// Receivers, routes and inhibit rules of alertmanager. If it is not set the operator creates a default configuration
+	// once and never changes it
+	Routing *AlertmanagerRouting `json:"routing,omitempty"`
+	// Scheduling of alertmanager pods
+	Scheduling SchedulingSpec `json:"scheduling,omitempty"`
+	// How the component is exposed out of cluster. It is an Ingress of IBM Cloud Pak management ingress by default
+	Exposure *Exposure `json:"exposure,omitempty"`
+	// Number of alertmanager pods, 1 by default. Replicas form a cluster which shares silences and notifications
+	// +kubebuilder:validation:Minimum=1
+	Replicas *int32 `json:"replicas,omitempty"`
+}
+
+// RouterSpec defines router containers in front of prometheus and alertmanager
+type RouterSpec struct {
+	// Image of router
+	Image string `json:"image,omitempty"`
+	// Resources of router container in prometheus pods
+	Resources v1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// PrometheusOperatorSpec defines prometheus operator deployment
+type PrometheusOperatorSpec struct {
+	// Image of prometheus operator
+	Image string `json:"image,omitempty"`
+	// Image of configmap reloader
+	ConfigmapReloadImage string `json:"configmapReloadImage,omitempty"`
+	// Image of prometheus config reloader
+	PrometheusConfigReloaderImage string `json:"prometheusConfigReloaderImage,omitempty"`
+	// Scheduling of prometheus operator pods
+	Scheduling DeploymentSchedulingSpec `json:"scheduling,omitempty"`
+}
+
+// MCMControllerSpec defines mcm monitoring controller deployment
+type MCMControllerSpec struct {
+	// If it is running on MCM Hub cluster or not
+	HubCluster bool `json:"hubCluster,omitempty"`
+	// Image of mcm monitoring controller
+	Image string `json:"image,omitempty"`
+	// Image of init container which does initializing work
+	HelperImage string `json:"helperImage,omitempty"`
+	// Service account of mcm monitoring controller pods
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// Resources of mcm monitoring controller container
+	Resources v1.ResourceRequirements `json:"resources,omitempty"`
+	// Scheduling of mcm monitoring controller pods
+	Scheduling DeploymentSchedulingSpec `json:"scheduling,omitempty"`
+}
+
+// HelmReleasesMonitorSpec defines helm API service
+type HelmReleasesMonitorSpec struct {
+	// Namespace of helm API service
+	Namespace string `json:"namespace,omitempty"`
+	// Port of helm API service
+	Port int32 `json:"port,omitempty"`
+}
+
+// AuthSpec defines services which authenticate users and are trusted by prometheus
+type AuthSpec struct {
+	// IAM services which authenticate requests to prometheus and alertmanager
+	IAM IAMSpec `json:"iam"`
+	// Grafana service trusted by prometheus
+	Grafana ServiceReference `json:"grafana"`
+}
+
+// IAMSpec defines IAM services
+type IAMSpec struct {
+	// Namespace of IAM services
+	Namespace string `json:"namespace,omitempty"`
+	// Identity provider service
+	IDProvider ServiceReference `json:"idProvider"`
+	// Identity management service
+	IDManagement ServiceReference `json:"idManagement"`
+}
+
+// ServiceReference refers to a service in cluster
+type ServiceReference struct {
+	// Name of service
+	Name string `json:"name"`
+	// Port of service
+	Port int32 `json:"port"`
+}
+
+// SchedulingSpec defines where pods of a component are scheduled
+type SchedulingSpec struct {
+	// Node selector of pods
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations of pods
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+	// Affinity of pods
+	Affinity *v1.Affinity `json:"affinity,omitempty"`
+	// Priority class name of pods
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+}
+
+// DeploymentSchedulingSpec defines where pods of a component deployed by a Deployment are scheduled.
+// Pods of prometheus and alertmanager are created by prometheus operator, which does not support topology spread constraints
+type DeploymentSchedulingSpec struct {
+	SchedulingSpec `json:",inline"`
+	// Topology spread constraints of pods
+	TopologySpreadConstraints []v1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+}
+
+// ThanosConfig defines Thanos sidecar which serves Store API of prometheus data and uploads it to object storage
+type ThanosConfig struct {
+	// Image of Thanos sidecar. THANOS_IMAGE environment variable of the operator or quay.io/thanos/thanos:v0.10.1 by default
+	Image string `json:"image,omitempty"`
+	// Resources of Thanos sidecar container
+	Resources v1.ResourceRequirements `json:"resources,omitempty"`
+	// Secret key which stores Thanos object storage configuration, for example of a S3 bucket.
+	// If it is not set, blocks are not uploaded and sidecar only serves data of local storage
+	ObjectStorageConfig *v1.SecretKeySelector `json:"objectStorageConfig,omitempty"`
+	// External labels added to all series besides cluster label with cluster name and replica label with pod name
+	ExternalLabels map[string]string `json:"externalLabels,omitempty"`
+}
+
+// ExposureType is kind of object which exposes a component out of cluster
+type ExposureType string
+
+const (
+	// ExposureIngress exposes component with an Ingress
+	ExposureIngress ExposureType = "Ingress"
+	// ExposureRoute exposes component with an OpenShift Route with reencrypt tls termination
+	ExposureRoute ExposureType = "Route"
+	// ExposureNone does not expose component
+	ExposureNone ExposureType = "None"
+)
+
+// Exposure defines how a component is exposed out of cluster
+type Exposure struct {
+	// Kind of object which exposes the component, one of Ingress, Route and None. Default is Ingress
+	// +kubebuilder:validation:Enum=Ingress;Route;None
+	Type ExposureType `json:"type,omitempty"`
+	// Class of Ingress. If it is empty, ingress class annotation of IBM Cloud Pak management ingress is used
+	IngressClassName string `json:"ingressClassName,omitempty"`
+	// Host of Ingress or Route. Ingress accepts all hosts and OpenShift generates host of Route if it is empty
+	Host string `json:"host,omitempty"`
+	// Name of tls secret of Ingress
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+	// Annotations of Ingress or Route. If they are not set, Ingress has annotations of IBM Cloud Pak management ingress
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// DefaultRule customizes a default prometheus rule
+type DefaultRule struct {
+	// Whether the rule is created, true by default. Disabled rules are deleted
+	Enabled *bool `json:"enabled,omitempty"`
+	// Threshold of the rule. It is ignored by rules without threshold
+	Threshold *int32 `json:"threshold,omitempty"`
+	// How long the condition must be true before alert fires, for example 10m
+	For string `json:"for,omitempty"`
+	// Value of severity label of alerts
+	Severity string `json:"severity,omitempty"`
+	// URL of runbook which is added as runbook_url annotation of alerts
+	RunbookURL string `json:"runbookURL,omitempty"`
+}
+
+// Certs defines certification used by monitoring stack
+type Certs struct {
+	// Who creates tls certificates, certManager or selfManaged. certManager by default
+	// If it is selfManaged, operator creates its own CA and key pairs and rotates them before expiry
+	// +kubebuilder:validation:Enum=certManager;selfManaged
+	Mode string `json:"mode,omitempty"`
+	// Prometheus and AlertManager' tls cert. Define the secret name. It is created by cert manager
+	MonitoringSecret string `json:"monitoringSecret"`
+	//Define monitoring stack client(prometheus, exporters)'s tls cert secret. It is created by cert manager
+	MonitoringClientSecret string `json:"monitoringClientSecret"`
+	// The issure name. It is used to generated tls certificates. All tls certificates of monitoring operators need to use same Issuer
+	// cs-ca-issuer by default
+	Issuer string `json:"issuer"`
+	// Kind of the issuer, Issuer or ClusterIssuer. Issuer by default
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	IssuerKind string `json:"issuerKind,omitempty"`
+	// Duration of certificates, cert-manager default value is used if it is not set
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// How long before expiry certificates are renewed, cert-manager default value is used if it is not set
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+	// Private key algorithm of certificates, rsa or ecdsa
+	// +kubebuilder:validation:Enum=rsa;ecdsa
+	KeyAlgorithm string `json:"keyAlgorithm,omitempty"`
+	// Private key size of certificates
+	KeySize int `json:"keySize,omitempty"`
+	// Extra dns names added to monitoring certificate
+	ExtraDNSNames []string `json:"extraDNSNames,omitempty"`
+	// IP addresses added to monitoring certificate
+	IPAddresses []string `json:"ipAddresses,omitempty"`
+	// If it is false, user can create secret manually before creating CR and operator will not recreate it if secret exists already
+	// If it is true, operator will recreate secret if it is not created by certificate (cert-manager)
+	AutoClean bool `json:"autoClean,omitempty"`
+}
+
+// PrometheusExtStatus defines the observed state of PrometheusExt
+type PrometheusExtStatus struct {
+	// Status of prometheus operator deployment
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	PrometheusOperator appsv1.DeploymentStatus `json:"prometheusOperator,omitempty"`
+	// Status of the prometheus CR, created or not
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	Prometheus string `json:"prometheus,omitempty"`
+	// Status of the alert manager CR, created or not
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	Alertmanager string `json:"alertmanager,omitempty"`
+	// Status of required secrets, created or not
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	Secrets string `json:"secrets,omitempty"`
+	// Status of required configmaps, created or not
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	Configmaps string `json:"configmaps,omitempty"`
+	// The generation of the CR which was fully reconciled last time
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Expiry dates of tls certificates
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	Certificates []CertificateStatus `json:"certificates,omitempty"`
+	// Hash of secrets and configmaps currently applied to pods of each component
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	ConfigHashes map[string]string `json:"configHashes,omitempty"`
+	// Conditions of the monitoring stack and of each of its components
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	Conditions []PrometheusExtCondition `json:"conditions,omitempty"`
+	// What reconciliation is waiting for when it is requeued
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	Waiting *WaitingStatus `json:"waiting,omitempty"`
+	// Alertmanager silence created for maintenance window
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	Maintenance *MaintenanceStatus `json:"maintenance,omitempty"`
+	// Health of remote write endpoints of prometheus
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	RemoteWrite []RemoteWriteStatus `json:"remoteWrite,omitempty"`
+}
+
+// CertificateStatus shows expiry date of tls certificate stored in a secret
+type CertificateStatus struct {
+	// Name of the secret
+	SecretName string `json:"secretName"`
+	// Expiry date of the certificate
+	NotAfter metav1.Time `json:"notAfter,omitempty"`
+}
+
+// WaitingStatus shows what reconciliation is waiting for and since when
+type WaitingStatus struct {
+	// Category of the requeue, one of WaitingForDependency, Conflict and Transient
+	Category string `json:"category"`
+	// What reconciliation is waiting for
+	Reason string `json:"reason"`
+	// Time when reconciliation started to wait for it
+	Since metav1.Time `json:"since"`
+}
+
+// MaintenanceStatus shows Alertmanager silence created for maintenance window
+type MaintenanceStatus struct {
+	// ID of the silence
+	SilenceID string `json:"silenceID"`
+	// End time of the maintenance window and the silence
+	EndsAt metav1.Time `json:"endsAt"`
+}
+
+// ConditionType is type of PrometheusExt condition
+type ConditionType string
+
+const (
+	// ConditionAvailable is true when all components are created and ready
+	ConditionAvailable ConditionType = "Available"
+	// ConditionProgressing is true when operator is still rolling out the spec
+	ConditionProgressing ConditionType = "Progressing"
+	// ConditionDegraded is true when reconciliation failed with an unexpected error
+	ConditionDegraded ConditionType = "Degraded"
+	// ConditionPrometheus reports status of the managed Prometheus
+	ConditionPrometheus ConditionType = "Prometheus"
+	// ConditionAlertmanager reports status of the managed Alertmanager
+	ConditionAlertmanager ConditionType = "Alertmanager"
+	// ConditionRouter reports status of router configmaps
+	ConditionRouter ConditionType = "Router"
+	// ConditionCertificates reports status of tls secrets
+	ConditionCertificates ConditionType = "Certificates"
+	// ConditionMCMController reports status of mcm monitoring controller deployment
+	ConditionMCMController ConditionType = "MCMController"
+	// ConditionPrometheusOperator reports status of prometheus operator deployment
+	ConditionPrometheusOperator ConditionType = "PrometheusOperator"
+)
+
+// PrometheusExtCondition describes state of PrometheusExt or one of its components
+type PrometheusExtCondition struct {
+	// Type of condition
+	Type ConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown
+	Status v1.ConditionStatus `json:"status"`
+	// One word reason for the condition's last transition
+	Reason string `json:"reason,omitempty"`
+	// Human readable message indicating details about the transition
+	Message string `json:"message,omitempty"`
+	// Last time the condition transitioned from one status to another
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PrometheusExt will start Prometheus and Alertmanager instances with RBAC enabled. It will also enable Multicloud monitoring
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=prometheusexts,scope=Namespaced
+// +operator-sdk:gen-csv:customresourcedefinitions.displayName="Prometheus Operator Extension"
+type PrometheusExt struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PrometheusExtSpec   `json:"spec,omitempty"`
+	Status PrometheusExtStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PrometheusExtList contains a list of PrometheusExt
+type PrometheusExtList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PrometheusExt `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PrometheusExt{}, &PrometheusExtList{})
+}
@@ -0,0 +1,111 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v1beta1
+
+import (
+	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+)
+
+func remoteWriteToHub(in []RemoteWrite) []v1alpha1.RemoteWrite {
+	var out []v1alpha1.RemoteWrite
+	for _, rw := range in {
+		o := v1alpha1.RemoteWrite{
+			URL:           rw.URL,
+			RemoteTimeout: rw.RemoteTimeout,
+			BasicAuth:     (*v1alpha1.RemoteBasicAuth)(rw.BasicAuth),
+			BearerToken:   rw.BearerToken,
+			TLSConfig:     (*v1alpha1.RemoteTLSConfig)(rw.TLSConfig),
+			ProxyURL:      rw.ProxyURL,
+			QueueConfig:   (*v1alpha1.RemoteWriteQueueConfig)(rw.QueueConfig),
+		}
+		for _, c := range rw.WriteRelabelConfigs {
+			o.WriteRelabelConfigs = append(o.WriteRelabelConfigs, v1alpha1.RelabelConfig(c))
+		}
+		out = append(out, o)
+	}
+	return out
+}
+
+func remoteWriteFromHub(in []v1alpha1.RemoteWrite) []RemoteWrite {
+	var out []RemoteWrite
+	for _, rw := range in {
+		o := RemoteWrite{
+			URL:           rw.URL,
+			RemoteTimeout: rw.RemoteTimeout,
+			BasicAuth:     (*RemoteBasicAuth)(rw.BasicAuth),
+			BearerToken:   rw.BearerToken,
+			TLSConfig:     (*RemoteTLSConfig)(rw.TLSConfig),
+			ProxyURL:      rw.ProxyURL,
+			QueueConfig:   (*RemoteWriteQueueConfig)(rw.QueueConfig),
+		}
+		for _, c := range rw.WriteRelabelConfigs {
+			o.WriteRelabelConfigs = append(o.WriteRelabelConfigs, RelabelConfig(c))
+		}
+		out = append(out, o)
+	}
+	return out
+}
+
+func remoteReadToHub(in []RemoteRead) []v1alpha1.RemoteRead {
+	var out []v1alpha1.RemoteRead
+	for _, rr := range in {
+		out = append(out, v1alpha1.RemoteRead{
+			URL:              rr.URL,
+			RemoteTimeout:    rr.RemoteTimeout,
+			RequiredMatchers: rr.RequiredMatchers,
+			ReadRecent:       rr.ReadRecent,
+			BasicAuth:        (*v1alpha1.RemoteBasicAuth)(rr.BasicAuth),
+			BearerToken:      rr.BearerToken,
+			TLSConfig:        (*v1alpha1.RemoteTLSConfig)(rr.TLSConfig),
+			ProxyURL:         rr.ProxyURL,
+		})
+	}
+	return out
+}
+
+func remoteReadFromHub(in []v1alpha1.RemoteRead) []RemoteRead {
+	var out []RemoteRead
+	for _, rr := range in {
+		out = append(out, RemoteRead{
+			URL:              rr.URL,
+			RemoteTimeout:    rr.RemoteTimeout,
+			RequiredMatchers: rr.RequiredMatchers,
+			ReadRecent:       rr.ReadRecent,
+			BasicAuth:        (*RemoteBasicAuth)(rr.BasicAuth),
+			BearerToken:      rr.BearerToken,
+			TLSConfig:        (*RemoteTLSConfig)(rr.TLSConfig),
+			ProxyURL:         rr.ProxyURL,
+		})
+	}
+	return out
+}
+
+func remoteWriteStatusToHub(in []RemoteWriteStatus) []v1alpha1.RemoteWriteStatus {
+	var out []v1alpha1.RemoteWriteStatus
+	for _, s := range in {
+		out = append(out, v1alpha1.RemoteWriteStatus(s))
+	}
+	return out
+}
+
+func remoteWriteStatusFromHub(in []v1alpha1.RemoteWriteStatus) []RemoteWriteStatus {
+	var out []RemoteWriteStatus
+	for _, s := range in {
+		out = append(out, RemoteWriteStatus(s))
+	}
+	return out
+}
@@ -19,11 +19,9 @@ package model
 import (
 	"os"
 	"reflect"
-	"time"
 
 	promv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
 	v1 "k8s.io/api/core/v1"
-	ev1beta1 "k8s.io/api/extensions/v1beta1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -35,6 +33,11 @@ import (
 func AlertmanagerName(cr *promext.PrometheusExt) string {
 	return ObjectName(cr, Alertmanager)
 }
+
+//AlertmanagerStatefulSetName returns name of statefulset created by prometheus operator for managed alertmanager
+func AlertmanagerStatefulSetName(cr *promext.PrometheusExt) string {
+	return "alertmanager-" + AlertmanagerName(cr)
+}
 func alertmanagerLabels(cr *promext.PrometheusExt) map[string]string {
 	labels := make(map[string]string)
 	labels[AppLabelKey] = AppLabelValue
@@ -47,6 +50,11 @@ func alertmanagerLabels(cr *promext.PrometheusExt) map[string]string {
 	return labels
 }
 
+//AlertmanagerPVCLabels returns labels of persistent volume claims created for alertmanager pods
+func AlertmanagerPVCLabels(cr *promext.PrometheusExt) map[string]string {
+	return alertmanagerSvcSelectors(cr)
+}
+
 func alertmanagerSvcSelectors(cr *promext.PrometheusExt) map[string]string {
 	selectors := make(map[string]string)
 	selectors[App] = string(Alertmanager)
@@ -58,18 +66,19 @@ func alertmanagerSvcSelectors(cr *promext.PrometheusExt) map[string]string {
 func AlertmanagerConfigSecret(cr *promext.PrometheusExt) *v1.Secret {
 	secret := &v1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "alertmanager-" + AlertmanagerName(cr),
+			Name:      AlertmanagerConfigSecretName(cr),
 			Namespace: cr.Namespace,
 			Labels:    alertmanagerLabels(cr),
 		},
-		Data: map[string][]byte{"alertmanager.yaml": []byte(alertConfigStr)},
+		Data: map[string][]byte{AlertmanagerConfigKey: []byte(alertConfigStr)},
 	}
 	return secret
 }
 
 //NewAlertmanager create Alertmanager object
 func NewAlertmanager(cr *promext.PrometheusExt) (*promv1.Alertmanager, error) {
-	replicas := int32(1)
+	replicas := Replicas(cr, Alertmanager)
+	scheduling := componentScheduling(cr, cr.Spec.AlertManagerConfig.Scheduling)
 	pvsize := DefaultPVSize
 	scName := cr.Annotations[StorageClassAnn]
 
@@ -78,7 +87,7 @@ func NewAlertmanager(cr *promext.PrometheusExt) (*promv1.Alertmanager, error) {
 	}
 	quantity, err := resource.ParseQuantity(pvsize)
 	if err != nil {
-		return nil, err
+		return nil, NewPermanentError("alertmanager", "invalid pvSize "+pvsize+": "+err.Error())
 	}
 	am := &promv1.Alertmanager{
 		ObjectMeta: metav1.ObjectMeta{
@@ -90,19 +99,22 @@ func NewAlertmanager(cr *promext.PrometheusExt) (*promv1.Alertmanager, error) {
 			PodMetadata: &metav1.ObjectMeta{
 				Labels:            alertmanagerLabels(cr),
 				Annotations:       commonPodAnnotations(),
-				CreationTimestamp: metav1.Time{Time: time.Now()},
+				CreationTimestamp: embeddedCreationTimestamp(cr),
 			},
-			Replicas:     &replicas,
-			Resources:    alertManagerResources(cr),
-			Secrets:      []string{cr.Spec.Certs.MonitoringSecret, cr.Spec.Certs.MonitoringClientSecret},
-			ConfigMaps:   []string{RouterEntryCmName(cr), AlertRouterNgCmName(cr)},
-			RoutePrefix:  "/alertmanager",
-			Containers:   []v1.Container{*NewRouterContainer(cr, Alertmanager)},
-			NodeSelector: cr.Spec.NodeSelector,
+			Replicas:          &replicas,
+			Affinity:          statefulSetAffinity(cr, Alertmanager, scheduling),
+			Tolerations:       scheduling.Tolerations,
+			PriorityClassName: scheduling.PriorityClassName,
+			Resources:         alertManagerResources(cr),
+			Secrets:           []string{cr.Spec.Certs.MonitoringSecret, cr.Spec.Certs.MonitoringClientSecret},
+			ConfigMaps:        []string{RouterEntryCmName(cr), AlertRouterNgCmName(cr)},
+			RoutePrefix:       "/alertmanager",
+			Containers:        []v1.Container{*NewRouterContainer(cr, Alertmanager)},
+			NodeSelector:      scheduling.NodeSelector,
 			Storage: &promv1.StorageSpec{
 				VolumeClaimTemplate: v1.PersistentVolumeClaim{
 					ObjectMeta: metav1.ObjectMeta{
-						CreationTimestamp: metav1.Time{Time: time.Now()},
+						CreationTimestamp: embeddedCreationTimestamp(cr),
 					},
 					Spec: v1.PersistentVolumeClaimSpec{
 						AccessModes:      []v1.PersistentVolumeAccessMode{"ReadWriteOnce"},
@@ -165,64 +177,6 @@ func alertManagerResources(cr *promext.PrometheusExt) v1.ResourceRequirements {
 	return cr.Spec.AlertManagerConfig.Resources
 }
 
-//UpdatedAlertmanager create updated Alertmanager object
-func UpdatedAlertmanager(cr *promext.PrometheusExt, curr *promv1.Alertmanager) (*promv1.Alertmanager, error) {
-	scName := cr.Annotations[StorageClassAnn]
-	pvsize := "10Gi"
-	if cr.Spec.AlertManagerConfig.PVSize != "" {
-		pvsize = cr.Spec.AlertManagerConfig.PVSize
-	}
-	quantity, err := resource.ParseQuantity(pvsize)
-	if err != nil {
-		return nil, err
-	}
-
-	am := curr.DeepCopy()
-	am.Labels = alertmanagerLabels(cr)
-	am.Spec.PodMetadata.Labels = alertmanagerLabels(cr)
-	am.Spec.PodMetadata.Annotations = commonPodAnnotations()
-	if cr.Spec.AlertManagerConfig.ImageTag != "" {
-		am.Spec.Tag = cr.Spec.AlertManagerConfig.ImageTag
-	}
-	am.Spec.Image = alertManagerImage(cr)
-	am.Spec.Resources = alertManagerResources(cr)
-	am.Spec.Secrets = []string{cr.Spec.Certs.MonitoringSecret, cr.Spec.Certs.MonitoringClientSecret}
-	am.Spec.ConfigMaps = []string{RouterEntryCmName(cr), AlertRouterNgCmName(cr)}
-	am.Spec.Containers = []v1.Container{*NewRouterContainer(cr, Alertmanager)}
-	am.Spec.Storage = &promv1.StorageSpec{
-		VolumeClaimTemplate: v1.PersistentVolumeClaim{
-			ObjectMeta: metav1.ObjectMeta{
-				CreationTimestamp: curr.Spec.Storage.VolumeClaimTemplate.CreationTimestamp,
-			},
-			Spec: v1.PersistentVolumeClaimSpec{
-				AccessModes:      []v1.PersistentVolumeAccessMode{"ReadWriteOnce"},
-				StorageClassName: &scName,
-				Resources: v1.ResourceRequirements{
-					Requests: map[v1.ResourceName]resource.Quantity{"storage": quantity},
-				},
-			},
-		},
-	}
-
-	if cr.Spec.ImagePullSecrets != nil && len(cr.Spec.ImagePullSecrets) != 0 {
-		var secrets []v1.LocalObjectReference
-		for _, secret := range cr.Spec.ImagePullSecrets {
-			secrets = append(secrets, v1.LocalObjectReference{Name: secret})
-		}
-		am.Spec.ImagePullSecrets = secrets
-
-	}
-	externalHost := cr.ObjectMeta.Annotations[ClusterHostAnn]
-	externalPort := cr.ObjectMeta.Annotations[ClusterPortAnn]
-	am.Spec.ExternalURL = "https://" + externalHost + ":" + externalPort + "/alertmanager"
-	if cr.Spec.AlertManagerConfig.ServiceAccountName != "" {
-		am.Spec.ServiceAccountName = cr.Spec.AlertManagerConfig.ServiceAccountName
-	}
-	am.Spec.NodeSelector = cr.Spec.NodeSelector
-
-	return am, nil
-}
-
 //NewAlertmanagetSvc create Alertmanager service object
 func NewAlertmanagetSvc(cr *promext.PrometheusExt) *v1.Service {
 	svc := &v1.Service{
@@ -249,78 +203,3 @@ func NewAlertmanagetSvc(cr *promext.PrometheusExt) *v1.Service {
 	}
 	return svc
 }
-
-//UpdatedAlertmanagetSvc create Alertmanager service object
-func UpdatedAlertmanagetSvc(cr *promext.PrometheusExt, curr *v1.Service) *v1.Service {
-	svc := curr.DeepCopy()
-	svc.Labels = alertmanagerLabels(cr)
-	svc.Spec.Selector = alertmanagerSvcSelectors(cr)
-	svc.Spec.Ports[0].Port = cr.Spec.AlertManagerConfig.ServicePort
-	return svc
-}
-
-//NewAlertmanagerIngress create ingress for managed alertmanager
-func NewAlertmanagerIngress(cr *promext.PrometheusExt) *ev1beta1.Ingress {
-	ingress := &ev1beta1.Ingress{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:        AlertmanagerName(cr),
-			Namespace:   cr.Namespace,
-			Labels:      alertmanagerLabels(cr),
-			Annotations: ingressAnnotations(cr),
-		},
-		Spec: ev1beta1.IngressSpec{
-			Rules: []ev1beta1.IngressRule{
-				{
-					IngressRuleValue: ev1beta1.IngressRuleValue{
-						HTTP: &ev1beta1.HTTPIngressRuleValue{
-							Paths: []ev1beta1.HTTPIngressPath{
-								{
-									Path: "/alertmanager",
-									Backend: ev1beta1.IngressBackend{
-										ServiceName: AlertmanagerName(cr),
-										ServicePort: intstr.IntOrString{
-											Type:   intstr.Int,
-											IntVal: cr.Spec.AlertManagerConfig.ServicePort,
-										},
-									},
-								},
-							},
-						},
-					},
-				},
-			},
-		},
-	}
-	return ingress
-}
-
-//UpdatedAlertmanagetIngress create Alertmanager ingress object
-func UpdatedAlertmanagetIngress(cr *promext.PrometheusExt, curr *ev1beta1.Ingress) *ev1beta1.Ingress {
-	ingress := curr.DeepCopy()
-	ingress.Labels = alertmanagerLabels(cr)
-	ingress.Annotations = ingressAnnotations(cr)
-	ingress.Spec = ev1beta1.IngressSpec{
-		Rules: []ev1beta1.IngressRule{
-			{
-				IngressRuleValue: ev1beta1.IngressRuleValue{
-					HTTP: &ev1beta1.HTTPIngressRuleValue{
-						Paths: []ev1beta1.HTTPIngressPath{
-							{
-								Path: "/alertmanager",
-								Backend: ev1beta1.IngressBackend{
-									ServiceName: AlertmanagerName(cr),
-									ServicePort: intstr.IntOrString{
-										Type:   intstr.Int,
-										IntVal: cr.Spec.AlertManagerConfig.ServicePort,
-									},
-								},
-							},
-						},
-					},
-				},
-			},
-		},
-	}
-
-	return ingress
-}
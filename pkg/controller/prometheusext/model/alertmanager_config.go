@@ -0,0 +1,667 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+
+	prommodel "github.com/prometheus/common/model"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	promext "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+)
+
+const (
+	//AlertmanagerConfigKey is key of alertmanager configuration in secret
+	AlertmanagerConfigKey = "alertmanager.yaml"
+	//AlertmanagerConfigUnmanagedAnn is annotation of alertmanager config secret.
+	//Operator does not change the secret if its value is "true"
+	AlertmanagerConfigUnmanagedAnn = "monitoring.operator.ibm.com/unmanaged"
+)
+
+//SecretValueFunc returns value of key in a secret of namespace
+type SecretValueFunc func(namespace string, selector *v1.SecretKeySelector) (string, error)
+
+// alertmanager.yaml format
+type amConfig struct {
+	Global       *amGlobal       `json:"global,omitempty"`
+	Route        *amRoute        `json:"route"`
+	InhibitRules []amInhibitRule `json:"inhibit_rules,omitempty"`
+	Receivers    []amReceiver    `json:"receivers"`
+}
+type amGlobal struct {
+	ResolveTimeout   string `json:"resolve_timeout,omitempty"`
+	SMTPFrom         string `json:"smtp_from,omitempty"`
+	SMTPSmarthost    string `json:"smtp_smarthost,omitempty"`
+	SMTPAuthUsername string `json:"smtp_auth_username,omitempty"`
+	SMTPAuthPassword string `json:"smtp_auth_password,omitempty"`
+	SMTPRequireTLS   *bool  `json:"smtp_require_tls,omitempty"`
+	SlackAPIURL      string `json:"slack_api_url,omitempty"`
+	PagerdutyURL     string `json:"pagerduty_url,omitempty"`
+	OpsgenieAPIURL   string `json:"opsgenie_api_url,omitempty"`
+}
+type amRoute struct {
+	Receiver       string            `json:"receiver,omitempty"`
+	GroupBy        []string          `json:"group_by,omitempty"`
+	GroupWait      string            `json:"group_wait,omitempty"`
+	GroupInterval  string            `json:"group_interval,omitempty"`
+	RepeatInterval string            `json:"repeat_interval,omitempty"`
+	Match          map[string]string `json:"match,omitempty"`
+	MatchRE        map[string]string `json:"match_re,omitempty"`
+	Continue       bool              `json:"continue,omitempty"`
+	Routes         []amRoute         `json:"routes,omitempty"`
+}
+type amInhibitRule struct {
+	SourceMatch   map[string]string `json:"source_match,omitempty"`
+	SourceMatchRE map[string]string `json:"source_match_re,omitempty"`
+	TargetMatch   map[string]string `json:"target_match,omitempty"`
+	TargetMatchRE map[string]string `json:"target_match_re,omitempty"`
+	Equal         []string          `json:"equal,omitempty"`
+}
+type amReceiver struct {
+	Name             string              `json:"name"`
+	WebhookConfigs   []amWebhookConfig   `json:"webhook_configs,omitempty"`
+	EmailConfigs     []amEmailConfig     `json:"email_configs,omitempty"`
+	SlackConfigs     []amSlackConfig     `json:"slack_configs,omitempty"`
+	PagerdutyConfigs []amPagerdutyConfig `json:"pagerduty_configs,omitempty"`
+	OpsgenieConfigs  []amOpsgenieConfig  `json:"opsgenie_configs,omitempty"`
+}
+type amWebhookConfig struct {
+	SendResolved *bool  `json:"send_resolved,omitempty"`
+	URL          string `json:"url"`
+	MaxAlerts    int32  `json:"max_alerts,omitempty"`
+}
+type amEmailConfig struct {
+	SendResolved *bool             `json:"send_resolved,omitempty"`
+	To           string            `json:"to"`
+	From         string            `json:"from,omitempty"`
+	Smarthost    string            `json:"smarthost,omitempty"`
+	AuthUsername string            `json:"auth_username,omitempty"`
+	AuthPassword string            `json:"auth_password,omitempty"`
+	RequireTLS   *bool             `json:"require_tls,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty"`
+}
+type amSlackConfig struct {
+	SendResolved *bool  `json:"send_resolved,omitempty"`
+	APIURL       string `json:"api_url,omitempty"`
+	Channel      string `json:"channel,omitempty"`
+	Username     string `json:"username,omitempty"`
+	Title        string `json:"title,omitempty"`
+	Text         string `json:"text,omitempty"`
+}
+type amPagerdutyConfig struct {
+	SendResolved *bool  `json:"send_resolved,omitempty"`
+	RoutingKey   string `json:"routing_key,omitempty"`
+	ServiceKey   string `json:"service_key,omitempty"`
+	URL          string `json:"url,omitempty"`
+	Severity     string `json:"severity,omitempty"`
+}
+type amOpsgenieConfig struct {
+	SendResolved *bool  `json:"send_resolved,omitempty"`
+	APIKey       string `json:"api_key"`
+	APIURL       string `json:"api_url,omitempty"`
+	Message      string `json:"message,omitempty"`
+	Priority     string `json:"priority,omitempty"`
+	Tags         string `json:"tags,omitempty"`
+}
+
+//AlertmanagerConfigSecretName returns name of secret which stores alertmanager configuration
+func AlertmanagerConfigSecretName(cr *promext.PrometheusExt) string {
+	return "alertmanager-" + AlertmanagerName(cr)
+}
+
+//IsUnmanagedAlertmanagerConfig tells if user manages alertmanager config secret by hand
+func IsUnmanagedAlertmanagerConfig(secret *v1.Secret) bool {
+	return secret.Annotations[AlertmanagerConfigUnmanagedAnn] == "true"
+}
+
+//AlertmanagerRoutingSecrets returns names of secrets referred by routing section of CR
+func AlertmanagerRoutingSecrets(cr *promext.PrometheusExt) []string {
+	routing := cr.Spec.AlertManagerConfig.Routing
+	if routing == nil {
+		return nil
+	}
+	var selectors []*v1.SecretKeySelector
+	if routing.Global != nil {
+		selectors = append(selectors, routing.Global.SMTPAuthPassword, routing.Global.SlackAPIURL)
+	}
+	return receiverSecrets(routing.Receivers, selectors)
+}
+
+//AlertRouteSecrets returns names of secrets referred by receivers of AlertRoute
+func AlertRouteSecrets(alertRoute *promext.AlertRoute) []string {
+	return receiverSecrets(alertRoute.Spec.Receivers, nil)
+}
+
+//receiverSecrets returns sorted names of secrets referred by receivers and selectors
+func receiverSecrets(receivers []promext.AlertmanagerReceiver, selectors []*v1.SecretKeySelector) []string {
+	for _, receiver := range receivers {
+		for _, c := range receiver.WebhookConfigs {
+			selectors = append(selectors, c.URLSecret)
+		}
+		for _, c := range receiver.EmailConfigs {
+			selectors = append(selectors, c.AuthPassword)
+		}
+		for _, c := range receiver.SlackConfigs {
+			selectors = append(selectors, c.APIURL)
+		}
+		for _, c := range receiver.PagerdutyConfigs {
+			selectors = append(selectors, c.RoutingKey, c.ServiceKey)
+		}
+		for _, c := range receiver.OpsgenieConfigs {
+			selectors = append(selectors, c.APIKey)
+		}
+	}
+	names := make(map[string]bool)
+	for _, selector := range selectors {
+		if selector != nil {
+			names[selector.Name] = true
+		}
+	}
+	var secrets []string
+	for name := range names {
+		secrets = append(secrets, name)
+	}
+	sort.Strings(secrets)
+	return secrets
+}
+
+//validateTenantReceiver checks credentials of receiver of AlertRoute. Alertmanager fills in empty smtp credentials from
+//global settings of CR, so email configs must have their own credentials when CR has global ones. Other settings are
+//checked by checkAmReceiver without global settings, because they are not used by tenant receivers
+func validateTenantReceiver(global *promext.AlertmanagerGlobal, receiver *promext.AlertmanagerReceiver) error {
+	if global == nil || (global.SMTPAuthUsername == "" && global.SMTPAuthPassword == nil) {
+		return nil
+	}
+	for _, c := range receiver.EmailConfigs {
+		if c.AuthUsername == "" || c.AuthPassword == nil {
+			return fmt.Errorf("email authUsername and authPassword are required")
+		}
+	}
+	return nil
+}
+
+func validateMatchRE(matchRE map[string]string) error {
+	for label, re := range matchRE {
+		if _, err := regexp.Compile("^(?:" + re + ")$"); err != nil {
+			return fmt.Errorf("invalid regular expression for label %s: %v", label, err)
+		}
+	}
+	return nil
+}
+
+//AlertmanagerConfigYaml renders alertmanager.yaml from routing section of CR and merges routes of AlertRoutes into it.
+//It returns validation errors of each AlertRoute by namespace/name. Invalid routes of AlertRoutes are skipped
+func AlertmanagerConfigYaml(cr *promext.PrometheusExt, alertRoutes []promext.AlertRoute, secretValue SecretValueFunc) ([]byte, map[string][]promext.AlertRouteError, error) {
+	routing := cr.Spec.AlertManagerConfig.Routing
+	value := func(selector *v1.SecretKeySelector) (string, error) {
+		return secretValue(cr.Namespace, selector)
+	}
+
+	config := &amConfig{Route: newAmRoute(&routing.Route)}
+	if g := routing.Global; g != nil {
+		smtpPassword, err := optionalValue(value, g.SMTPAuthPassword)
+		if err != nil {
+			return nil, nil, err
+		}
+		slackURL, err := optionalValue(value, g.SlackAPIURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		config.Global = &amGlobal{
+			ResolveTimeout:   g.ResolveTimeout,
+			SMTPFrom:         g.SMTPFrom,
+			SMTPSmarthost:    g.SMTPSmarthost,
+			SMTPAuthUsername: g.SMTPAuthUsername,
+			SMTPAuthPassword: smtpPassword,
+			SMTPRequireTLS:   g.SMTPRequireTLS,
+			SlackAPIURL:      slackURL,
+			PagerdutyURL:     g.PagerdutyURL,
+			OpsgenieAPIURL:   g.OpsgenieAPIURL,
+		}
+	}
+	for i := range routing.Receivers {
+		receiver, err := newAmReceiver(&routing.Receivers[i], value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("receiver %s: %v", routing.Receivers[i].Name, err)
+		}
+		config.Receivers = append(config.Receivers, *receiver)
+	}
+	for _, rule := range routing.InhibitRules {
+		config.InhibitRules = append(config.InhibitRules, amInhibitRule{
+			SourceMatch:   rule.SourceMatch,
+			SourceMatchRE: rule.SourceMatchRE,
+			TargetMatch:   rule.TargetMatch,
+			TargetMatchRE: rule.TargetMatchRE,
+			Equal:         rule.Equal,
+		})
+	}
+
+	// routing of CR is checked by the same rules as rendered configuration before AlertRoutes are merged,
+	// so that errors of CR are reported as invalid routing
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := loadAmConfig(data); err != nil {
+		return nil, nil, NewPermanentError("alertmanager", "invalid routing: "+err.Error())
+	}
+
+	routeErrors := make(map[string][]promext.AlertRouteError)
+	var tenantRoutes []amRoute
+	for i := range alertRoutes {
+		receivers, routes, errs := mergeAlertRoute(routing.Global, &alertRoutes[i], secretValue)
+		config.Receivers = append(config.Receivers, receivers...)
+		tenantRoutes = append(tenantRoutes, routes...)
+		routeErrors[alertRoutes[i].Namespace+"/"+alertRoutes[i].Name] = errs
+	}
+	// routes of AlertRoutes are matched before routes of CR. They continue so that routes of CR still get the alerts
+	config.Route.Routes = append(tenantRoutes, config.Route.Routes...)
+
+	data, err = yaml.Marshal(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	// make sure rendered configuration can be loaded by alertmanager
+	if _, err := loadAmConfig(data); err != nil {
+		return nil, nil, NewPermanentError("alertmanager", "rendered alertmanager configuration is invalid: "+err.Error())
+	}
+	return data, routeErrors, nil
+}
+
+//loadAmConfig is the validator of alertmanager configuration. It is used for routing of CR and for rendered configuration.
+//It parses alertmanager.yaml and checks it like config.Load of alertmanager does when it loads the file:
+//receivers must be unique and have the settings which are not filled in from global settings, routes must refer
+//defined receivers and the root route must not have matchers
+func loadAmConfig(data []byte) (*amConfig, error) {
+	config := &amConfig{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, err
+	}
+	global := config.Global
+	if global == nil {
+		global = &amGlobal{}
+	}
+	if err := checkAmDuration("resolve_timeout", global.ResolveTimeout, false); err != nil {
+		return nil, err
+	}
+	receivers := make(map[string]bool)
+	for _, receiver := range config.Receivers {
+		if receiver.Name == "" {
+			return nil, fmt.Errorf("missing name in receiver")
+		}
+		if receivers[receiver.Name] {
+			return nil, fmt.Errorf("notification config name %q is not unique", receiver.Name)
+		}
+		receivers[receiver.Name] = true
+		if err := checkAmReceiver(global, &receiver); err != nil {
+			return nil, fmt.Errorf("receiver %s: %v", receiver.Name, err)
+		}
+	}
+	if config.Route == nil {
+		return nil, fmt.Errorf("no routes provided")
+	}
+	if config.Route.Receiver == "" {
+		return nil, fmt.Errorf("root route must specify a default receiver")
+	}
+	if len(config.Route.Match) > 0 || len(config.Route.MatchRE) > 0 {
+		return nil, fmt.Errorf("root route must not have any matchers")
+	}
+	if config.Route.Continue {
+		return nil, fmt.Errorf("cannot have continue in root route")
+	}
+	if err := checkAmRoute(config.Route, receivers); err != nil {
+		return nil, err
+	}
+	for _, rule := range config.InhibitRules {
+		for _, matchRE := range []map[string]string{rule.SourceMatchRE, rule.TargetMatchRE} {
+			if err := validateMatchRE(matchRE); err != nil {
+				return nil, fmt.Errorf("inhibit rule: %v", err)
+			}
+		}
+	}
+	return config, nil
+}
+
+func checkAmReceiver(global *amGlobal, receiver *amReceiver) error {
+	for _, c := range receiver.WebhookConfigs {
+		if err := checkAmURL("webhook url", c.URL); err != nil {
+			return err
+		}
+	}
+	for _, c := range receiver.EmailConfigs {
+		if c.To == "" {
+			return fmt.Errorf("missing to address in email config")
+		}
+		if c.Smarthost == "" && global.SMTPSmarthost == "" {
+			return fmt.Errorf("no global SMTP smarthost set")
+		}
+		if c.From == "" && global.SMTPFrom == "" {
+			return fmt.Errorf("no global SMTP from set")
+		}
+	}
+	for _, c := range receiver.SlackConfigs {
+		url := c.APIURL
+		if url == "" {
+			url = global.SlackAPIURL
+		}
+		if url == "" {
+			return fmt.Errorf("no global Slack API URL set")
+		}
+		if err := checkAmURL("slack api_url", url); err != nil {
+			return err
+		}
+	}
+	for _, c := range receiver.PagerdutyConfigs {
+		if c.RoutingKey == "" && c.ServiceKey == "" {
+			return fmt.Errorf("missing service or routing key in PagerDuty config")
+		}
+	}
+	for _, c := range receiver.OpsgenieConfigs {
+		if c.APIKey == "" {
+			return fmt.Errorf("missing api_key in OpsGenie config")
+		}
+	}
+	return nil
+}
+
+func checkAmRoute(route *amRoute, receivers map[string]bool) error {
+	if route.Receiver != "" && !receivers[route.Receiver] {
+		return fmt.Errorf("undefined receiver %q used in route", route.Receiver)
+	}
+	groupBy := make(map[string]bool)
+	for _, label := range route.GroupBy {
+		if label == "..." {
+			continue
+		}
+		if !prommodel.LabelName(label).IsValid() {
+			return fmt.Errorf("invalid label name %q in group_by list", label)
+		}
+		if groupBy[label] {
+			return fmt.Errorf("duplicated label %q in group_by", label)
+		}
+		groupBy[label] = true
+	}
+	for label := range route.Match {
+		if !prommodel.LabelName(label).IsValid() {
+			return fmt.Errorf("invalid label name %q in match", label)
+		}
+	}
+	if err := validateMatchRE(route.MatchRE); err != nil {
+		return err
+	}
+	if err := checkAmDuration("group_wait", route.GroupWait, true); err != nil {
+		return err
+	}
+	if err := checkAmDuration("group_interval", route.GroupInterval, false); err != nil {
+		return err
+	}
+	if err := checkAmDuration("repeat_interval", route.RepeatInterval, false); err != nil {
+		return err
+	}
+	for i := range route.Routes {
+		if err := checkAmRoute(&route.Routes[i], receivers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkAmDuration(name, value string, zero bool) error {
+	if value == "" {
+		return nil
+	}
+	d, err := prommodel.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	if d == 0 && !zero {
+		return fmt.Errorf("%s cannot be zero", name)
+	}
+	return nil
+}
+
+func checkAmURL(name, value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %v", name, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q for %s", u.Scheme, name)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("missing host for %s", name)
+	}
+	return nil
+}
+
+//AlertRouteReceiverName returns name of AlertRoute receiver in alertmanager configuration
+func AlertRouteReceiverName(alertRoute *promext.AlertRoute, receiver string) string {
+	return alertRoute.Namespace + "/" + alertRoute.Name + "/" + receiver
+}
+
+//mergeAlertRoute returns valid receivers and routes of AlertRoute and errors of invalid ones
+func mergeAlertRoute(global *promext.AlertmanagerGlobal, alertRoute *promext.AlertRoute, secretValue SecretValueFunc) ([]amReceiver, []amRoute, []promext.AlertRouteError) {
+	var errs []promext.AlertRouteError
+	value := func(selector *v1.SecretKeySelector) (string, error) {
+		return secretValue(alertRoute.Namespace, selector)
+	}
+	receivers := make(map[string]*amReceiver)
+	for i := range alertRoute.Spec.Receivers {
+		r := &alertRoute.Spec.Receivers[i]
+		field := fmt.Sprintf("receivers[%d]", i)
+		if r.Name == "" || receivers[r.Name] != nil {
+			errs = append(errs, promext.AlertRouteError{Field: field, Message: "receiver name is empty or duplicated"})
+			continue
+		}
+		err := validateTenantReceiver(global, r)
+		var receiver *amReceiver
+		if err == nil {
+			receiver, err = newAmReceiver(r, value)
+		}
+		if err == nil {
+			// values read from secrets of tenant are checked here, so that they do not make whole configuration invalid
+			err = checkAmReceiver(&amGlobal{}, receiver)
+		}
+		if err != nil {
+			errs = append(errs, promext.AlertRouteError{Field: field, Message: err.Error()})
+			continue
+		}
+		receiver.Name = AlertRouteReceiverName(alertRoute, r.Name)
+		receivers[r.Name] = receiver
+	}
+
+	var routes []amRoute
+	used := make(map[string]bool)
+	for i := range alertRoute.Spec.Routes {
+		field := fmt.Sprintf("routes[%d]", i)
+		route := alertRoute.Spec.Routes[i]
+		if route.Receiver == "" {
+			errs = append(errs, promext.AlertRouteError{Field: field, Message: "receiver is required"})
+			continue
+		}
+		r := newAmRoute(&route)
+		if err := checkAmRoute(r, receiverNames(receivers)); err != nil {
+			errs = append(errs, promext.AlertRouteError{Field: field, Message: err.Error()})
+			continue
+		}
+		tenantRoute(r, alertRoute, used)
+		r.Continue = true
+		routes = append(routes, *r)
+	}
+
+	var merged []amReceiver
+	for i := range alertRoute.Spec.Receivers {
+		name := alertRoute.Spec.Receivers[i].Name
+		if receiver, ok := receivers[name]; ok && used[receiver.Name] {
+			merged = append(merged, *receiver)
+			delete(receivers, name)
+		}
+	}
+	return merged, routes, errs
+}
+
+//tenantRoute renames receivers and forces namespace matcher in route and its child routes
+func tenantRoute(route *amRoute, alertRoute *promext.AlertRoute, used map[string]bool) {
+	if route.Receiver != "" {
+		route.Receiver = AlertRouteReceiverName(alertRoute, route.Receiver)
+		used[route.Receiver] = true
+	}
+	match := map[string]string{"namespace": alertRoute.Namespace}
+	for k, v := range route.Match {
+		if k != "namespace" {
+			match[k] = v
+		}
+	}
+	route.Match = match
+	delete(route.MatchRE, "namespace")
+	for i := range route.Routes {
+		tenantRoute(&route.Routes[i], alertRoute, used)
+	}
+}
+
+func receiverNames(receivers map[string]*amReceiver) map[string]bool {
+	names := make(map[string]bool)
+	for name := range receivers {
+		names[name] = true
+	}
+	return names
+}
+
+func optionalValue(value func(*v1.SecretKeySelector) (string, error), selector *v1.SecretKeySelector) (string, error) {
+	if selector == nil {
+		return "", nil
+	}
+	v, err := value(selector)
+	if err != nil {
+		return "", fmt.Errorf("failed to read key %s of secret %s: %v", selector.Key, selector.Name, err)
+	}
+	return v, nil
+}
+
+func newAmReceiver(r *promext.AlertmanagerReceiver, value func(*v1.SecretKeySelector) (string, error)) (*amReceiver, error) {
+	receiver := &amReceiver{Name: r.Name}
+	for _, c := range r.WebhookConfigs {
+		url := c.URL
+		if c.URLSecret != nil {
+			v, err := optionalValue(value, c.URLSecret)
+			if err != nil {
+				return nil, err
+			}
+			url = v
+		}
+		receiver.WebhookConfigs = append(receiver.WebhookConfigs, amWebhookConfig{
+			SendResolved: c.SendResolved,
+			URL:          url,
+			MaxAlerts:    c.MaxAlerts,
+		})
+	}
+	for _, c := range r.EmailConfigs {
+		password, err := optionalValue(value, c.AuthPassword)
+		if err != nil {
+			return nil, err
+		}
+		receiver.EmailConfigs = append(receiver.EmailConfigs, amEmailConfig{
+			SendResolved: c.SendResolved,
+			To:           c.To,
+			From:         c.From,
+			Smarthost:    c.Smarthost,
+			AuthUsername: c.AuthUsername,
+			AuthPassword: password,
+			RequireTLS:   c.RequireTLS,
+			Headers:      c.Headers,
+		})
+	}
+	for _, c := range r.SlackConfigs {
+		url, err := optionalValue(value, c.APIURL)
+		if err != nil {
+			return nil, err
+		}
+		receiver.SlackConfigs = append(receiver.SlackConfigs, amSlackConfig{
+			SendResolved: c.SendResolved,
+			APIURL:       url,
+			Channel:      c.Channel,
+			Username:     c.Username,
+			Title:        c.Title,
+			Text:         c.Text,
+		})
+	}
+	for _, c := range r.PagerdutyConfigs {
+		routingKey, err := optionalValue(value, c.RoutingKey)
+		if err != nil {
+			return nil, err
+		}
+		serviceKey, err := optionalValue(value, c.ServiceKey)
+		if err != nil {
+			return nil, err
+		}
+		receiver.PagerdutyConfigs = append(receiver.PagerdutyConfigs, amPagerdutyConfig{
+			SendResolved: c.SendResolved,
+			RoutingKey:   routingKey,
+			ServiceKey:   serviceKey,
+			URL:          c.URL,
+			Severity:     c.Severity,
+		})
+	}
+	for _, c := range r.OpsgenieConfigs {
+		apiKey, err := optionalValue(value, c.APIKey)
+		if err != nil {
+			return nil, err
+		}
+		receiver.OpsgenieConfigs = append(receiver.OpsgenieConfigs, amOpsgenieConfig{
+			SendResolved: c.SendResolved,
+			APIKey:       apiKey,
+			APIURL:       c.APIURL,
+			Message:      c.Message,
+			Priority:     c.Priority,
+			Tags:         c.Tags,
+		})
+	}
+	return receiver, nil
+}
+
+func newAmRoute(route *promext.AlertmanagerRoute) *amRoute {
+	r := &amRoute{
+		Receiver:       route.Receiver,
+		GroupBy:        route.GroupBy,
+		GroupWait:      route.GroupWait,
+		GroupInterval:  route.GroupInterval,
+		RepeatInterval: route.RepeatInterval,
+		Match:          route.Match,
+		MatchRE:        copyStringMap(route.MatchRE),
+		Continue:       route.Continue,
+	}
+	for i := range route.Routes {
+		r.Routes = append(r.Routes, *newAmRoute(&route.Routes[i]))
+	}
+	return r
+}
+
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]string)
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,347 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	promext "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+)
+
+func secretKey(name, key string) *v1.SecretKeySelector {
+	return &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: name}, Key: key}
+}
+
+//testSecretValues returns values of secrets keyed by namespace/name/key
+func testSecretValues(values map[string]string) SecretValueFunc {
+	return func(namespace string, selector *v1.SecretKeySelector) (string, error) {
+		value, ok := values[namespace+"/"+selector.Name+"/"+selector.Key]
+		if !ok {
+			return "", fmt.Errorf("secret %s not found", selector.Name)
+		}
+		return value, nil
+	}
+}
+
+func TestAlertmanagerConfigYaml(t *testing.T) {
+	secrets := map[string]string{
+		"monitoring/alerting/slack":    "https://hooks.slack.com/platform",
+		"monitoring/alerting/smtp":     "platform-password",
+		"team-a/team-alerting/slack":   "https://hooks.slack.com/team-a",
+		"team-a/team-alerting/smtp":    "team-a-password",
+		"team-a/team-alerting/webhook": "https://team-a.example.com/hook",
+		"team-a/team-alerting/invalid": "team-a.example.com/hook",
+	}
+	platformRouting := func() *promext.AlertmanagerRouting {
+		return &promext.AlertmanagerRouting{
+			Global: &promext.AlertmanagerGlobal{
+				ResolveTimeout:   "5m",
+				SMTPFrom:         "alerts@example.com",
+				SMTPSmarthost:    "smtp.example.com:587",
+				SMTPAuthUsername: "platform",
+				SMTPAuthPassword: secretKey("alerting", "smtp"),
+				SlackAPIURL:      secretKey("alerting", "slack"),
+			},
+			Receivers: []promext.AlertmanagerReceiver{
+				{Name: "platform-slack", SlackConfigs: []promext.AlertmanagerSlackConfig{{Channel: "#alerts"}}},
+				{Name: "platform-email", EmailConfigs: []promext.AlertmanagerEmailConfig{{To: "ops@example.com"}}},
+			},
+			Route: promext.AlertmanagerRoute{
+				Receiver: "platform-slack",
+				GroupBy:  []string{"alertname"},
+				Routes:   []promext.AlertmanagerRoute{{Receiver: "platform-email", Match: map[string]string{"severity": "critical"}}},
+			},
+		}
+	}
+	alertRoute := func(receivers ...promext.AlertmanagerReceiver) promext.AlertRoute {
+		var routes []promext.AlertmanagerRoute
+		for _, r := range receivers {
+			routes = append(routes, promext.AlertmanagerRoute{Receiver: r.Name, Match: map[string]string{"namespace": "other", "app": "a"}})
+		}
+		return promext.AlertRoute{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-alerting", Namespace: "team-a"},
+			Spec:       promext.AlertRouteSpec{Receivers: receivers, Routes: routes},
+		}
+	}
+	tests := []struct {
+		name          string
+		routing       func() *promext.AlertmanagerRouting
+		alertRoutes   []promext.AlertRoute
+		wantErr       bool
+		wantPermanent bool
+		// receivers in rendered configuration
+		wantReceivers []string
+		// receivers of root route in rendered configuration
+		wantRoutes []string
+		// number of errors of team-a/team-alerting
+		wantRouteErrors int
+		check           func(t *testing.T, config *amConfig)
+	}{
+		{
+			name:          "platform routing",
+			routing:       platformRouting,
+			wantReceivers: []string{"platform-slack", "platform-email"},
+			wantRoutes:    []string{"platform-email"},
+			check: func(t *testing.T, config *amConfig) {
+				if config.Global.SlackAPIURL != "https://hooks.slack.com/platform" || config.Global.SMTPAuthPassword != "platform-password" {
+					t.Errorf("global secrets are not rendered: %+v", config.Global)
+				}
+			},
+		},
+		{
+			name: "route refers undefined receiver",
+			routing: func() *promext.AlertmanagerRouting {
+				routing := platformRouting()
+				routing.Route.Routes[0].Receiver = "undefined"
+				return routing
+			},
+			wantErr:       true,
+			wantPermanent: true,
+		},
+		{
+			name: "invalid duration",
+			routing: func() *promext.AlertmanagerRouting {
+				routing := platformRouting()
+				routing.Route.GroupWait = "soon"
+				return routing
+			},
+			wantErr:       true,
+			wantPermanent: true,
+		},
+		{
+			name: "invalid url of receiver",
+			routing: func() *promext.AlertmanagerRouting {
+				routing := platformRouting()
+				routing.Receivers[1].WebhookConfigs = []promext.AlertmanagerWebhookConfig{{URL: "example.com/hook"}}
+				return routing
+			},
+			wantErr:       true,
+			wantPermanent: true,
+		},
+		{
+			name: "rendered configuration with duplicated receiver",
+			routing: func() *promext.AlertmanagerRouting {
+				routing := platformRouting()
+				routing.Receivers[1].Name = "team-a/team-alerting/slack"
+				routing.Route.Routes[0].Receiver = "team-a/team-alerting/slack"
+				return routing
+			},
+			alertRoutes: []promext.AlertRoute{alertRoute(
+				promext.AlertmanagerReceiver{Name: "slack", SlackConfigs: []promext.AlertmanagerSlackConfig{
+					{Channel: "#team-a", APIURL: secretKey("team-alerting", "slack")}}},
+			)},
+			wantErr:       true,
+			wantPermanent: true,
+		},
+		{
+			name: "missing secret",
+			routing: func() *promext.AlertmanagerRouting {
+				routing := platformRouting()
+				routing.Global.SlackAPIURL = secretKey("missing", "slack")
+				return routing
+			},
+			wantErr: true,
+		},
+		{
+			name:    "tenant receiver with own credentials",
+			routing: platformRouting,
+			alertRoutes: []promext.AlertRoute{alertRoute(
+				promext.AlertmanagerReceiver{Name: "slack", SlackConfigs: []promext.AlertmanagerSlackConfig{
+					{Channel: "#team-a", APIURL: secretKey("team-alerting", "slack")}}},
+				promext.AlertmanagerReceiver{Name: "hook", WebhookConfigs: []promext.AlertmanagerWebhookConfig{
+					{URLSecret: secretKey("team-alerting", "webhook")}}},
+			)},
+			wantReceivers: []string{"platform-slack", "platform-email", "team-a/team-alerting/slack", "team-a/team-alerting/hook"},
+			wantRoutes:    []string{"team-a/team-alerting/slack", "team-a/team-alerting/hook", "platform-email"},
+			check: func(t *testing.T, config *amConfig) {
+				route := config.Route.Routes[0]
+				if !route.Continue || !reflect.DeepEqual(route.Match, map[string]string{"namespace": "team-a", "app": "a"}) {
+					t.Errorf("tenant route is not isolated: %+v", route)
+				}
+				if url := config.Receivers[2].SlackConfigs[0].APIURL; url != "https://hooks.slack.com/team-a" {
+					t.Errorf("tenant slack url = %s, want url from secret of tenant namespace", url)
+				}
+			},
+		},
+		{
+			name:    "tenant slack receiver does not inherit global url",
+			routing: platformRouting,
+			alertRoutes: []promext.AlertRoute{alertRoute(
+				promext.AlertmanagerReceiver{Name: "slack", SlackConfigs: []promext.AlertmanagerSlackConfig{{Channel: "#team-a"}}},
+			)},
+			wantReceivers:   []string{"platform-slack", "platform-email"},
+			wantRoutes:      []string{"platform-email"},
+			wantRouteErrors: 2,
+		},
+		{
+			name:    "tenant email receiver does not inherit global smtp settings",
+			routing: platformRouting,
+			alertRoutes: []promext.AlertRoute{alertRoute(
+				promext.AlertmanagerReceiver{Name: "email", EmailConfigs: []promext.AlertmanagerEmailConfig{{To: "team-a@example.com"}}},
+			)},
+			wantReceivers:   []string{"platform-slack", "platform-email"},
+			wantRoutes:      []string{"platform-email"},
+			wantRouteErrors: 2,
+		},
+		{
+			name:    "tenant email receiver without credentials when global has credentials",
+			routing: platformRouting,
+			alertRoutes: []promext.AlertRoute{alertRoute(
+				promext.AlertmanagerReceiver{Name: "email", EmailConfigs: []promext.AlertmanagerEmailConfig{
+					{To: "team-a@example.com", From: "team-a@example.com", Smarthost: "smtp.team-a.example.com:587"}}},
+			)},
+			wantReceivers:   []string{"platform-slack", "platform-email"},
+			wantRoutes:      []string{"platform-email"},
+			wantRouteErrors: 2,
+		},
+		{
+			name:    "tenant email receiver with own credentials",
+			routing: platformRouting,
+			alertRoutes: []promext.AlertRoute{alertRoute(
+				promext.AlertmanagerReceiver{Name: "email", EmailConfigs: []promext.AlertmanagerEmailConfig{
+					{To: "team-a@example.com", From: "team-a@example.com", Smarthost: "smtp.team-a.example.com:587",
+						AuthUsername: "team-a", AuthPassword: secretKey("team-alerting", "smtp")}}},
+			)},
+			wantReceivers: []string{"platform-slack", "platform-email", "team-a/team-alerting/email"},
+			wantRoutes:    []string{"team-a/team-alerting/email", "platform-email"},
+			check: func(t *testing.T, config *amConfig) {
+				if password := config.Receivers[2].EmailConfigs[0].AuthPassword; password != "team-a-password" {
+					t.Errorf("tenant smtp password = %s, want password from secret of tenant namespace", password)
+				}
+			},
+		},
+		{
+			name:    "tenant receiver with invalid url in secret",
+			routing: platformRouting,
+			alertRoutes: []promext.AlertRoute{alertRoute(
+				promext.AlertmanagerReceiver{Name: "hook", WebhookConfigs: []promext.AlertmanagerWebhookConfig{
+					{URLSecret: secretKey("team-alerting", "invalid")}}},
+			)},
+			wantReceivers:   []string{"platform-slack", "platform-email"},
+			wantRoutes:      []string{"platform-email"},
+			wantRouteErrors: 2,
+		},
+		{
+			name:    "tenant secret in namespace of CR is not used",
+			routing: platformRouting,
+			alertRoutes: []promext.AlertRoute{alertRoute(
+				promext.AlertmanagerReceiver{Name: "slack", SlackConfigs: []promext.AlertmanagerSlackConfig{
+					{Channel: "#team-a", APIURL: secretKey("alerting", "slack")}}},
+			)},
+			wantReceivers:   []string{"platform-slack", "platform-email"},
+			wantRoutes:      []string{"platform-email"},
+			wantRouteErrors: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &promext.PrometheusExt{ObjectMeta: metav1.ObjectMeta{Name: "monitoring", Namespace: "monitoring"}}
+			cr.Spec.AlertManagerConfig.Routing = tt.routing()
+			data, routeErrors, err := AlertmanagerConfigYaml(cr, tt.alertRoutes, testSecretValues(secrets))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AlertmanagerConfigYaml() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if IsPermanentErr(err) != tt.wantPermanent {
+					t.Errorf("IsPermanentErr() = %v, want %v", IsPermanentErr(err), tt.wantPermanent)
+				}
+				return
+			}
+			config := &amConfig{}
+			if err := yaml.UnmarshalStrict(data, config); err != nil {
+				t.Fatalf("rendered configuration can not be loaded: %v", err)
+			}
+			var receivers []string
+			for _, r := range config.Receivers {
+				receivers = append(receivers, r.Name)
+			}
+			if !reflect.DeepEqual(receivers, tt.wantReceivers) {
+				t.Errorf("receivers = %v, want %v", receivers, tt.wantReceivers)
+			}
+			var routes []string
+			for _, r := range config.Route.Routes {
+				routes = append(routes, r.Receiver)
+			}
+			if !reflect.DeepEqual(routes, tt.wantRoutes) {
+				t.Errorf("routes = %v, want %v", routes, tt.wantRoutes)
+			}
+			if got := len(routeErrors["team-a/team-alerting"]); got != tt.wantRouteErrors {
+				t.Errorf("AlertRoute errors = %v, want %d errors", routeErrors["team-a/team-alerting"], tt.wantRouteErrors)
+			}
+			if tt.check != nil {
+				tt.check(t, config)
+			}
+		})
+	}
+}
+
+func TestLoadAmConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			config: `
+global:
+  slack_api_url: https://hooks.slack.com/platform
+receivers:
+- name: slack
+  slack_configs:
+  - channel: '#alerts'
+route:
+  receiver: slack
+  group_by: [alertname, namespace]
+  group_wait: 0s
+  routes:
+  - receiver: slack
+    match:
+      severity: critical
+`,
+		},
+		{name: "unknown field", config: "route:\n  receiver: a\n  unknown: b\nreceivers:\n- name: a\n", wantErr: true},
+		{name: "no route", config: "receivers:\n- name: a\n", wantErr: true},
+		{name: "root route without receiver", config: "route:\n  group_by: [alertname]\nreceivers:\n- name: a\n", wantErr: true},
+		{name: "root route with matchers", config: "route:\n  receiver: a\n  match:\n    severity: critical\nreceivers:\n- name: a\n", wantErr: true},
+		{name: "root route with continue", config: "route:\n  receiver: a\n  continue: true\nreceivers:\n- name: a\n", wantErr: true},
+		{name: "undefined receiver", config: "route:\n  receiver: a\n  routes:\n  - receiver: b\nreceivers:\n- name: a\n", wantErr: true},
+		{name: "duplicated receiver", config: "route:\n  receiver: a\nreceivers:\n- name: a\n- name: a\n", wantErr: true},
+		{name: "duplicated group by label", config: "route:\n  receiver: a\n  group_by: [alertname, alertname]\nreceivers:\n- name: a\n", wantErr: true},
+		{name: "invalid group by label", config: "route:\n  receiver: a\n  group_by: [alert-name]\nreceivers:\n- name: a\n", wantErr: true},
+		{name: "zero repeat interval", config: "route:\n  receiver: a\n  repeat_interval: 0s\nreceivers:\n- name: a\n", wantErr: true},
+		{name: "slack without url", config: "route:\n  receiver: a\nreceivers:\n- name: a\n  slack_configs:\n  - channel: '#a'\n", wantErr: true},
+		{name: "email without smarthost", config: "route:\n  receiver: a\nreceivers:\n- name: a\n  email_configs:\n  - to: a@example.com\n    from: b@example.com\n", wantErr: true},
+		{name: "email with global smarthost", config: "global:\n  smtp_smarthost: smtp.example.com:587\n  smtp_from: b@example.com\nroute:\n  receiver: a\nreceivers:\n- name: a\n  email_configs:\n  - to: a@example.com\n"},
+		{name: "webhook with relative url", config: "route:\n  receiver: a\nreceivers:\n- name: a\n  webhook_configs:\n  - url: example.com/hook\n", wantErr: true},
+		{name: "invalid inhibit regular expression", config: "route:\n  receiver: a\nreceivers:\n- name: a\ninhibit_rules:\n- source_match_re:\n    alertname: '('\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadAmConfig([]byte(tt.config))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadAmConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	//FieldManager is name of field manager used by operator when it applies objects with server-side apply
+	FieldManager = "ibm-monitoring-prometheusext-operator"
+	//AppliedHashAnn is annotation with hash of configuration which is applied by operator last time.
+	//It tells if fields are removed from configuration, which can not be found by comparing with live object
+	AppliedHashAnn = "monitoring.operator.ibm.com/applied-hash"
+)
+
+//SetAppliedHash sets hash of object into its AppliedHashAnn annotation
+func SetAppliedHash(obj runtime.Object) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	annotations := accessor.GetAnnotations()
+	delete(annotations, AppliedHashAnn)
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[AppliedHashAnn] = hex.EncodeToString(sum[:])
+	accessor.SetAnnotations(annotations)
+	return nil
+}
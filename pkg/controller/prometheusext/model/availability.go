@@ -0,0 +1,86 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package model
+
+import (
+	v1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	promext "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+)
+
+const (
+	//ReplicaExternalLabel is external label of prometheus which has name of the prometheus pod.
+	//Prometheus operator drops it from alerts, so alerts of all replicas are deduplicated by alertmanager
+	ReplicaExternalLabel = "replica"
+	hostnameTopologyKey  = "kubernetes.io/hostname"
+)
+
+//Replicas returns number of prometheus or alertmanager pods
+func Replicas(cr *promext.PrometheusExt, ot ObjectType) int32 {
+	replicas := cr.Spec.PrometheusConfig.Replicas
+	if ot == Alertmanager {
+		replicas = cr.Spec.AlertManagerConfig.Replicas
+	}
+	if replicas == nil || *replicas < 1 {
+		return 1
+	}
+	return *replicas
+}
+
+func podSelectors(cr *promext.PrometheusExt, ot ObjectType) map[string]string {
+	if ot == Alertmanager {
+		return alertmanagerSvcSelectors(cr)
+	}
+	return prometheusSvcSelectors(cr)
+}
+
+//podAntiAffinity prefers to schedule replicas of prometheus or alertmanager to different nodes,
+//so that a node drain does not stop all of them. It is not required, so replicas still run on small clusters
+func podAntiAffinity(cr *promext.PrometheusExt, ot ObjectType) *v1.Affinity {
+	return &v1.Affinity{
+		PodAntiAffinity: &v1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []v1.WeightedPodAffinityTerm{
+				{
+					Weight: 100,
+					PodAffinityTerm: v1.PodAffinityTerm{
+						LabelSelector: &metav1.LabelSelector{MatchLabels: podSelectors(cr, ot)},
+						TopologyKey:   hostnameTopologyKey,
+					},
+				},
+			},
+		},
+	}
+}
+
+//NewPodDisruptionBudget creates pod disruption budget which allows one pod of prometheus or alertmanager to be evicted at a time
+func NewPodDisruptionBudget(cr *promext.PrometheusExt, ot ObjectType) *policyv1beta1.PodDisruptionBudget {
+	maxUnavailable := intstr.FromInt(1)
+	return &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ObjectName(cr, ot),
+			Namespace: cr.Namespace,
+			Labels:    componentLabels(cr, ot),
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector:       &metav1.LabelSelector{MatchLabels: podSelectors(cr, ot)},
+		},
+	}
+}
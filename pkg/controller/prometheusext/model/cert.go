@@ -24,22 +24,39 @@ import (
 )
 
 //NewCertitication new certification object
-func NewCertitication(name string, cr *proext.PrometheusExt, dnsNames []string) *cert.Certificate {
+func NewCertitication(name string, cr *proext.PrometheusExt, dnsNames []string, ipAddresses []string) *cert.Certificate {
 	return &cert.Certificate{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: cr.Namespace,
 			Labels:    getCertLabels(cr),
 		},
-		Spec: cert.CertificateSpec{
-			SecretName: name,
-			IssuerRef: cert.ObjectReference{
-				Name: "cs-ca-issuer",
-				Kind: cert.IssuerKind,
-			},
-			CommonName: AppLabelValue,
-			DNSNames:   dnsNames,
+		Spec: *certificateSpec(name, cr, dnsNames, ipAddresses),
+	}
+}
+
+func certificateSpec(name string, cr *proext.PrometheusExt, dnsNames []string, ipAddresses []string) *cert.CertificateSpec {
+	issuer := defaultIssuer
+	if cr.Spec.Certs.Issuer != "" {
+		issuer = cr.Spec.Certs.Issuer
+	}
+	issuerKind := cert.IssuerKind
+	if cr.Spec.Certs.IssuerKind != "" {
+		issuerKind = cr.Spec.Certs.IssuerKind
+	}
+	return &cert.CertificateSpec{
+		SecretName: name,
+		IssuerRef: cert.ObjectReference{
+			Name: issuer,
+			Kind: issuerKind,
 		},
+		CommonName:   AppLabelValue,
+		DNSNames:     dnsNames,
+		IPAddresses:  ipAddresses,
+		Duration:     cr.Spec.Certs.Duration,
+		RenewBefore:  cr.Spec.Certs.RenewBefore,
+		KeyAlgorithm: cert.KeyAlgorithm(cr.Spec.Certs.KeyAlgorithm),
+		KeySize:      cr.Spec.Certs.KeySize,
 	}
 }
 
@@ -64,7 +81,13 @@ func MonitoringDNSNames(cr *proext.PrometheusExt) []string {
 		cr.Spec.GrafanaSvcName+"."+cr.Namespace,
 		"*."+cr.Namespace,
 		"*."+cr.Namespace+".svc")
+	dnsNames = append(dnsNames, cr.Spec.Certs.ExtraDNSNames...)
 
 	return dnsNames
 
 }
+
+//MonitoringIPAddresses get ip addresses for monitoring cert
+func MonitoringIPAddresses(cr *proext.PrometheusExt) []string {
+	return cr.Spec.Certs.IPAddresses
+}
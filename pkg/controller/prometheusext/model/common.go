@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	promev1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	monitoringv1alpha1 "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
 )
@@ -48,6 +49,16 @@ func appendCommonLabels(labels map[string]string) map[string]string {
 	return labels
 }
 
+//embeddedCreationTimestamp returns creation timestamp for object metadata embedded in Prometheus and Alertmanager specs.
+//It can not be null and should be stable, so that applied objects are not changed in every loop.
+//Rendered CR has no creation timestamp, so start of unix time is used to keep rendered output same between runs
+func embeddedCreationTimestamp(cr *monitoringv1alpha1.PrometheusExt) metav1.Time {
+	if cr.CreationTimestamp.IsZero() {
+		return metav1.Unix(0, 0)
+	}
+	return cr.CreationTimestamp
+}
+
 //ObjectName returns name related to current cr
 //TODO: howto sync name of grafana?
 func ObjectName(cr *monitoringv1alpha1.PrometheusExt, t ObjectType) string {
@@ -69,6 +80,45 @@ func ManagedAlertmanager(cr *monitoringv1alpha1.PrometheusExt, am *promev1.Alert
 	}
 	return false
 }
+
+//ManagedByCR returns name of CR which manages the object with the labels. It is empty if the object is not managed
+func ManagedByCR(labels map[string]string) string {
+	return labels[managedLabelKey()]
+}
+
+//RetainStorage tells if persistent volume claims are kept after CR is deleted
+func RetainStorage(cr *monitoringv1alpha1.PrometheusExt) bool {
+	return cr.Spec.RetainStorage == nil || *cr.Spec.RetainStorage
+}
+
+//HasFinalizer tells if CR has finalizer
+func HasFinalizer(cr *monitoringv1alpha1.PrometheusExt, finalizer string) bool {
+	for _, f := range cr.Finalizers {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+//RemoveFinalizer removes finalizer from CR
+func RemoveFinalizer(cr *monitoringv1alpha1.PrometheusExt, finalizer string) {
+	var finalizers []string
+	for _, f := range cr.Finalizers {
+		if f != finalizer {
+			finalizers = append(finalizers, f)
+		}
+	}
+	cr.Finalizers = finalizers
+}
+
+//SecretsReferredByCR returns names of secrets whose changes need to be reconciled
+func SecretsReferredByCR(cr *monitoringv1alpha1.PrometheusExt) []string {
+	secrets := []string{cr.Spec.Certs.MonitoringSecret, cr.Spec.Certs.MonitoringClientSecret, AlertmanagerConfigSecretName(cr)}
+	secrets = append(secrets, AlertmanagerRoutingSecrets(cr)...)
+	return append(secrets, RemoteStorageSecrets(cr)...)
+}
+
 func managedLabelKey() string {
 	return "managed"
 }
@@ -91,35 +141,6 @@ func commonPodAnnotations() map[string]string {
 	}
 }
 
-//IReqeueError defines interface for requeueError
-type IReqeueError interface {
-	Reason() string
-}
-type requeueError struct {
-	component string
-	reason    string
-}
-
-// NewRequeueError creats requeueError error
-func NewRequeueError(component string, reason string) error {
-	return &requeueError{component, reason}
-
-}
-func (r *requeueError) Error() string {
-	return "Component " + r.component + "requires to be requeued: " + r.reason
-}
-func (r *requeueError) Reason() string {
-	return r.reason
-}
-
-//IsRequeueErr tells if error type is requeueError
-func IsRequeueErr(e error) bool {
-	switch e.(type) {
-	case IReqeueError:
-		return true
-	}
-	return false
-}
 func ingressAnnotations(cr *monitoringv1alpha1.PrometheusExt) map[string]string {
 	return map[string]string{
 		"kubernetes.io/ingress.class":                    "ibm-icp-management",
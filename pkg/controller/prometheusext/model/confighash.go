@@ -0,0 +1,79 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//ConfigHashAnn is pod annotation with hash of secrets and configmaps mounted by the pod.
+//Pods are restarted when it is changed
+const ConfigHashAnn = "monitoring.operator.ibm.com/config-hash"
+
+//ConfigHash returns hash of content of secrets and configmaps. Objects which do not exist are skipped
+func ConfigHash(secrets []*v1.Secret, cms []*v1.ConfigMap) string {
+	h := sha256.New()
+	for _, secret := range secrets {
+		if secret == nil {
+			continue
+		}
+		h.Write([]byte("secret/" + secret.Name + "\n"))
+		writeData(h, secret.Data)
+	}
+	for _, cm := range cms {
+		if cm == nil {
+			continue
+		}
+		h.Write([]byte("configmap/" + cm.Name + "\n"))
+		data := make(map[string][]byte)
+		for k, v := range cm.Data {
+			data[k] = []byte(v)
+		}
+		for k, v := range cm.BinaryData {
+			data[k] = v
+		}
+		writeData(h, data)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+//SetConfigHash sets config hash annotation of pod metadata
+func SetConfigHash(podMeta *metav1.ObjectMeta, hash string) {
+	if podMeta.Annotations == nil {
+		podMeta.Annotations = make(map[string]string)
+	}
+	podMeta.Annotations[ConfigHashAnn] = hash
+}
+
+func writeData(w io.Writer, data map[string][]byte) {
+	var keys []string
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		w.Write([]byte(k + "\n"))
+		w.Write(data[k])
+		w.Write([]byte("\n"))
+	}
+}
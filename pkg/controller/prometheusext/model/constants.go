@@ -16,6 +16,10 @@
 
 package model
 
+import (
+	promext "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+)
+
 const (
 	App              = "app"
 	None             = "None"
@@ -23,6 +27,8 @@ const (
 	ClusterHostAnn   = "cp-cluster-info-host"
 	ClusterPortAnn   = "cp-cluster-info-port"
 	ManagedIngressCm = "management-ingress-info"
+	//CleanupFinalizer is finalizer of PrometheusExt CR which cleans up resources not garbage collected
+	CleanupFinalizer = "monitoring.operator.ibm.com/cleanup"
 	Ready            = "Ready"
 	NotReady         = "NotReady"
 	//AppLabelKey is key of label
@@ -38,7 +44,7 @@ const (
 	//ExternalPort external port for alertmanager and prometheus
 	ExternalPort = "443"
 	//DefaultPVSize is default storage size for alertmanager and prometheus
-	DefaultPVSize = "10Gi"
+	DefaultPVSize = promext.DefaultPVSize
 	//Prometheus means object is Prometheus
 	Prometheus = ObjectType("prometheus")
 	//Alertmanager means object is Alertmanager
@@ -52,8 +58,9 @@ const (
 	HealthCheckAnnValue = "cert-manager, icp-management-ingress, auth-idp, common-web-ui, platform-header"
 
 	defaultHelmPort      = int32(3000)
-	defaultClusterDomain = "cluster.local"
-	defaultClusterName   = "mycluster"
+	defaultClusterDomain = promext.DefaultClusterDomain
+	defaultClusterName   = promext.DefaultClusterName
+	defaultIssuer        = promext.DefaultIssuer
 
 	amImageEnv        = "AM_IMAGE"
 	promeImageEnv     = "PROME_IMAGE"
@@ -63,6 +70,12 @@ const (
 	routerImageEnv    = "ROUTER_IMAGE"
 	helperImageEnv    = "MCM_HELPER_IMAGE"
 	mcmImageEnv       = "MCM_IMAGE"
+	thanosImageEnv    = "THANOS_IMAGE"
+	operatorNameEnv   = "OPERATOR_NAME"
+
+	defaultOperatorName = "ibm-monitoring-prometheusext-operator"
+	//operatorMetricsPortName is name of port of operator metrics service created by operator-sdk
+	operatorMetricsPortName = "http-metrics"
 
 	imageDigestKey = `sha256:`
 
@@ -756,6 +769,27 @@ const (
         replacement: system
         target_label: metrics_type
   {{- end }}
+{{- if .OperatorMetricsService }}
+
+  # Scrape config for metrics of this operator, like expiry of monitoring certificates
+  # which is used by certificate-expiry rules
+  - job_name: 'ibm-monitoring-operator'
+
+    kubernetes_sd_configs:
+      - role: endpoints
+
+    relabel_configs:
+      - source_labels: [__meta_kubernetes_service_name, __meta_kubernetes_endpoint_port_name]
+        action: keep
+        regex: {{ .OperatorMetricsService }};{{ .OperatorMetricsPort }}
+      - source_labels: [__meta_kubernetes_namespace]
+        action: replace
+        target_label: kubernetes_namespace
+  {{- if not .Standalone }}
+      - target_label: metrics_type
+        replacement: system
+  {{- end }}
+{{- end }}
     `
 	routerEntrypoint = `#!/bin/sh
     if [ -e /opt/ibm/router/certs/tls.crt ]; then
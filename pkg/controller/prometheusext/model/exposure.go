@@ -0,0 +1,187 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package model
+
+import (
+	routev1 "github.com/openshift/api/route/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	promext "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+)
+
+const (
+	ingressClassAnn = "kubernetes.io/ingress.class"
+	routeRewriteAnn = "haproxy.router.openshift.io/rewrite-target"
+)
+
+var (
+	ingressV1GVK      = schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}
+	ingressV1beta1GVK = schema.GroupVersionKind{Group: "extensions", Version: "v1beta1", Kind: "Ingress"}
+)
+
+//IngressGVK returns version of Ingress served by cluster
+func IngressGVK() schema.GroupVersionKind {
+	if CurrentPlatform().IngressV1 {
+		return ingressV1GVK
+	}
+	return ingressV1beta1GVK
+}
+
+//NewIngressObject returns empty Ingress object of version served by cluster, used to read and delete ingresses
+func NewIngressObject() *unstructured.Unstructured {
+	ingress := &unstructured.Unstructured{}
+	ingress.SetGroupVersionKind(IngressGVK())
+	return ingress
+}
+
+//ExposureOf returns exposure of prometheus or alertmanager. Ingress is used if it is not set
+func ExposureOf(cr *promext.PrometheusExt, ot ObjectType) promext.Exposure {
+	var exposure *promext.Exposure
+	if ot == Alertmanager {
+		exposure = cr.Spec.AlertManagerConfig.Exposure
+	} else {
+		exposure = cr.Spec.PrometheusConfig.Exposure
+	}
+	if exposure == nil {
+		return promext.Exposure{Type: promext.ExposureIngress}
+	}
+	e := *exposure
+	if e.Type == "" {
+		e.Type = promext.ExposureIngress
+	}
+	return e
+}
+
+//exposedService returns name, port and port name of the service exposed for prometheus or alertmanager
+func exposedService(cr *promext.PrometheusExt, ot ObjectType) (string, int32, string) {
+	if ot == Alertmanager {
+		return AlertmanagerName(cr), cr.Spec.AlertManagerConfig.ServicePort, "web"
+	}
+	return PromethuesName(cr), cr.Spec.PrometheusConfig.ServicePort, "http"
+}
+
+//componentLabels returns labels of objects of prometheus or alertmanager
+func componentLabels(cr *promext.PrometheusExt, ot ObjectType) map[string]string {
+	if ot == Alertmanager {
+		return alertmanagerLabels(cr)
+	}
+	return PrometheusLabels(cr)
+}
+
+//NewIngress creates ingress for prometheus or alertmanager. It is networking.k8s.io/v1 Ingress if cluster serves it,
+//otherwise extensions/v1beta1 Ingress
+func NewIngress(cr *promext.PrometheusExt, ot ObjectType) *unstructured.Unstructured {
+	exposure := ExposureOf(cr, ot)
+	name, port, _ := exposedService(cr, ot)
+	v1 := CurrentPlatform().IngressV1
+
+	annotations := map[string]string{}
+	if exposure.Annotations != nil {
+		for k, v := range exposure.Annotations {
+			annotations[k] = v
+		}
+	} else {
+		annotations = ingressAnnotations(cr)
+	}
+	spec := map[string]interface{}{}
+	if exposure.IngressClassName != "" {
+		delete(annotations, ingressClassAnn)
+		if v1 {
+			spec["ingressClassName"] = exposure.IngressClassName
+		} else {
+			annotations[ingressClassAnn] = exposure.IngressClassName
+		}
+	}
+
+	path := map[string]interface{}{"path": "/" + string(ot)}
+	if v1 {
+		path["pathType"] = "Prefix"
+		path["backend"] = map[string]interface{}{
+			"service": map[string]interface{}{
+				"name": name,
+				"port": map[string]interface{}{"number": int64(port)},
+			},
+		}
+	} else {
+		path["backend"] = map[string]interface{}{
+			"serviceName": name,
+			"servicePort": int64(port),
+		}
+	}
+	rule := map[string]interface{}{
+		"http": map[string]interface{}{"paths": []interface{}{path}},
+	}
+	if exposure.Host != "" {
+		rule["host"] = exposure.Host
+	}
+	spec["rules"] = []interface{}{rule}
+	if exposure.TLSSecretName != "" {
+		tls := map[string]interface{}{"secretName": exposure.TLSSecretName}
+		if exposure.Host != "" {
+			tls["hosts"] = []interface{}{exposure.Host}
+		}
+		spec["tls"] = []interface{}{tls}
+	}
+
+	ingress := NewIngressObject()
+	ingress.SetName(name)
+	ingress.SetNamespace(cr.Namespace)
+	ingress.SetLabels(componentLabels(cr, ot))
+	ingress.SetAnnotations(annotations)
+	ingress.Object["spec"] = spec
+	return ingress
+}
+
+//NewRoute creates OpenShift route for prometheus or alertmanager. Router containers serve tls with certificate of
+//caCert, so route reencrypts traffic
+func NewRoute(cr *promext.PrometheusExt, ot ObjectType, caCert string) *routev1.Route {
+	exposure := ExposureOf(cr, ot)
+	name, _, portName := exposedService(cr, ot)
+	annotations := exposure.Annotations
+	if annotations == nil {
+		annotations = map[string]string{routeRewriteAnn: "/"}
+	}
+	weight := int32(100)
+	return &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   cr.Namespace,
+			Labels:      componentLabels(cr, ot),
+			Annotations: annotations,
+		},
+		Spec: routev1.RouteSpec{
+			Host: exposure.Host,
+			Path: "/" + string(ot),
+			To: routev1.RouteTargetReference{
+				Kind:   "Service",
+				Name:   name,
+				Weight: &weight,
+			},
+			Port: &routev1.RoutePort{
+				TargetPort: intstr.FromString(portName),
+			},
+			TLS: &routev1.TLSConfig{
+				Termination:                   routev1.TLSTerminationReencrypt,
+				InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+				DestinationCACertificate:      caCert,
+			},
+		},
+	}
+}
@@ -0,0 +1,83 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package model
+
+import (
+	"fmt"
+	"time"
+
+	promext "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+)
+
+const (
+	//PausedAnn stops reconciliation of CR when it is "true". Only status is refreshed
+	PausedAnn = "monitoring.operator.ibm.com/paused"
+	//MaintenanceAnn is end time of maintenance window in RFC3339 format.
+	//All alerts are silenced in Alertmanager until the end time
+	MaintenanceAnn = "monitoring.operator.ibm.com/maintenance"
+)
+
+//IsPaused tells if reconciliation of CR is paused
+func IsPaused(cr *promext.PrometheusExt) bool {
+	return cr.Annotations[PausedAnn] == "true"
+}
+
+//MaintenanceEnd returns end time of maintenance window and whether it is still active at now
+func MaintenanceEnd(cr *promext.PrometheusExt, now time.Time) (time.Time, bool, error) {
+	value, ok := cr.Annotations[MaintenanceAnn]
+	if !ok || value == "" {
+		return time.Time{}, false, nil
+	}
+	end, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid end time of maintenance annotation %s: %v", MaintenanceAnn, err)
+	}
+	return end, end.After(now), nil
+}
+
+//AlertmanagerAPIURL returns url of Alertmanager API v2. Silences are gossiped to all replicas, so the first one is used
+func AlertmanagerAPIURL(cr *promext.PrometheusExt) string {
+	return fmt.Sprintf("http://%s-0.alertmanager-operated.%s.svc:9093/alertmanager/api/v2", AlertmanagerStatefulSetName(cr), cr.Namespace)
+}
+
+//SilenceMatcher is matcher of Alertmanager silence
+type SilenceMatcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+}
+
+//Silence is Alertmanager silence in API v2
+type Silence struct {
+	ID        string           `json:"id,omitempty"`
+	Matchers  []SilenceMatcher `json:"matchers"`
+	StartsAt  time.Time        `json:"startsAt"`
+	EndsAt    time.Time        `json:"endsAt"`
+	CreatedBy string           `json:"createdBy"`
+	Comment   string           `json:"comment"`
+}
+
+//NewMaintenanceSilence creates silence of all alerts from now to end of maintenance window
+func NewMaintenanceSilence(cr *promext.PrometheusExt, now, end time.Time) *Silence {
+	return &Silence{
+		Matchers:  []SilenceMatcher{{Name: "alertname", Value: ".+", IsRegex: true}},
+		StartsAt:  now,
+		EndsAt:    end,
+		CreatedBy: FieldManager,
+		Comment:   fmt.Sprintf("Maintenance window of PrometheusExt %s/%s set by annotation %s", cr.Namespace, cr.Name, MaintenanceAnn),
+	}
+}
@@ -20,7 +20,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
@@ -29,11 +28,8 @@ import (
 	promext "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
 )
 
-var creationTime *metav1.Time
-
 //NewMCMCtlDeployment create new deployment object for mcm controller
 func NewMCMCtlDeployment(cr *promext.PrometheusExt) (*appsv1.Deployment, error) {
-	creationTime = &metav1.Time{Time: time.Now()}
 	spec, err := mcmDeploymentSpec(cr)
 	if err != nil {
 		return nil, err
@@ -52,27 +48,6 @@ func NewMCMCtlDeployment(cr *promext.PrometheusExt) (*appsv1.Deployment, error)
 
 }
 
-//UpdatedMCMCtlDeployment create updated deployment object for mcm controller
-func UpdatedMCMCtlDeployment(cr *promext.PrometheusExt, curr *appsv1.Deployment) (*appsv1.Deployment, error) {
-	creationTime = &curr.ObjectMeta.CreationTimestamp
-	spec, err := mcmDeploymentSpec(cr)
-	if err != nil {
-		return nil, err
-	}
-
-	deployment := curr.DeepCopy()
-	deployment.ObjectMeta.Labels = msmcCtrlLabels(cr)
-
-	deployment.Spec.Template.ObjectMeta.Labels = spec.Template.ObjectMeta.Labels
-	deployment.Spec.Template.ObjectMeta.Annotations = spec.Template.ObjectMeta.Annotations
-	deployment.Spec.Template.Spec.Containers = spec.Template.Spec.Containers
-	deployment.Spec.Template.Spec.Volumes = spec.Template.Spec.Volumes
-	deployment.Spec.Template.Spec.ImagePullSecrets = spec.Template.Spec.ImagePullSecrets
-	deployment.Spec.Template.Spec.ServiceAccountName = spec.Template.Spec.ServiceAccountName
-	deployment.Spec.Template.Spec.NodeSelector = cr.Spec.NodeSelector
-	return deployment, nil
-
-}
 func mcmDeploymentSpec(cr *promext.PrometheusExt) (*appsv1.DeploymentSpec, error) {
 	replicas := int32(1)
 
@@ -88,10 +63,9 @@ func mcmDeploymentSpec(cr *promext.PrometheusExt) (*appsv1.DeploymentSpec, error
 				Annotations: commonPodAnnotations(),
 			},
 			Spec: v1.PodSpec{
-				HostPID:      false,
-				HostIPC:      false,
-				HostNetwork:  false,
-				NodeSelector: cr.Spec.NodeSelector,
+				HostPID:     false,
+				HostIPC:     false,
+				HostNetwork: false,
 				Volumes: []v1.Volume{
 					{
 						Name: "monitoring-ca-certs",
@@ -122,6 +96,7 @@ func mcmDeploymentSpec(cr *promext.PrometheusExt) (*appsv1.DeploymentSpec, error
 		spec.Template.Spec.ImagePullSecrets = secrets
 
 	}
+	applyScheduling(cr, &spec.Template.Spec, cr.Spec.MCMMonitor.Scheduling)
 	if len(cr.Spec.MCMMonitor.ServiceAccountName) != 0 {
 		spec.Template.Spec.ServiceAccountName = cr.Spec.MCMMonitor.ServiceAccountName
 	}
@@ -139,7 +114,6 @@ func mcmDeploymentSpec(cr *promext.PrometheusExt) (*appsv1.DeploymentSpec, error
 
 func mcmContainer(cr *promext.PrometheusExt) (*v1.Container, error) {
 	prometheus, perr := NewPrometheus(cr)
-	prometheus.Spec.PodMetadata.CreationTimestamp = *creationTime
 	if perr != nil {
 		return nil, perr
 	}
@@ -149,7 +123,17 @@ func mcmContainer(cr *promext.PrometheusExt) (*v1.Container, error) {
 		MatchLabels: map[string]string{Component: hubPromemetheus},
 	}
 	prometheus.Spec.Storage = nil
+	// hub prometheus has no pod disruption budget and its pods are not selected by default anti-affinity of managed prometheus
+	hubReplicas := int32(1)
+	prometheus.Spec.Replicas = &hubReplicas
+	prometheus.Spec.Affinity = cr.Spec.PrometheusConfig.Scheduling.Affinity
 	prometheus.Spec.AdditionalScrapeConfigs = nil
+	// remote storage and Thanos sidecar are used by managed prometheus only
+	prometheus.Spec.RemoteWrite = nil
+	prometheus.Spec.RemoteRead = nil
+	prometheus.Spec.Thanos = nil
+	prometheus.Spec.ExternalLabels = nil
+	prometheus.Spec.Secrets = []string{cr.Spec.Certs.MonitoringSecret, cr.Spec.Certs.MonitoringClientSecret}
 	prometheus.Spec.PodMetadata.Labels[AppLabelKey] = hubPromemetheus
 	prometheusStr, merr := json.Marshal(prometheus)
 	if merr != nil {
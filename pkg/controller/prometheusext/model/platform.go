@@ -0,0 +1,97 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package model
+
+import (
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+)
+
+const (
+	openshiftSecurityGroupVersion = "security.openshift.io/v1"
+	openshiftRouteGroupVersion    = "route.openshift.io/v1"
+	networkingGroupVersion        = "networking.k8s.io/v1"
+	//PodSecurityEnforceLabel is label of Pod Security Standards admission which sets security level of namespace
+	PodSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+	//podSecurityLevel is required by router containers which add NET_ADMIN and NET_RAW capabilities
+	podSecurityLevel = "privileged"
+)
+
+//Platform tells which optional APIs are served by cluster
+type Platform struct {
+	//OpenShift is true if cluster serves security context constraints
+	OpenShift bool
+	//Routes is true if cluster serves OpenShift routes
+	Routes bool
+	//IngressV1 is true if cluster serves networking.k8s.io/v1 ingresses. Otherwise extensions/v1beta1 ingresses are used
+	IngressV1 bool
+}
+
+//platform is detected when operator starts
+var platform = Platform{OpenShift: true, Routes: true}
+
+//DetectPlatform checks through discovery which optional APIs are served by cluster
+func DetectPlatform(client discovery.ServerResourcesInterface) (Platform, error) {
+	var p Platform
+	var err error
+	if p.OpenShift, err = servesResource(client, openshiftSecurityGroupVersion, "securitycontextconstraints"); err != nil {
+		return p, err
+	}
+	if p.Routes, err = servesResource(client, openshiftRouteGroupVersion, "routes"); err != nil {
+		return p, err
+	}
+	if p.IngressV1, err = servesResource(client, networkingGroupVersion, "ingresses"); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+func servesResource(client discovery.ServerResourcesInterface, groupVersion string, resource string) (bool, error) {
+	resources, err := client.ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, r := range resources.APIResources {
+		if r.Name == resource {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+//SetPlatform sets platform used to build objects
+func SetPlatform(p Platform) {
+	platform = p
+}
+
+//CurrentPlatform returns platform used to build objects
+func CurrentPlatform() Platform {
+	return platform
+}
+
+//IsOpenShift tells if objects are built for OpenShift. Otherwise they are for plain Kubernetes
+func IsOpenShift() bool {
+	return platform.OpenShift
+}
+
+//PodSecurityLabels returns Pod Security Standards labels of namespace which allow operands to run on plain Kubernetes
+func PodSecurityLabels() map[string]string {
+	return map[string]string{PodSecurityEnforceLabel: podSecurityLevel}
+}
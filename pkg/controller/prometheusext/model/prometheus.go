@@ -21,11 +21,9 @@ import (
 	"html/template"
 	"os"
 	"reflect"
-	"time"
 
 	promv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
 	v1 "k8s.io/api/core/v1"
-	ev1beta1 "k8s.io/api/extensions/v1beta1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -50,26 +48,16 @@ func NewPrometheus(cr *promext.PrometheusExt) (*promv1.Prometheus, error) {
 	return prometheus, nil
 }
 
-//UpdatedPrometheus update Prometheus object for cr
-func UpdatedPrometheus(cr *promext.PrometheusExt, current *promv1.Prometheus) (*promv1.Prometheus, error) {
-	spec, err := prometheusSpec(cr)
-	if err != nil {
-		return nil, err
-	}
-	prometheus := current.DeepCopy()
-	prometheus.Labels = PrometheusLabels(cr)
-	prometheus.Spec = *spec
-	prometheus.Spec.PodMetadata.CreationTimestamp = current.Spec.PodMetadata.CreationTimestamp
-	prometheus.Spec.Storage.VolumeClaimTemplate.CreationTimestamp = current.Spec.Storage.VolumeClaimTemplate.CreationTimestamp
-	prometheus.Spec.NodeSelector = cr.Spec.NodeSelector
-	return prometheus, nil
-}
-
 //PromethuesName returns names for prometheus objects
 func PromethuesName(cr *promext.PrometheusExt) string {
 	return ObjectName(cr, Prometheus)
 }
 
+//PrometheusStatefulSetName returns name of statefulset created by prometheus operator for managed prometheus
+func PrometheusStatefulSetName(cr *promext.PrometheusExt) string {
+	return "prometheus-" + PromethuesName(cr)
+}
+
 //NewPrometheusSvc create service for managed prometheus
 func NewPrometheusSvc(cr *promext.PrometheusExt) *v1.Service {
 	svc := &v1.Service{
@@ -92,85 +80,14 @@ func NewPrometheusSvc(cr *promext.PrometheusExt) *v1.Service {
 			},
 			Selector: prometheusSvcSelectors(cr),
 			Type:     v1.ServiceTypeClusterIP,
+			// Replicas scrape at different times, so requests of a client are sent to the same replica
+			// to get consistent query results through router
+			SessionAffinity: v1.ServiceAffinityClientIP,
 		},
 	}
 	return svc
 }
 
-//UpdatedPrometheusSvc update service for managed prometheus
-func UpdatedPrometheusSvc(cr *promext.PrometheusExt, currentSvc *v1.Service) *v1.Service {
-	svc := currentSvc.DeepCopy()
-	svc.Labels = PrometheusLabels(cr)
-	svc.Spec.Ports[0].Port = cr.Spec.PrometheusConfig.ServicePort
-	svc.Spec.Selector = prometheusSvcSelectors(cr)
-	return svc
-}
-
-//NewPrometheusIngress create ingress for managed prometheus
-func NewPrometheusIngress(cr *promext.PrometheusExt) *ev1beta1.Ingress {
-	ingress := &ev1beta1.Ingress{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:        PromethuesName(cr),
-			Namespace:   cr.Namespace,
-			Labels:      PrometheusLabels(cr),
-			Annotations: ingressAnnotations(cr),
-		},
-		Spec: ev1beta1.IngressSpec{
-			Rules: []ev1beta1.IngressRule{
-				{
-					IngressRuleValue: ev1beta1.IngressRuleValue{
-						HTTP: &ev1beta1.HTTPIngressRuleValue{
-							Paths: []ev1beta1.HTTPIngressPath{
-								{
-									Path: "/prometheus",
-									Backend: ev1beta1.IngressBackend{
-										ServiceName: PromethuesName(cr),
-										ServicePort: intstr.IntOrString{
-											Type:   intstr.Int,
-											IntVal: cr.Spec.PrometheusConfig.ServicePort,
-										},
-									},
-								},
-							},
-						},
-					},
-				},
-			},
-		},
-	}
-	return ingress
-}
-
-//UpdatedPrometheusIngress update ingress for managed prometheus
-func UpdatedPrometheusIngress(cr *promext.PrometheusExt, currentIngress *ev1beta1.Ingress) *ev1beta1.Ingress {
-	ingress := currentIngress.DeepCopy()
-	ingress.Labels = PrometheusLabels(cr)
-	ingress.Annotations = ingressAnnotations(cr)
-	ingress.Spec = ev1beta1.IngressSpec{
-		Rules: []ev1beta1.IngressRule{
-			{
-				IngressRuleValue: ev1beta1.IngressRuleValue{
-					HTTP: &ev1beta1.HTTPIngressRuleValue{
-						Paths: []ev1beta1.HTTPIngressPath{
-							{
-								Path: "/prometheus",
-								Backend: ev1beta1.IngressBackend{
-									ServiceName: PromethuesName(cr),
-									ServicePort: intstr.IntOrString{
-										Type:   intstr.Int,
-										IntVal: cr.Spec.PrometheusConfig.ServicePort,
-									},
-								},
-							},
-						},
-					},
-				},
-			},
-		},
-	}
-	return ingress
-}
-
 //PrometheusLabels return labels for prometheus objects
 func PrometheusLabels(cr *promext.PrometheusExt) map[string]string {
 	labels := make(map[string]string)
@@ -184,6 +101,11 @@ func PrometheusLabels(cr *promext.PrometheusExt) map[string]string {
 	return labels
 }
 
+//PrometheusPVCLabels returns labels of persistent volume claims created for prometheus pods
+func PrometheusPVCLabels(cr *promext.PrometheusExt) map[string]string {
+	return prometheusSvcSelectors(cr)
+}
+
 func prometheusSvcSelectors(cr *promext.PrometheusExt) map[string]string {
 	selectors := make(map[string]string)
 	selectors[App] = string(Prometheus)
@@ -205,7 +127,12 @@ func prometheusDefaultResources() v1.ResourceRequirements {
 	}
 }
 func prometheusSpec(cr *promext.PrometheusExt) (*promv1.PrometheusSpec, error) {
-	replicas := int32(1)
+	replicas := Replicas(cr, Prometheus)
+	replicaLabel := ReplicaExternalLabel
+	scheduling := componentScheduling(cr, cr.Spec.PrometheusConfig.Scheduling)
+	if err := checkRemoteStorage(cr); err != nil {
+		return nil, err
+	}
 	pvsize := DefaultPVSize
 	scName := cr.Annotations[StorageClassAnn]
 
@@ -214,20 +141,25 @@ func prometheusSpec(cr *promext.PrometheusExt) (*promv1.PrometheusSpec, error) {
 	}
 	quantity, err := resource.ParseQuantity(pvsize)
 	if err != nil {
-		return nil, err
+		return nil, NewPermanentError("prometheus", "invalid pvSize "+pvsize+": "+err.Error())
 	}
 	spec := &promv1.PrometheusSpec{
 		PodMetadata: &metav1.ObjectMeta{
 			Labels:            PrometheusLabels(cr),
 			Annotations:       commonPodAnnotations(),
-			CreationTimestamp: metav1.Time{Time: time.Now()},
+			CreationTimestamp: embeddedCreationTimestamp(cr),
 		},
-		Replicas:       &replicas,
-		EnableAdminAPI: true,
-		Resources:      cr.Spec.PrometheusConfig.Resources,
-		RoutePrefix:    "/prometheus",
-		Secrets:        []string{cr.Spec.Certs.MonitoringSecret, cr.Spec.Certs.MonitoringClientSecret},
-		ConfigMaps:     []string{ProRouterNgCmName(cr), RouterEntryCmName(cr), ProLuaCmName(cr), ProLuaUtilsCmName(cr)},
+		Replicas:                 &replicas,
+		ReplicaExternalLabelName: &replicaLabel,
+		ExternalLabels:           prometheusExternalLabels(cr),
+		Affinity:                 statefulSetAffinity(cr, Prometheus, scheduling),
+		Tolerations:              scheduling.Tolerations,
+		PriorityClassName:        scheduling.PriorityClassName,
+		EnableAdminAPI:           true,
+		Resources:                cr.Spec.PrometheusConfig.Resources,
+		RoutePrefix:              "/prometheus",
+		Secrets:                  prometheusSecrets(cr),
+		ConfigMaps:               []string{ProRouterNgCmName(cr), RouterEntryCmName(cr), ProLuaCmName(cr), ProLuaUtilsCmName(cr)},
 		ServiceMonitorSelector: &metav1.LabelSelector{
 			MatchExpressions: []metav1.LabelSelectorRequirement{
 				{
@@ -244,11 +176,14 @@ func prometheusSpec(cr *promext.PrometheusExt) (*promv1.PrometheusSpec, error) {
 			Key: scrapeTargetsFileName(),
 		},
 		Containers:   []v1.Container{*NewRouterContainer(cr, Prometheus)},
-		NodeSelector: cr.Spec.NodeSelector,
+		NodeSelector: scheduling.NodeSelector,
+		RemoteWrite:  remoteWriteSpecs(cr),
+		RemoteRead:   remoteReadSpecs(cr),
+		Thanos:       thanosSpec(cr),
 		Storage: &promv1.StorageSpec{
 			VolumeClaimTemplate: v1.PersistentVolumeClaim{
 				ObjectMeta: metav1.ObjectMeta{
-					CreationTimestamp: metav1.Time{Time: time.Now()},
+					CreationTimestamp: embeddedCreationTimestamp(cr),
 				},
 				Spec: v1.PersistentVolumeClaimSpec{
 					AccessModes:      []v1.PersistentVolumeAccessMode{"ReadWriteOnce"},
@@ -296,17 +231,17 @@ func prometheusSpec(cr *promext.PrometheusExt) (*promv1.PrometheusSpec, error) {
 
 	}
 	if cr.Spec.PrometheusConfig.Retention == "" {
-		spec.Retention = "24h"
+		spec.Retention = promext.DefaultRetention
 	} else {
 		spec.Retention = cr.Spec.PrometheusConfig.Retention
 	}
 	if cr.Spec.PrometheusConfig.ScrapeInterval == "" {
-		spec.ScrapeInterval = "1m"
+		spec.ScrapeInterval = promext.DefaultScrapeInterval
 	} else {
 		spec.ScrapeInterval = cr.Spec.PrometheusConfig.ScrapeInterval
 	}
 	if cr.Spec.PrometheusConfig.EvaluationInterval == "" {
-		spec.EvaluationInterval = "1m"
+		spec.EvaluationInterval = promext.DefaultEvaluationInterval
 	} else {
 		spec.EvaluationInterval = cr.Spec.PrometheusConfig.EvaluationInterval
 	}
@@ -374,6 +309,10 @@ func NewScrapeTargetsSecret(cr *promext.PrometheusExt) (*v1.Secret, error) {
 		NodeExporter:     true,
 		ClusterDomain:    clusterDomain,
 	}
+	if DefaultRuleEnabled(cr, CertificateExpiry) {
+		paras.OperatorMetricsService = operatorMetricsServiceName()
+		paras.OperatorMetricsPort = operatorMetricsPortName
+	}
 	if err := scrapeTargetsTemplate.Execute(&tplBuffer, paras); err != nil {
 		return nil, err
 	}
@@ -388,30 +327,6 @@ func NewScrapeTargetsSecret(cr *promext.PrometheusExt) (*v1.Secret, error) {
 	return secret, nil
 }
 
-//UpdatedScrapeTargetsSecret return secret for prometheus scrape targets
-func UpdatedScrapeTargetsSecret(cr *promext.PrometheusExt, currentSecret *v1.Secret) (*v1.Secret, error) {
-	secret := currentSecret.DeepCopy()
-	var tplBuffer bytes.Buffer
-	clusterDomain := defaultClusterDomain
-	if cr.Spec.ClusterDomain != "" {
-		clusterDomain = cr.Spec.ClusterDomain
-
-	}
-	paras := scrapeTargetConfigParas{
-		Standalone:       !cr.Spec.MCMMonitor.IsHubCluster,
-		CASecretName:     cr.Spec.MonitoringSecret,
-		ClientSecretName: cr.Spec.MonitoringClientSecret,
-		NodeExporter:     true,
-		ClusterDomain:    clusterDomain,
-	}
-	if err := scrapeTargetsTemplate.Execute(&tplBuffer, paras); err != nil {
-		return nil, err
-	}
-	secret.Labels = PrometheusLabels(cr)
-	secret.Data = map[string][]byte{scrapeTargetsFileName(): tplBuffer.Bytes()} //[]byte(tplBuffer.String())}
-	return secret, nil
-}
-
 //NewPrometheusRules create default PrometheusRule objects
 func NewPrometheusRules(cr *promext.PrometheusExt) []*promv1.PrometheusRule {
 	return []*promv1.PrometheusRule{nil}
@@ -424,6 +339,18 @@ type scrapeTargetConfigParas struct {
 	ClientSecretName string
 	NodeExporter     bool
 	ClusterDomain    string
+	//metrics service of operator, it is scraped only if it is set
+	OperatorMetricsService string
+	OperatorMetricsPort    string
+}
+
+//operatorMetricsServiceName returns name of metrics service which operator-sdk creates for operator
+func operatorMetricsServiceName() string {
+	name := os.Getenv(operatorNameEnv)
+	if name == "" {
+		name = defaultOperatorName
+	}
+	return name + "-metrics"
 }
 
 var (
@@ -38,9 +38,10 @@ func PrometheusOperatorName(cr *promext.PrometheusExt) string {
 func NewProOperatorDeployment(cr *promext.PrometheusExt) *appsv1.Deployment {
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      PrometheusOperatorName(cr),
-			Namespace: cr.Namespace,
-			Labels:    proOperatorLabels(cr),
+			Name:        PrometheusOperatorName(cr),
+			Namespace:   cr.Namespace,
+			Labels:      proOperatorLabels(cr),
+			Annotations: commonPodAnnotations(),
 		},
 		Spec: *promeDeploymentSpec(cr),
 	}
@@ -64,10 +65,10 @@ func promeDeploymentSpec(cr *promext.PrometheusExt) *appsv1.DeploymentSpec {
 				HostIPC:            false,
 				HostNetwork:        false,
 				ServiceAccountName: "prometheus-operator",
-				NodeSelector:       cr.Spec.NodeSelector,
 			},
 		},
 	}
+	applyScheduling(cr, &spec.Template.Spec, cr.Spec.PrometheusOperator.Scheduling)
 
 	if cr.Spec.ImagePullSecrets != nil && len(cr.Spec.ImagePullSecrets) != 0 {
 		var secrets []v1.LocalObjectReference
@@ -144,24 +145,6 @@ func prometneusOperatorContainer(cr *promext.PrometheusExt) *v1.Container {
 
 }
 
-//UpdatedProOperatorDeployment create new deployment for prometheus operator
-func UpdatedProOperatorDeployment(cr *promext.PrometheusExt, curr *appsv1.Deployment) *appsv1.Deployment {
-	deployment := curr.DeepCopy()
-	deployment.ObjectMeta.Labels = proOperatorLabels(cr)
-	ann := commonPodAnnotations()
-	for k, v := range ann {
-		deployment.ObjectMeta.Annotations[k] = v
-
-	}
-	spec := promeDeploymentSpec(cr)
-	deployment.Spec.Template.ObjectMeta.Labels = spec.Template.ObjectMeta.Labels
-	deployment.Spec.Template.ObjectMeta.Annotations = spec.Template.ObjectMeta.Annotations
-	deployment.Spec.Template.Spec.Containers = spec.Template.Spec.Containers
-	deployment.Spec.Template.Spec.NodeSelector = cr.Spec.NodeSelector
-
-	return deployment
-}
-
 func proOperatorLabels(cr *promext.PrometheusExt) map[string]string {
 	labels := make(map[string]string)
 	labels[AppLabelKey] = AppLabelValue
@@ -18,28 +18,18 @@ package model
 
 import (
 	"bytes"
-	"html/template"
+	"fmt"
+	"sort"
+	"text/template"
 
 	promv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	prommodel "github.com/prometheus/common/model"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	promext "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
 )
 
-//defaultPromethuesRules is a dictionary to stor default prometheus rules information
-var (
-	defaultPromethuesRules map[PrometheusRuleName]*promv1.PrometheusRule
-	nodeMemUsageExprTempl  *template.Template
-	nodeMemUsageDesTempl   *template.Template
-	highCPUExprTempl       *template.Template
-	highCPUDesTempl        *template.Template
-)
-
 const (
-	nodeMemUsageExpr = `((node_memory_MemTotal_bytes - (node_memory_MemFree_bytes + node_memory_Buffers_bytes + node_memory_Cached_bytes))/ node_memory_MemTotal_bytes) * 100 > {{.NodeMemoryUsage}}`
-	nodeMemUsageDes  = `{{ "{{ " }} $labels.instance {{ " }}" }}: Memory usage is above the {{.NodeMemoryUsage}}% threshold.  The current value is: {{ "{{ " }} $value {{ " }}" }}.`
-	highCPUExpr      = `(100 - (avg by (instance) (irate(node_cpu_seconds_total{mode="idle"}[5m])) * 100)) > {{.HighCPUUsage}}`
-	highCPUDes       = `{{ "{{ " }} $labels.instance {{ " }}" }}: CPU usage is above the {{ .HighCPUUsage }}% threshold.  The current value is: {{ "{{ " }} $value {{ " }}" }}.`
 	// NodeMem is rule name for high node memory usage
 	NodeMem = PrometheusRuleName("node-memory-usage")
 	//NodeCPU is rule name for high for cpu usage
@@ -49,178 +39,356 @@ const (
 	//PodRestart is rule name for pods restarted
 	PodRestart = PrometheusRuleName("pods-restarting")
 	//FailedJob is rule name for failed jobs
-	FailedJob   = PrometheusRuleName("failed-jobs")
-	summary     = "summary"
-	description = "description"
+	FailedJob = PrometheusRuleName("failed-jobs")
+	//PrometheusHealth is rule name for prometheus health rule pack
+	PrometheusHealth = PrometheusRuleName("prometheus-health")
+	//AlertmanagerHealth is rule name for alertmanager health rule pack
+	AlertmanagerHealth = PrometheusRuleName("alertmanager-health")
+	//DiskUsage is rule name for persistent volumes filling up
+	DiskUsage = PrometheusRuleName("disk-usage")
+	//CertificateExpiry is rule name for expiry of monitoring tls certificates
+	CertificateExpiry = PrometheusRuleName("certificate-expiry")
+	//APIServerErrors is rule name for kube-apiserver error rates
+	APIServerErrors = PrometheusRuleName("apiserver-errors")
+	//Watchdog is rule name for the alert which always fires
+	Watchdog = PrometheusRuleName("watchdog")
+	//DefaultRuleLabelKey is label key of PrometheusRule objects created from default rules
+	DefaultRuleLabelKey = "monitoring.operator.ibm.com/default-rule"
+	//CertExpiryMetric is name of metric exported by operator for expiry of tls certificates
+	CertExpiryMetric = "ibm_monitoring_certificate_expiration_timestamp_seconds"
+	summary          = "summary"
+	description      = "description"
+	runbookURL       = "runbook_url"
+	severityLabel    = "severity"
 )
 
-type nodeMemRulePara struct {
-	NodeMemoryUsage int
+//PrometheusRuleName defines prometheus rule names
+type PrometheusRuleName string
+
+//defaultRule is definition of a default prometheus rule or a rule pack with several alerts
+type defaultRule struct {
+	group  string
+	alerts []defaultAlert
+	//threshold returns default threshold of the rule, nil if rule has no threshold
+	threshold func(cr *promext.PrometheusExt) int
+	//optional rules are created only when they are enabled in CR
+	optional bool
 }
-type nodeCPURulePara struct {
-	HighCPUUsage int
+
+//defaultAlert is definition of an alert in default rule
+//expr and annotations are templates which get rule threshold as .Threshold and CR namespace as .Namespace
+type defaultAlert struct {
+	alert       string
+	expr        string
+	forDuration string
+	severity    string
+	summary     string
+	description string
 }
 
-//PrometheusRuleName defines prometheus rule names
-type PrometheusRuleName string
+type defaultRulePara struct {
+	Threshold int
+	Namespace string
+}
 
-//DefaultPrometheusRules return dictionary for default prometheus rules
-func DefaultPrometheusRules(cr *promext.PrometheusExt) (map[PrometheusRuleName]*promv1.PrometheusRule, error) {
-	var tplBuffer bytes.Buffer
-	memPara := nodeMemRulePara{
-		NodeMemoryUsage: cr.Spec.PrometheusConfig.NodeMemoryThreshold,
-	}
-	cpuPara := nodeCPURulePara{
-		HighCPUUsage: cr.Spec.PrometheusConfig.NodeCPUThreshold,
-	}
-	//node memory usage
-	if err := nodeMemUsageExprTempl.Execute(&tplBuffer, memPara); err != nil {
-		return nil, err
-	}
-	defaultPromethuesRules[NodeMem].Spec.Groups[0].Rules[0].Expr.StrVal = tplBuffer.String()
-	tplBuffer.Reset()
+//defaultPrometheusRules is a dictionary to store default prometheus rules information
+var defaultPrometheusRules = map[PrometheusRuleName]defaultRule{
+	NodeMem: {
+		group: "NodeMemoryUsage",
+		alerts: []defaultAlert{{
+			alert:       "NodeMemoryUsage",
+			expr:        `((node_memory_MemTotal_bytes - (node_memory_MemFree_bytes + node_memory_Buffers_bytes + node_memory_Cached_bytes))/ node_memory_MemTotal_bytes) * 100 > {{ .Threshold }}`,
+			forDuration: "5m",
+			summary:     `{{ "{{ " }}$labels.instance{{ " }}" }}: High memory usage detected`,
+			description: `{{ "{{ " }} $labels.instance {{ " }}" }}: Memory usage is above the {{ .Threshold }}% threshold.  The current value is: {{ "{{ " }} $value {{ " }}" }}.`,
+		}},
+		threshold: func(cr *promext.PrometheusExt) int { return cr.Spec.PrometheusConfig.NodeMemoryThreshold },
+	},
+	NodeCPU: {
+		group: "HighCPUUsage",
+		alerts: []defaultAlert{{
+			alert:       "HighCPUUsage",
+			expr:        `(100 - (avg by (instance) (irate(node_cpu_seconds_total{mode="idle"}[5m])) * 100)) > {{ .Threshold }}`,
+			forDuration: "5m",
+			summary:     "High CPU Usage",
+			description: `{{ "{{ " }} $labels.instance {{ " }}" }}: CPU usage is above the {{ .Threshold }}% threshold.  The current value is: {{ "{{ " }} $value {{ " }}" }}.`,
+		}},
+		threshold: func(cr *promext.PrometheusExt) int { return cr.Spec.PrometheusConfig.NodeCPUThreshold },
+	},
+	PodTerm: {
+		group: "podsTerminated",
+		alerts: []defaultAlert{{
+			alert:       "podsTerminated",
+			expr:        `sum_over_time(kube_pod_container_status_terminated_reason{reason!="Completed"}[1h]) > 0`,
+			summary:     "Pod was terminated",
+			description: `Pod {{ "{{ " }} $labels.pod {{ " }}" }} in namespace {{ "{{ " }} $labels.namespace {{ " }}" }} has a termination status other than completed.`,
+		}},
+	},
+	PodRestart: {
+		group: "podsRestarting",
+		alerts: []defaultAlert{{
+			alert:       "podsRestarting",
+			expr:        `increase(kube_pod_container_status_restarts_total[1h]) > {{ .Threshold }}`,
+			summary:     "Pod restarting a lot",
+			description: `Pod {{ "{{ " }} $labels.pod {{ " }}" }} in namespace {{ "{{ " }} $labels.namespace {{ " }}" }} is restarting a lot`,
+		}},
+		threshold: func(cr *promext.PrometheusExt) int { return 5 },
+	},
+	FailedJob: {
+		group: "failedJobs",
+		alerts: []defaultAlert{{
+			alert:       "failedJobs",
+			expr:        "kube_job_failed != 0",
+			summary:     "Failed job",
+			description: `Job {{ "{{ " }} $labels.exported_job {{ " }}" }} in namespace {{ "{{ " }} $labels.namespace {{ " }}" }} failed for some reason.`,
+		}},
+	},
+	PrometheusHealth: {
+		group: "PrometheusHealth",
+		alerts: []defaultAlert{
+			{
+				alert:       "PrometheusConfigReloadFailed",
+				expr:        "prometheus_config_last_reload_successful == 0",
+				forDuration: "10m",
+				summary:     "Prometheus configuration reload failed",
+				description: `Reloading configuration of Prometheus {{ "{{ " }} $labels.instance {{ " }}" }} has failed.`,
+			},
+			{
+				alert:       "PrometheusNotificationQueueFull",
+				expr:        "prometheus_notifications_queue_length >= prometheus_notifications_queue_capacity",
+				forDuration: "15m",
+				summary:     "Prometheus alert notification queue is full",
+				description: `Alert notification queue of Prometheus {{ "{{ " }} $labels.instance {{ " }}" }} is full.`,
+			},
+			{
+				alert:       "PrometheusTSDBCompactionsFailing",
+				expr:        "increase(prometheus_tsdb_compactions_failed_total[3h]) > 0",
+				forDuration: "15m",
+				summary:     "Prometheus TSDB compactions are failing",
+				description: `Prometheus {{ "{{ " }} $labels.instance {{ " }}" }} has {{ "{{ " }} $value {{ " }}" }} compaction failures over the last 3 hours.`,
+			},
+			{
+				alert:       "TargetDown",
+				expr:        "up == 0",
+				forDuration: "15m",
+				summary:     "Scrape target is down",
+				description: `Target {{ "{{ " }} $labels.instance {{ " }}" }} of job {{ "{{ " }} $labels.job {{ " }}" }} is down.`,
+			},
+		},
+		optional: true,
+	},
+	AlertmanagerHealth: {
+		group: "AlertmanagerHealth",
+		alerts: []defaultAlert{
+			{
+				alert:       "AlertmanagerFailedNotifications",
+				expr:        "increase(alertmanager_notifications_failed_total[10m]) > 0",
+				forDuration: "5m",
+				summary:     "Alertmanager failed to send notifications",
+				description: `Alertmanager {{ "{{ " }} $labels.instance {{ " }}" }} failed to send notifications to {{ "{{ " }} $labels.integration {{ " }}" }}.`,
+			},
+			{
+				alert:       "AlertmanagerClusterPeerMismatch",
+				expr:        "alertmanager_cluster_members != on (job) group_left() count by (job) (alertmanager_cluster_members)",
+				forDuration: "5m",
+				severity:    "critical",
+				summary:     "Alertmanager cluster members are inconsistent",
+				description: `Alertmanager {{ "{{ " }} $labels.instance {{ " }}" }} has not found all other members of the cluster.`,
+			},
+		},
+		optional: true,
+	},
+	DiskUsage: {
+		group: "PersistentVolumeUsage",
+		alerts: []defaultAlert{{
+			alert:       "PersistentVolumeFillingUp",
+			expr:        "kubelet_volume_stats_available_bytes / kubelet_volume_stats_capacity_bytes < 0.15 and predict_linear(kubelet_volume_stats_available_bytes[6h], {{ .Threshold }} * 24 * 3600) < 0",
+			forDuration: "1h",
+			summary:     "Persistent volume is filling up",
+			description: `PersistentVolumeClaim {{ "{{ " }} $labels.persistentvolumeclaim {{ " }}" }} in namespace {{ "{{ " }} $labels.namespace {{ " }}" }} is expected to fill up within {{ .Threshold }} days.`,
+		}},
+		//days
+		threshold: func(cr *promext.PrometheusExt) int { return 4 },
+		optional:  true,
+	},
+	CertificateExpiry: {
+		group: "CertificateExpiry",
+		alerts: []defaultAlert{{
+			alert:       "MonitoringCertificateExpiring",
+			expr:        CertExpiryMetric + `{secret_namespace="{{ .Namespace }}"} - time() < {{ .Threshold }} * 24 * 3600`,
+			forDuration: "1h",
+			summary:     "Monitoring tls certificate is expiring",
+			description: `Certificate in secret {{ "{{ " }} $labels.secret {{ " }}" }} expires within {{ .Threshold }} days.`,
+		}},
+		//days
+		threshold: func(cr *promext.PrometheusExt) int { return 14 },
+		optional:  true,
+	},
+	APIServerErrors: {
+		group: "KubeAPIServerErrors",
+		alerts: []defaultAlert{{
+			alert:       "KubeAPIErrorsHigh",
+			expr:        `sum(rate(apiserver_request_total{code=~"5.."}[5m])) / sum(rate(apiserver_request_total[5m])) * 100 > {{ .Threshold }}`,
+			forDuration: "10m",
+			severity:    "critical",
+			summary:     "kube-apiserver error rate is high",
+			description: `{{ "{{ " }} $value {{ " }}" }}% of requests to kube-apiserver failed with server errors.`,
+		}},
+		//percentage
+		threshold: func(cr *promext.PrometheusExt) int { return 5 },
+		optional:  true,
+	},
+	Watchdog: {
+		group: "Watchdog",
+		alerts: []defaultAlert{{
+			alert:       "Watchdog",
+			expr:        "vector(1)",
+			severity:    "none",
+			summary:     "Alerting pipeline is working",
+			description: "This alert always fires. If it is not received, alerting pipeline is broken.",
+		}},
+		optional: true,
+	},
+}
 
-	if err := nodeMemUsageDesTempl.Execute(&tplBuffer, memPara); err != nil {
-		return nil, err
+//DefaultPrometheusRuleNames returns names of all default prometheus rules including disabled ones
+func DefaultPrometheusRuleNames() []PrometheusRuleName {
+	names := make([]PrometheusRuleName, 0, len(defaultPrometheusRules))
+	for name := range defaultPrometheusRules {
+		names = append(names, name)
 	}
-	defaultPromethuesRules[NodeMem].Spec.Groups[0].Rules[0].Annotations[description] = tplBuffer.String()
-	tplBuffer.Reset()
-	//node cpu usage
-	if err := highCPUExprTempl.Execute(&tplBuffer, cpuPara); err != nil {
-		return nil, err
-	}
-	defaultPromethuesRules[NodeCPU].Spec.Groups[0].Rules[0].Expr.StrVal = tplBuffer.String()
-	tplBuffer.Reset()
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
 
-	if err := highCPUDesTempl.Execute(&tplBuffer, cpuPara); err != nil {
-		return nil, err
+//DefaultRuleEnabled checks if default rule is enabled in CR
+func DefaultRuleEnabled(cr *promext.PrometheusExt, name PrometheusRuleName) bool {
+	custom, ok := cr.Spec.PrometheusConfig.DefaultRules[string(name)]
+	if !ok || custom.Enabled == nil {
+		return !defaultPrometheusRules[name].optional
 	}
-	defaultPromethuesRules[NodeCPU].Spec.Groups[0].Rules[0].Annotations[description] = tplBuffer.String()
-	tplBuffer.Reset()
+	return *custom.Enabled
+}
 
-	return defaultPromethuesRules, nil
+//DefaultRuleLabels returns labels of PrometheusRule objects created from default rules
+func DefaultRuleLabels(cr *promext.PrometheusExt) map[string]string {
+	labels := PrometheusLabels(cr)
+	labels[DefaultRuleLabelKey] = "true"
+	return labels
+}
 
+//ValidateDefaultRules checks customization of default rules in CR
+func ValidateDefaultRules(cr *promext.PrometheusExt) error {
+	for name, custom := range cr.Spec.PrometheusConfig.DefaultRules {
+		if _, ok := defaultPrometheusRules[PrometheusRuleName(name)]; !ok {
+			return NewPermanentError("prometheus", "defaultRules: unknown rule "+name)
+		}
+		if custom.For != "" {
+			if _, err := prommodel.ParseDuration(custom.For); err != nil {
+				return NewPermanentError("prometheus", fmt.Sprintf("defaultRules.%s.for: %v", name, err))
+			}
+		}
+	}
+	return nil
 }
 
-//create immutable rules
-func init() {
-	nodeMemUsageExprTempl = template.Must(template.New("NodeMemUsageExpr").Parse(nodeMemUsageExpr))
-	nodeMemUsageDesTempl = template.Must(template.New("NodeMemUsageDes").Parse(nodeMemUsageDes))
-	highCPUExprTempl = template.Must(template.New("HighCPUExpr").Parse(highCPUExpr))
-	highCPUDesTempl = template.Must(template.New("HighCPUDes").Parse(highCPUDes))
+//DefaultPrometheusRules return dictionary for enabled default prometheus rules
+func DefaultPrometheusRules(cr *promext.PrometheusExt) (map[PrometheusRuleName]*promv1.PrometheusRule, error) {
+	if err := ValidateDefaultRules(cr); err != nil {
+		return nil, err
+	}
+	rules := make(map[PrometheusRuleName]*promv1.PrometheusRule)
+	for name, def := range defaultPrometheusRules {
+		if !DefaultRuleEnabled(cr, name) {
+			continue
+		}
+		rule, err := newDefaultRule(cr, name, def)
+		if err != nil {
+			return nil, err
+		}
+		rules[name] = rule
+	}
+	return rules, nil
+}
 
-	defaultPromethuesRules = make(map[PrometheusRuleName]*promv1.PrometheusRule)
-	defaultPromethuesRules[NodeMem] = &promv1.PrometheusRule{
-		Spec: promv1.PrometheusRuleSpec{
-			Groups: []promv1.RuleGroup{
-				{
-					Name: "NodeMemoryUsage",
-					Rules: []promv1.Rule{
-						{
-							Alert: "NodeMemoryUsage",
-							Expr: intstr.IntOrString{
-								Type:   intstr.String,
-								StrVal: "",
-							},
-							For: "5m",
-							Annotations: map[string]string{
-								description: "",
-								summary:     `{{ "{{ " }}$labels.instance{{ " }}" }}: High memory usage detected`,
-							},
-						},
-					},
-				},
-			},
-		},
+func newDefaultRule(cr *promext.PrometheusExt, name PrometheusRuleName, def defaultRule) (*promv1.PrometheusRule, error) {
+	custom := cr.Spec.PrometheusConfig.DefaultRules[string(name)]
+	para := defaultRulePara{Namespace: cr.Namespace}
+	if def.threshold != nil {
+		para.Threshold = def.threshold(cr)
 	}
-	defaultPromethuesRules[NodeCPU] = &promv1.PrometheusRule{
+	if custom.Threshold != nil {
+		para.Threshold = int(*custom.Threshold)
+	}
+	var rules []promv1.Rule
+	for _, alert := range def.alerts {
+		rule, err := newDefaultAlert(alert, custom, para)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+	return &promv1.PrometheusRule{
 		Spec: promv1.PrometheusRuleSpec{
 			Groups: []promv1.RuleGroup{
 				{
-					Name: "HighCPUUsage",
-					Rules: []promv1.Rule{
-						{
-							Alert: "HighCPUUsage",
-							Expr: intstr.IntOrString{
-								Type:   intstr.String,
-								StrVal: "",
-							},
-							For: "5m",
-							Annotations: map[string]string{
-								description: "",
-								summary:     "High CPU Usage",
-							},
-						},
-					},
+					Name:  def.group,
+					Rules: rules,
 				},
 			},
 		},
+	}, nil
+}
+
+func newDefaultAlert(alert defaultAlert, custom promext.DefaultRule, para defaultRulePara) (*promv1.Rule, error) {
+	expr, err := renderRuleTemplate(alert.alert+"-expr", alert.expr, para)
+	if err != nil {
+		return nil, err
 	}
-	defaultPromethuesRules[PodTerm] = &promv1.PrometheusRule{
-		Spec: promv1.PrometheusRuleSpec{
-			Groups: []promv1.RuleGroup{
-				{
-					Name: "podsTerminated",
-					Rules: []promv1.Rule{
-						{
-							Alert: "podsTerminated",
-							Expr: intstr.IntOrString{
-								Type:   intstr.String,
-								StrVal: `sum_over_time(kube_pod_container_status_terminated_reason{reason!="Completed"}[1h]) > 0`,
-							},
-							Annotations: map[string]string{
-								description: `Pod {{ "{{ " }} $labels.pod {{ " }}" }} in namespace {{ "{{ " }} $labels.namespace {{ " }}" }} has a termination status other than completed.`,
-								summary:     `Pod was terminated`,
-							},
-						},
-					},
-				},
-			},
+	desc, err := renderRuleTemplate(alert.alert+"-description", alert.description, para)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := renderRuleTemplate(alert.alert+"-summary", alert.summary, para)
+	if err != nil {
+		return nil, err
+	}
+	rule := &promv1.Rule{
+		Alert: alert.alert,
+		Expr: intstr.IntOrString{
+			Type:   intstr.String,
+			StrVal: expr,
 		},
+		For: alert.forDuration,
+		Annotations: map[string]string{
+			description: desc,
+			summary:     sum,
+		},
+	}
+	severity := alert.severity
+	if custom.Severity != "" {
+		severity = custom.Severity
+	}
+	if severity != "" {
+		rule.Labels = map[string]string{severityLabel: severity}
+	}
+	if custom.For != "" {
+		rule.For = custom.For
+	}
+	if custom.RunbookURL != "" {
+		rule.Annotations[runbookURL] = custom.RunbookURL
 	}
+	return rule, nil
+}
 
-	defaultPromethuesRules[PodRestart] = &promv1.PrometheusRule{
-		Spec: promv1.PrometheusRuleSpec{
-			Groups: []promv1.RuleGroup{
-				{
-					Name: "podsRestarting",
-					Rules: []promv1.Rule{
-						{
-							Alert: "podsRestarting",
-							Expr: intstr.IntOrString{
-								Type:   intstr.String,
-								StrVal: `increase(kube_pod_container_status_restarts_total[1h]) > 5`,
-							},
-							Annotations: map[string]string{
-								description: `Pod {{ "{{ " }} $labels.pod {{ " }}" }} in namespace {{ "{{ " }} $labels.namespace {{ " }}" }} is restarting a lot`,
-								summary:     `Pod restarting a lot`,
-							},
-						},
-					},
-				},
-			},
-		},
+func renderRuleTemplate(name, text string, para interface{}) (string, error) {
+	tpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", err
 	}
-	defaultPromethuesRules[FailedJob] = &promv1.PrometheusRule{
-		Spec: promv1.PrometheusRuleSpec{
-			Groups: []promv1.RuleGroup{
-				{
-					Name: "failedJobs",
-					Rules: []promv1.Rule{
-						{
-							Alert: "failedJobs",
-							Expr: intstr.IntOrString{
-								Type:   intstr.String,
-								StrVal: "kube_job_failed != 0",
-							},
-							Annotations: map[string]string{
-								description: `Job {{ "{{ " }} $labels.exported_job {{ " }}" }} in namespace {{ "{{ " }} $labels.namespace {{ " }}" }} failed for some reason.`,
-								summary:     "Failed job",
-							},
-						},
-					},
-				},
-			},
-		},
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, para); err != nil {
+		return "", err
 	}
+	return buf.String(), nil
 }
@@ -0,0 +1,50 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package model
+
+import (
+	"testing"
+
+	promext "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+)
+
+func TestNewDefaultAlertSeverity(t *testing.T) {
+	tests := []struct {
+		name         string
+		alert        defaultAlert
+		custom       promext.DefaultRule
+		wantSeverity string
+		wantLabel    bool
+	}{
+		{name: "no severity", alert: defaultAlert{alert: "NoSeverity", expr: "up == 0"}, wantLabel: false},
+		{name: "severity of alert", alert: defaultAlert{alert: "Critical", expr: "up == 0", severity: "critical"}, wantSeverity: "critical", wantLabel: true},
+		{name: "severity of custom rule", alert: defaultAlert{alert: "NoSeverity", expr: "up == 0"}, custom: promext.DefaultRule{Severity: "warning"}, wantSeverity: "warning", wantLabel: true},
+		{name: "custom rule overrides alert", alert: defaultAlert{alert: "Critical", expr: "up == 0", severity: "critical"}, custom: promext.DefaultRule{Severity: "info"}, wantSeverity: "info", wantLabel: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := newDefaultAlert(tt.alert, tt.custom, defaultRulePara{})
+			if err != nil {
+				t.Fatalf("newDefaultAlert() error = %v", err)
+			}
+			severity, ok := rule.Labels[severityLabel]
+			if ok != tt.wantLabel || severity != tt.wantSeverity {
+				t.Errorf("severity label = %q (set %v), want %q (set %v)", severity, ok, tt.wantSeverity, tt.wantLabel)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	promext "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+)
+
+func TestScrapeTargetsOperatorMetrics(t *testing.T) {
+	enabled, disabled := true, false
+	tests := []struct {
+		name    string
+		rules   map[string]promext.DefaultRule
+		wantJob bool
+	}{
+		{name: "certificate-expiry is not enabled", wantJob: false},
+		{name: "certificate-expiry is enabled", rules: map[string]promext.DefaultRule{string(CertificateExpiry): {Enabled: &enabled}}, wantJob: true},
+		{name: "certificate-expiry is disabled", rules: map[string]promext.DefaultRule{string(CertificateExpiry): {Enabled: &disabled}}, wantJob: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &promext.PrometheusExt{ObjectMeta: metav1.ObjectMeta{Name: "monitoring", Namespace: "monitoring"}}
+			cr.Spec.PrometheusConfig.DefaultRules = tt.rules
+			secret, err := NewScrapeTargetsSecret(cr)
+			if err != nil {
+				t.Fatalf("NewScrapeTargetsSecret() error = %v", err)
+			}
+			config := string(secret.Data[scrapeTargetsFileName()])
+			job := strings.Contains(config, "job_name: 'ibm-monitoring-operator'") &&
+				strings.Contains(config, "regex: "+defaultOperatorName+"-metrics;"+operatorMetricsPortName)
+			if job != tt.wantJob {
+				t.Errorf("operator metrics job in scrape targets = %v, want %v", job, tt.wantJob)
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	promv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	promext "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+)
+
+//prometheusSecretsDir is where prometheus operator mounts secrets listed in prometheus spec
+const prometheusSecretsDir = "/etc/prometheus/secrets/"
+
+//RemoteWriteMaxLagSeconds is how far newest sent sample can be behind newest ingested sample before endpoint is unhealthy
+const RemoteWriteMaxLagSeconds = 300
+
+//Queries of prometheus_remote_storage_* metrics which prometheus scrapes from itself.
+//Series are grouped by url label of prometheus 2.15+ and by queue label of older versions
+const (
+	RemoteWriteSucceededQuery = `sum by (queue, url) (rate(prometheus_remote_storage_succeeded_samples_total{job="prometheus"}[5m]))`
+	RemoteWriteFailedQuery    = `sum by (queue, url) (rate(prometheus_remote_storage_failed_samples_total{job="prometheus"}[5m]))`
+	RemoteWriteLagQuery       = `scalar(max(prometheus_remote_storage_highest_timestamp_in_seconds{job="prometheus"})) - max by (queue, url) (prometheus_remote_storage_queue_highest_sent_timestamp_seconds{job="prometheus"})`
+)
+
+//RemoteStorageSecrets returns names of secrets referred by remote write and remote read endpoints
+//and by Thanos object storage of CR. Prometheus pods can not start until all of them exist
+func RemoteStorageSecrets(cr *promext.PrometheusExt) []string {
+	return sortedNames(append(remoteEndpointSecrets(cr), ThanosSecrets(cr)...))
+}
+
+//remoteEndpointSecrets returns names of secrets referred by remote write and remote read endpoints
+func remoteEndpointSecrets(cr *promext.PrometheusExt) []string {
+	var selectors []*v1.SecretKeySelector
+	for _, rw := range cr.Spec.PrometheusConfig.RemoteWrite {
+		selectors = append(selectors, remoteSelectors(rw.BasicAuth, rw.BearerToken, rw.TLSConfig)...)
+	}
+	for _, rr := range cr.Spec.PrometheusConfig.RemoteRead {
+		selectors = append(selectors, remoteSelectors(rr.BasicAuth, rr.BearerToken, rr.TLSConfig)...)
+	}
+	var names []string
+	for _, selector := range selectors {
+		names = append(names, selector.Name)
+	}
+	return sortedNames(names)
+}
+
+//sortedNames returns sorted non-empty names without duplicates
+func sortedNames(names []string) []string {
+	unique := make(map[string]bool)
+	for _, name := range names {
+		if name != "" {
+			unique[name] = true
+		}
+	}
+	var sorted []string
+	for name := range unique {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+//remoteSelectors returns secret keys referred by a remote endpoint
+func remoteSelectors(basicAuth *promext.RemoteBasicAuth, bearerToken *v1.SecretKeySelector, tls *promext.RemoteTLSConfig) []*v1.SecretKeySelector {
+	var selectors []*v1.SecretKeySelector
+	if basicAuth != nil {
+		selectors = append(selectors, &basicAuth.Username, &basicAuth.Password)
+	}
+	if bearerToken != nil {
+		selectors = append(selectors, bearerToken)
+	}
+	if tls != nil {
+		for _, selector := range []*v1.SecretKeySelector{tls.CA, tls.Cert, tls.Key} {
+			if selector != nil {
+				selectors = append(selectors, selector)
+			}
+		}
+	}
+	return selectors
+}
+
+//prometheusSecrets returns secrets mounted into prometheus pods: tls secrets and secrets of remote endpoints.
+//Thanos object storage configuration is passed to sidecar in environment variable by prometheus operator
+func prometheusSecrets(cr *promext.PrometheusExt) []string {
+	secrets := []string{cr.Spec.Certs.MonitoringSecret, cr.Spec.Certs.MonitoringClientSecret}
+	for _, name := range remoteEndpointSecrets(cr) {
+		if name != cr.Spec.Certs.MonitoringSecret && name != cr.Spec.Certs.MonitoringClientSecret {
+			secrets = append(secrets, name)
+		}
+	}
+	return secrets
+}
+
+//secretFile returns path of secret key mounted into prometheus pods
+func secretFile(selector *v1.SecretKeySelector) string {
+	if selector == nil {
+		return ""
+	}
+	return prometheusSecretsDir + selector.Name + "/" + selector.Key
+}
+
+//checkRemoteStorage returns permanent error if a remote endpoint or Thanos object storage can not be mapped to prometheus spec
+func checkRemoteStorage(cr *promext.PrometheusExt) error {
+	check := func(kind string, endpoint string, selectors []*v1.SecretKeySelector) error {
+		u, err := url.Parse(endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return NewPermanentError("prometheus", fmt.Sprintf("invalid url of %s endpoint: %s", kind, endpoint))
+		}
+		for _, selector := range selectors {
+			if selector.Name == "" || selector.Key == "" {
+				return NewPermanentError("prometheus", fmt.Sprintf("secret name and key are required by %s endpoint %s", kind, endpoint))
+			}
+		}
+		return nil
+	}
+	for _, rw := range cr.Spec.PrometheusConfig.RemoteWrite {
+		if err := check("remote write", rw.URL, remoteSelectors(rw.BasicAuth, rw.BearerToken, rw.TLSConfig)); err != nil {
+			return err
+		}
+	}
+	for _, rr := range cr.Spec.PrometheusConfig.RemoteRead {
+		if err := check("remote read", rr.URL, remoteSelectors(rr.BasicAuth, rr.BearerToken, rr.TLSConfig)); err != nil {
+			return err
+		}
+	}
+	if thanos := cr.Spec.PrometheusConfig.Thanos; thanos != nil && thanos.ObjectStorageConfig != nil {
+		if thanos.ObjectStorageConfig.Name == "" || thanos.ObjectStorageConfig.Key == "" {
+			return NewPermanentError("prometheus", "secret name and key are required by thanos object storage config")
+		}
+	}
+	return nil
+}
+
+//remoteBasicAuth maps basic auth of a remote endpoint. Prometheus operator reads the secrets and puts credentials into prometheus configuration
+func remoteBasicAuth(basicAuth *promext.RemoteBasicAuth) *promv1.BasicAuth {
+	if basicAuth == nil {
+		return nil
+	}
+	return &promv1.BasicAuth{Username: basicAuth.Username, Password: basicAuth.Password}
+}
+
+//remoteTLSConfig maps tls configuration of a remote endpoint to files of mounted secrets.
+//Prometheus operator does not support secret references in tls configuration of remote endpoints
+func remoteTLSConfig(tls *promext.RemoteTLSConfig) *promv1.TLSConfig {
+	if tls == nil {
+		return nil
+	}
+	return &promv1.TLSConfig{
+		CAFile:             secretFile(tls.CA),
+		CertFile:           secretFile(tls.Cert),
+		KeyFile:            secretFile(tls.Key),
+		ServerName:         tls.ServerName,
+		InsecureSkipVerify: tls.InsecureSkipVerify,
+	}
+}
+
+//remoteWriteSpecs maps remote write endpoints of CR to prometheus spec
+func remoteWriteSpecs(cr *promext.PrometheusExt) []promv1.RemoteWriteSpec {
+	var specs []promv1.RemoteWriteSpec
+	for _, rw := range cr.Spec.PrometheusConfig.RemoteWrite {
+		spec := promv1.RemoteWriteSpec{
+			URL:             rw.URL,
+			RemoteTimeout:   rw.RemoteTimeout,
+			BasicAuth:       remoteBasicAuth(rw.BasicAuth),
+			BearerTokenFile: secretFile(rw.BearerToken),
+			TLSConfig:       remoteTLSConfig(rw.TLSConfig),
+			ProxyURL:        rw.ProxyURL,
+		}
+		for _, c := range rw.WriteRelabelConfigs {
+			spec.WriteRelabelConfigs = append(spec.WriteRelabelConfigs, promv1.RelabelConfig{
+				SourceLabels: c.SourceLabels,
+				Separator:    c.Separator,
+				TargetLabel:  c.TargetLabel,
+				Regex:        c.Regex,
+				Modulus:      c.Modulus,
+				Replacement:  c.Replacement,
+				Action:       c.Action,
+			})
+		}
+		if q := rw.QueueConfig; q != nil {
+			spec.QueueConfig = &promv1.QueueConfig{
+				Capacity:          q.Capacity,
+				MinShards:         q.MinShards,
+				MaxShards:         q.MaxShards,
+				MaxSamplesPerSend: q.MaxSamplesPerSend,
+				BatchSendDeadline: q.BatchSendDeadline,
+				MinBackoff:        q.MinBackoff,
+				MaxBackoff:        q.MaxBackoff,
+			}
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+//remoteReadSpecs maps remote read endpoints of CR to prometheus spec
+func remoteReadSpecs(cr *promext.PrometheusExt) []promv1.RemoteReadSpec {
+	var specs []promv1.RemoteReadSpec
+	for _, rr := range cr.Spec.PrometheusConfig.RemoteRead {
+		specs = append(specs, promv1.RemoteReadSpec{
+			URL:              rr.URL,
+			RequiredMatchers: rr.RequiredMatchers,
+			RemoteTimeout:    rr.RemoteTimeout,
+			ReadRecent:       rr.ReadRecent,
+			BasicAuth:        remoteBasicAuth(rr.BasicAuth),
+			BearerTokenFile:  secretFile(rr.BearerToken),
+			TLSConfig:        remoteTLSConfig(rr.TLSConfig),
+			ProxyURL:         rr.ProxyURL,
+		})
+	}
+	return specs
+}
+
+//PrometheusAPIURL returns url of Prometheus HTTP API. Every replica sends samples by itself, so the first one is used
+func PrometheusAPIURL(cr *promext.PrometheusExt) string {
+	return fmt.Sprintf("http://%s-0.prometheus-operated.%s.svc:9090/prometheus/api/v1", PrometheusStatefulSetName(cr), cr.Namespace)
+}
+
+//RemoteWriteSeriesURL returns url of remote write endpoint which a prometheus_remote_storage_* series belongs to.
+//Prometheus before 2.15 has queue label like 0:https://example.com/write instead of url label
+func RemoteWriteSeriesURL(labels map[string]string) string {
+	if u, ok := labels["url"]; ok {
+		return u
+	}
+	queue := labels["queue"]
+	if i := strings.Index(queue, ":"); i >= 0 && !strings.HasPrefix(queue[i:], "://") {
+		return queue[i+1:]
+	}
+	return queue
+}
+
+//NewRemoteWriteStatus returns health of remote write endpoint from results of remote write queries keyed by endpoint url
+func NewRemoteWriteStatus(endpoint string, succeeded, failed, lag map[string]float64, now metav1.Time) promext.RemoteWriteStatus {
+	status := promext.RemoteWriteStatus{URL: endpoint, Healthy: v1.ConditionUnknown, LastProbeTime: now}
+	succeededRate, sent := succeeded[endpoint]
+	failedRate, failing := failed[endpoint]
+	if !sent && !failing {
+		status.Message = "no prometheus_remote_storage metrics of the endpoint yet"
+		return status
+	}
+	status.SucceededSamplesRate = fmt.Sprintf("%.2f", succeededRate)
+	status.FailedSamplesRate = fmt.Sprintf("%.2f", failedRate)
+	if seconds, ok := lag[endpoint]; ok {
+		lagSeconds := int64(seconds)
+		status.LagSeconds = &lagSeconds
+	}
+	switch {
+	case failedRate > 0:
+		status.Healthy = v1.ConditionFalse
+		status.Message = fmt.Sprintf("%.2f samples per second failed to be sent", failedRate)
+	case status.LagSeconds != nil && *status.LagSeconds > RemoteWriteMaxLagSeconds:
+		status.Healthy = v1.ConditionFalse
+		status.Message = fmt.Sprintf("sent samples are %d seconds behind ingested samples", *status.LagSeconds)
+	default:
+		status.Healthy = v1.ConditionTrue
+		status.Message = "samples are sent without failures"
+	}
+	return status
+}
@@ -0,0 +1,120 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	promext "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+)
+
+func TestRemoteWriteSeriesURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   string
+	}{
+		{name: "url label", labels: map[string]string{"url": "https://a.example.com/write", "queue": "0:https://b.example.com/write"}, want: "https://a.example.com/write"},
+		{name: "queue with index", labels: map[string]string{"queue": "0:https://a.example.com/write"}, want: "https://a.example.com/write"},
+		{name: "queue without index", labels: map[string]string{"queue": "https://a.example.com/write"}, want: "https://a.example.com/write"},
+		{name: "no labels", labels: map[string]string{}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RemoteWriteSeriesURL(tt.labels); got != tt.want {
+				t.Errorf("RemoteWriteSeriesURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRemoteWriteStatus(t *testing.T) {
+	const endpoint = "https://a.example.com/write"
+	now := metav1.Now()
+	lag := func(seconds int64) *int64 { return &seconds }
+	tests := []struct {
+		name      string
+		succeeded map[string]float64
+		failed    map[string]float64
+		lag       map[string]float64
+		want      promext.RemoteWriteStatus
+	}{
+		{
+			name: "no metrics",
+			want: promext.RemoteWriteStatus{URL: endpoint, Healthy: v1.ConditionUnknown, LastProbeTime: now,
+				Message: "no prometheus_remote_storage metrics of the endpoint yet"},
+		},
+		{
+			name:      "metrics of other endpoint",
+			succeeded: map[string]float64{"https://b.example.com/write": 10},
+			want: promext.RemoteWriteStatus{URL: endpoint, Healthy: v1.ConditionUnknown, LastProbeTime: now,
+				Message: "no prometheus_remote_storage metrics of the endpoint yet"},
+		},
+		{
+			name:      "healthy",
+			succeeded: map[string]float64{endpoint: 12.345},
+			failed:    map[string]float64{endpoint: 0},
+			lag:       map[string]float64{endpoint: 3.7},
+			want: promext.RemoteWriteStatus{URL: endpoint, Healthy: v1.ConditionTrue, LastProbeTime: now,
+				SucceededSamplesRate: "12.35", FailedSamplesRate: "0.00", LagSeconds: lag(3),
+				Message: "samples are sent without failures"},
+		},
+		{
+			name:      "failed samples",
+			succeeded: map[string]float64{endpoint: 10},
+			failed:    map[string]float64{endpoint: 0.5},
+			want: promext.RemoteWriteStatus{URL: endpoint, Healthy: v1.ConditionFalse, LastProbeTime: now,
+				SucceededSamplesRate: "10.00", FailedSamplesRate: "0.50",
+				Message: "0.50 samples per second failed to be sent"},
+		},
+		{
+			name:   "only failed samples",
+			failed: map[string]float64{endpoint: 2},
+			want: promext.RemoteWriteStatus{URL: endpoint, Healthy: v1.ConditionFalse, LastProbeTime: now,
+				SucceededSamplesRate: "0.00", FailedSamplesRate: "2.00",
+				Message: "2.00 samples per second failed to be sent"},
+		},
+		{
+			name:      "lag too long",
+			succeeded: map[string]float64{endpoint: 10},
+			lag:       map[string]float64{endpoint: RemoteWriteMaxLagSeconds + 1},
+			want: promext.RemoteWriteStatus{URL: endpoint, Healthy: v1.ConditionFalse, LastProbeTime: now,
+				SucceededSamplesRate: "10.00", FailedSamplesRate: "0.00", LagSeconds: lag(RemoteWriteMaxLagSeconds + 1),
+				Message: "sent samples are 301 seconds behind ingested samples"},
+		},
+		{
+			name:      "lag at limit",
+			succeeded: map[string]float64{endpoint: 10},
+			lag:       map[string]float64{endpoint: RemoteWriteMaxLagSeconds},
+			want: promext.RemoteWriteStatus{URL: endpoint, Healthy: v1.ConditionTrue, LastProbeTime: now,
+				SucceededSamplesRate: "10.00", FailedSamplesRate: "0.00", LagSeconds: lag(RemoteWriteMaxLagSeconds),
+				Message: "samples are sent without failures"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewRemoteWriteStatus(endpoint, tt.succeeded, tt.failed, tt.lag, now)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewRemoteWriteStatus() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
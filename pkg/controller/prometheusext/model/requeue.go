@@ -0,0 +1,169 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package model
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+//RequeueCategory tells why reconciliation is requeued. Every category has its own backoff policy
+type RequeueCategory string
+
+const (
+	//RequeueWaitingForDependency means reconciliation waits for an object created by others, like a certificate secret
+	RequeueWaitingForDependency RequeueCategory = "WaitingForDependency"
+	//RequeueConflict means an object was changed by others while it was being updated
+	RequeueConflict RequeueCategory = "Conflict"
+	//RequeueTransient means a temporary failure of the api server
+	RequeueTransient RequeueCategory = "Transient"
+)
+
+//backoffPolicy is exponential backoff from base to max
+type backoffPolicy struct {
+	base time.Duration
+	max  time.Duration
+}
+
+var backoffPolicies = map[RequeueCategory]backoffPolicy{
+	RequeueWaitingForDependency: {base: 5 * time.Second, max: 5 * time.Minute},
+	RequeueConflict:             {base: time.Second, max: 30 * time.Second},
+	RequeueTransient:            {base: 2 * time.Second, max: 2 * time.Minute},
+}
+
+//Backoff returns delay before the attempt-th requeue of the category. attempt starts from 1
+func (c RequeueCategory) Backoff(attempt int) time.Duration {
+	policy, ok := backoffPolicies[c]
+	if !ok {
+		policy = backoffPolicies[RequeueTransient]
+	}
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := float64(policy.base) * math.Pow(2, float64(attempt-1))
+	if delay > float64(policy.max) {
+		return policy.max
+	}
+	return time.Duration(delay)
+}
+
+//IReqeueError defines interface for requeueError
+type IReqeueError interface {
+	Reason() string
+	Detail() string
+	Category() RequeueCategory
+}
+type requeueError struct {
+	category  RequeueCategory
+	component string
+	reason    string
+	detail    string
+}
+
+// NewRequeueError creats requeueError error. Reason is used as label of metrics, so it must be one of a few fixed values
+func NewRequeueError(category RequeueCategory, component string, reason string) error {
+	return &requeueError{category: category, component: component, reason: reason}
+
+}
+
+//NewRequeueErrorWithDetail creates requeueError error with detail, like names of objects or error of api server,
+//which is logged and shown in conditions but not used as label of metrics
+func NewRequeueErrorWithDetail(category RequeueCategory, component string, reason string, detail string) error {
+	return &requeueError{category: category, component: component, reason: reason, detail: detail}
+}
+func (r *requeueError) Error() string {
+	if r.detail != "" {
+		return "Component " + r.component + " requires to be requeued: " + r.reason + ": " + r.detail
+	}
+	return "Component " + r.component + " requires to be requeued: " + r.reason
+}
+func (r *requeueError) Reason() string {
+	return r.reason
+}
+func (r *requeueError) Detail() string {
+	return r.detail
+}
+func (r *requeueError) Category() RequeueCategory {
+	return r.category
+}
+
+//IsRequeueErr tells if error type is requeueError
+func IsRequeueErr(e error) bool {
+	switch e.(type) {
+	case IReqeueError:
+		return true
+	}
+	return false
+}
+
+//permanentError is an error which can not be fixed by retrying, like an invalid spec
+type permanentError struct {
+	component string
+	reason    string
+}
+
+//NewPermanentError creates error which stops requeueing until the CR is changed
+func NewPermanentError(component string, reason string) error {
+	return &permanentError{component, reason}
+}
+func (p *permanentError) Error() string {
+	return "Component " + p.component + " can not be reconciled until spec is changed: " + p.reason
+}
+
+//IsPermanentErr tells if error type is permanentError
+func IsPermanentErr(e error) bool {
+	_, ok := e.(*permanentError)
+	return ok
+}
+
+//RequeueBackoff counts requeues of every CR to calculate backoff of next requeue.
+//Attempts are reset when category of requeue changes or reconciliation succeeds
+type RequeueBackoff struct {
+	lock     sync.Mutex
+	attempts map[string]requeueAttempts
+}
+
+type requeueAttempts struct {
+	category RequeueCategory
+	count    int
+}
+
+//NewRequeueBackoff creates RequeueBackoff
+func NewRequeueBackoff() *RequeueBackoff {
+	return &RequeueBackoff{attempts: make(map[string]requeueAttempts)}
+}
+
+//Next counts requeue of key and returns delay before it
+func (b *RequeueBackoff) Next(key string, category RequeueCategory) time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	attempts := b.attempts[key]
+	if attempts.category != category {
+		attempts = requeueAttempts{category: category}
+	}
+	attempts.count++
+	b.attempts[key] = attempts
+	return category.Backoff(attempts.count)
+}
+
+//Forget resets requeues of key
+func (b *RequeueBackoff) Forget(key string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	delete(b.attempts, key)
+}
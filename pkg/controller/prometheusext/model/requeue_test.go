@@ -0,0 +1,110 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRequeueCategoryBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		category RequeueCategory
+		attempt  int
+		want     time.Duration
+	}{
+		{name: "first dependency wait", category: RequeueWaitingForDependency, attempt: 1, want: 5 * time.Second},
+		{name: "third dependency wait", category: RequeueWaitingForDependency, attempt: 3, want: 20 * time.Second},
+		{name: "dependency wait at max", category: RequeueWaitingForDependency, attempt: 20, want: 5 * time.Minute},
+		{name: "first conflict", category: RequeueConflict, attempt: 1, want: time.Second},
+		{name: "conflict at max", category: RequeueConflict, attempt: 6, want: 30 * time.Second},
+		{name: "transient", category: RequeueTransient, attempt: 2, want: 4 * time.Second},
+		{name: "attempt below 1", category: RequeueTransient, attempt: 0, want: 2 * time.Second},
+		{name: "unknown category uses transient policy", category: RequeueCategory("Unknown"), attempt: 1, want: 2 * time.Second},
+		{name: "large attempt does not overflow", category: RequeueTransient, attempt: 10000, want: 2 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.category.Backoff(tt.attempt); got != tt.want {
+				t.Errorf("Backoff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequeueBackoff(t *testing.T) {
+	type step struct {
+		key      string
+		category RequeueCategory
+		forget   bool
+		want     time.Duration
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "backoff grows for same category",
+			steps: []step{
+				{key: "ns/a", category: RequeueConflict, want: time.Second},
+				{key: "ns/a", category: RequeueConflict, want: 2 * time.Second},
+				{key: "ns/a", category: RequeueConflict, want: 4 * time.Second},
+			},
+		},
+		{
+			name: "category change resets attempts",
+			steps: []step{
+				{key: "ns/a", category: RequeueConflict, want: time.Second},
+				{key: "ns/a", category: RequeueConflict, want: 2 * time.Second},
+				{key: "ns/a", category: RequeueWaitingForDependency, want: 5 * time.Second},
+				{key: "ns/a", category: RequeueConflict, want: time.Second},
+			},
+		},
+		{
+			name: "forget resets attempts",
+			steps: []step{
+				{key: "ns/a", category: RequeueTransient, want: 2 * time.Second},
+				{key: "ns/a", category: RequeueTransient, want: 4 * time.Second},
+				{key: "ns/a", forget: true},
+				{key: "ns/a", category: RequeueTransient, want: 2 * time.Second},
+			},
+		},
+		{
+			name: "keys are counted separately",
+			steps: []step{
+				{key: "ns/a", category: RequeueTransient, want: 2 * time.Second},
+				{key: "ns/b", category: RequeueTransient, want: 2 * time.Second},
+				{key: "ns/a", category: RequeueTransient, want: 4 * time.Second},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backoff := NewRequeueBackoff()
+			for i, s := range tt.steps {
+				if s.forget {
+					backoff.Forget(s.key)
+					continue
+				}
+				if got := backoff.Next(s.key, s.category); got != s.want {
+					t.Errorf("step %d: Next() = %v, want %v", i, got, s.want)
+				}
+			}
+		})
+	}
+}
@@ -46,7 +46,7 @@ func RouterEntryCmName(cr *promext.PrometheusExt) string {
 
 type proRouterNgParas struct {
 	Managed    bool //is it managed instance? true for now
-	Openshift  bool //installed on top openshift? true for now
+	Openshift  bool //installed on top openshift?
 	Standalone bool
 }
 
@@ -64,20 +64,12 @@ func NewAlertmanagerRouterNgCm(cr *promext.PrometheusExt) *v1.ConfigMap {
 
 }
 
-//UpdatedAlertRouterNgcm update configmap for alertmanager router nginx onfig
-func UpdatedAlertRouterNgcm(cr *promext.PrometheusExt, curr *v1.ConfigMap) *v1.ConfigMap {
-	cm := curr.DeepCopy()
-	cm.Labels = alertmanagerLabels(cr)
-	cm.Data = map[string]string{"nginx.conf": alertRouterConfig}
-	return cm
-}
-
 //NewProRouterNgCm returns configmap for router nginx
 func NewProRouterNgCm(cr *promext.PrometheusExt) (*v1.ConfigMap, error) {
 	var tplBuffer bytes.Buffer
 	paras := proRouterNgParas{
 		Managed:    true,
-		Openshift:  true,
+		Openshift:  IsOpenShift(),
 		Standalone: !cr.Spec.MCMMonitor.IsHubCluster,
 	}
 	if err := prometheusNgConfTemplate.Execute(&tplBuffer, paras); err != nil {
@@ -105,41 +97,6 @@ func ProLuaUtilsCmName(cr *promext.PrometheusExt) string {
 	return cr.Name + "-prometheus-lua-utils"
 }
 
-//UpdatedProLuaCm return cupdated onfigmap for prometheus lua script
-func UpdatedProLuaCm(cr *promext.PrometheusExt, curr *v1.ConfigMap) (*v1.ConfigMap, error) {
-	cm := curr.DeepCopy()
-	var tplBuffer bytes.Buffer
-	helmNamespace := cr.Namespace
-	if cr.Spec.HelmReleasesMonitor.Namespace != "" {
-		helmNamespace = cr.Spec.HelmReleasesMonitor.Namespace
-	}
-	helmPort := defaultHelmPort
-	if cr.Spec.HelmReleasesMonitor.Port != 0 {
-		helmPort = cr.Spec.HelmReleasesMonitor.Port
-
-	}
-	clusterDomain := defaultClusterDomain
-	if cr.Spec.ClusterDomain != "" {
-		clusterDomain = cr.Spec.ClusterDomain
-	}
-	paras := luaParas{
-		Standalone:          !cr.Spec.MCMMonitor.IsHubCluster,
-		Managed:             true,
-		Openshift:           true,
-		AlertmanagerSvcName: AlertmanagerName(cr),
-		AlertmanagerSvcPort: fmt.Sprintf("%d", cr.Spec.AlertManagerConfig.ServicePort),
-		HelmNamespace:       helmNamespace,
-		HelmPort:            helmPort,
-		ClusterDomain:       clusterDomain,
-	}
-	if err := prometheusLuaTemplate.Execute(&tplBuffer, paras); err != nil {
-		return nil, err
-	}
-	cm.Labels = PrometheusLabels(cr)
-	cm.Data = map[string]string{"prom.lua": tplBuffer.String()}
-	return cm, nil
-}
-
 type luaParas struct {
 	Openshift           bool
 	Managed             bool
@@ -172,7 +129,7 @@ func NewProLuaCm(cr *promext.PrometheusExt) (*v1.ConfigMap, error) {
 	paras := luaParas{
 		Standalone:          !cr.Spec.MCMMonitor.IsHubCluster,
 		Managed:             true,
-		Openshift:           true,
+		Openshift:           IsOpenShift(),
 		AlertmanagerSvcName: AlertmanagerName(cr),
 		AlertmanagerSvcPort: fmt.Sprintf("%d", cr.Spec.AlertManagerConfig.ServicePort),
 		HelmNamespace:       helmNamespace,
@@ -193,48 +150,6 @@ func NewProLuaCm(cr *promext.PrometheusExt) (*v1.ConfigMap, error) {
 	return cm, nil
 }
 
-//UpdatedProLuaUtilsCm return updated configmap for prometheus lua utils script
-func UpdatedProLuaUtilsCm(cr *promext.PrometheusExt, curr *v1.ConfigMap) (*v1.ConfigMap, error) {
-	var tplBuffer bytes.Buffer
-	iamNS := cr.Namespace
-	if cr.Spec.IAMProvider.Namespace != "" {
-		iamNS = cr.Spec.IAMProvider.Namespace
-	}
-
-	clusterName := defaultClusterName
-	if cr.Spec.ClusterName != "" {
-		clusterName = cr.Spec.ClusterName
-
-	}
-	clusterDomain := defaultClusterDomain
-	if cr.Spec.ClusterDomain != "" {
-		clusterDomain = cr.Spec.ClusterDomain
-
-	}
-
-	paras := luaUtilsParas{
-		ClusterName:          clusterName,
-		ClusterDomain:        clusterDomain,
-		Namespace:            cr.Namespace,
-		PrometheusSvcName:    PromethuesName(cr),
-		PrometheusSvcPort:    fmt.Sprintf("%d", cr.Spec.PrometheusConfig.ServicePort),
-		GrafanaSvcName:       cr.Spec.GrafanaSvcName,
-		GrafanaSvcPort:       fmt.Sprintf("%d", cr.Spec.GrafanaSvcPort),
-		IAMNamespace:         iamNS,
-		IAMProviderSvcName:   cr.Spec.IAMProvider.IDProviderSvc,
-		IAMProviderSvcPort:   fmt.Sprintf("%d", cr.Spec.IAMProvider.IDProviderSvcPort),
-		IAMManagementSvcName: cr.Spec.IAMProvider.IDManagementSvc,
-		IAMManagementSvcPort: fmt.Sprintf("%d", cr.Spec.IAMProvider.IDManagementSvcPort),
-	}
-	if err := prometheusLuaUtilsTemplate.Execute(&tplBuffer, paras); err != nil {
-		return nil, err
-	}
-	cm := curr.DeepCopy()
-	cm.Labels = PrometheusLabels(cr)
-	cm.Data = map[string]string{"monitoring-util.lua": tplBuffer.String()}
-	return cm, nil
-}
-
 type luaUtilsParas struct {
 	ClusterName       string
 	Namespace         string
@@ -299,23 +214,6 @@ func NewProLuaUtilsCm(cr *promext.PrometheusExt) (*v1.ConfigMap, error) {
 	return cm, nil
 }
 
-//UpdatedProRouterNgCm creates updated configmap for prometheus router nginx config
-func UpdatedProRouterNgCm(cr *promext.PrometheusExt, curr *v1.ConfigMap) (*v1.ConfigMap, error) {
-	cm := curr.DeepCopy()
-	var tplBuffer bytes.Buffer
-	paras := proRouterNgParas{
-		Managed:    true,
-		Openshift:  true,
-		Standalone: !cr.Spec.MCMMonitor.IsHubCluster,
-	}
-	if err := prometheusNgConfTemplate.Execute(&tplBuffer, paras); err != nil {
-		return nil, err
-	}
-	cm.Labels = PrometheusLabels(cr)
-	cm.Data = map[string]string{"nginx.conf": tplBuffer.String()}
-	return cm, nil
-}
-
 type routerEntryParas struct {
 	Openshift bool
 	Managed   bool
@@ -326,7 +224,7 @@ func NewRouterEntryCm(cr *promext.PrometheusExt) (*v1.ConfigMap, error) {
 	var tplBuffer bytes.Buffer
 	paras := routerEntryParas{
 		Managed:   true,
-		Openshift: true,
+		Openshift: IsOpenShift(),
 	}
 	if err := routerEntrypointTemplate.Execute(&tplBuffer, paras); err != nil {
 		return nil, err
@@ -341,24 +239,6 @@ func NewRouterEntryCm(cr *promext.PrometheusExt) (*v1.ConfigMap, error) {
 	}, nil
 }
 
-//UpdatedRouterEntryCm returns updated configmap for router entrypoint
-func UpdatedRouterEntryCm(cr *promext.PrometheusExt, curr *v1.ConfigMap) (*v1.ConfigMap, error) {
-
-	var tplBuffer bytes.Buffer
-	paras := routerEntryParas{
-		Managed:   true,
-		Openshift: true,
-	}
-	if err := routerEntrypointTemplate.Execute(&tplBuffer, paras); err != nil {
-		return nil, err
-	}
-	cm := curr.DeepCopy()
-	cm.Labels = PrometheusLabels(cr)
-	cm.Data = map[string]string{"entrypoint.sh": tplBuffer.String()}
-
-	return cm, nil
-}
-
 //NewRouterContainer returns router container
 func NewRouterContainer(cr *promext.PrometheusExt, ot ObjectType) *v1.Container {
 	rofs := false
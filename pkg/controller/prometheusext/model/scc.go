@@ -46,6 +46,14 @@ func CreateOrUpdateSCC(secClient secv1client.SecurityV1Interface, userNamespace
 	return nil
 
 }
+
+//NewSCC returns SCC object which is used by service accounts of userNamespace
+func NewSCC(userNamespace string) *secv1.SecurityContextConstraints {
+	scc := blankSCC()
+	setSCC(scc, userNamespace)
+	return scc
+}
+
 func blankSCC() *secv1.SecurityContextConstraints {
 	scc := &secv1.SecurityContextConstraints{
 		TypeMeta: metav1.TypeMeta{
@@ -91,9 +99,55 @@ func setSCC(scc *secv1.SecurityContextConstraints, userNamespace string) {
 		secv1.FSProjected,
 		secv1.FSTypeSecret,
 	}
-	scc.Users = []string{
+	// keep users of other namespaces
+	for _, user := range sccUsers(userNamespace) {
+		if !containsString(scc.Users, user) {
+			scc.Users = append(scc.Users, user)
+		}
+	}
+
+}
+
+//RemoveSCCUsers removes service accounts of userNamespace from SCC users and deletes SCC if no user is left
+func RemoveSCCUsers(secClient secv1client.SecurityV1Interface, userNamespace string) error {
+	scc, err := secClient.SecurityContextConstraints().Get(blankSCC().Name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	removed := sccUsers(userNamespace)
+	var users []string
+	for _, user := range scc.Users {
+		if !containsString(removed, user) {
+			users = append(users, user)
+		}
+	}
+	if len(users) == 0 {
+		err = secClient.SecurityContextConstraints().Delete(scc.Name, &metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	scc.Users = users
+	_, err = secClient.SecurityContextConstraints().Update(scc)
+	return err
+}
+
+func sccUsers(userNamespace string) []string {
+	return []string{
 		"system:serviceaccount:" + userNamespace + ":ibm-monitoring-prometheusext-operand",
 		"system:serviceaccount:" + userNamespace + ":ibm-monitoring-mcm-ctl",
 	}
+}
 
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
 }
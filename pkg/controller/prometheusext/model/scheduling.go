@@ -0,0 +1,49 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package model
+
+import (
+	v1 "k8s.io/api/core/v1"
+
+	promext "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+)
+
+//componentScheduling returns scheduling of pods of a component. Node selector of CR is used if component has no node selector
+func componentScheduling(cr *promext.PrometheusExt, scheduling promext.SchedulingSpec) promext.SchedulingSpec {
+	if len(scheduling.NodeSelector) == 0 {
+		scheduling.NodeSelector = cr.Spec.NodeSelector
+	}
+	return scheduling
+}
+
+//applyScheduling sets scheduling of a component to pod spec of a deployment
+func applyScheduling(cr *promext.PrometheusExt, spec *v1.PodSpec, scheduling promext.DeploymentSchedulingSpec) {
+	podScheduling := componentScheduling(cr, scheduling.SchedulingSpec)
+	spec.NodeSelector = podScheduling.NodeSelector
+	spec.Tolerations = podScheduling.Tolerations
+	spec.Affinity = podScheduling.Affinity
+	spec.PriorityClassName = podScheduling.PriorityClassName
+	spec.TopologySpreadConstraints = scheduling.TopologySpreadConstraints
+}
+
+//statefulSetAffinity returns affinity of prometheus or alertmanager pods. Replicas are spread to nodes by default
+func statefulSetAffinity(cr *promext.PrometheusExt, ot ObjectType, scheduling promext.SchedulingSpec) *v1.Affinity {
+	if scheduling.Affinity != nil {
+		return scheduling.Affinity
+	}
+	return podAntiAffinity(cr, ot)
+}
@@ -0,0 +1,378 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package model
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"reflect"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	proext "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+)
+
+const (
+	//SelfManagedCertAnn is annotation of tls secrets created by operator in selfManaged mode
+	SelfManagedCertAnn = "monitoring.operator.ibm.com/self-managed-cert"
+	//CACertKey is key of ca cert in tls secrets
+	CACertKey = "ca.crt"
+	//CARotatedAnn is annotation of CA secret with time when CA was rotated
+	CARotatedAnn = "monitoring.operator.ibm.com/ca-rotated-at"
+	//CATrustOverlap is how long certificates stay signed by previous CA after CA is rotated,
+	//so that all pods trust the new CA before certificates signed by it are used
+	CATrustOverlap = time.Hour
+	//NotSignedByCurrentCA is reason of rotation when certificate is signed by other CA
+	NotSignedByCurrentCA = "certificate is not signed by current CA"
+
+	defaultCertDuration   = 90 * 24 * time.Hour
+	defaultCACertDuration = 5 * 365 * 24 * time.Hour
+	defaultRSAKeySize     = 2048
+)
+
+//IsSelfManagedCerts tells if tls certificates are created by operator
+func IsSelfManagedCerts(cr *proext.PrometheusExt) bool {
+	return cr.Spec.Certs.Mode == proext.CertsModeSelfManaged
+}
+
+//CASecretName returns name of secret which stores operator's CA in selfManaged mode
+func CASecretName(cr *proext.PrometheusExt) string {
+	return cr.Name + "-monitoring-ca"
+}
+
+//IsSelfManagedSecret tells if secret is created by operator in selfManaged mode
+func IsSelfManagedSecret(secret *v1.Secret) bool {
+	_, ok := secret.Annotations[SelfManagedCertAnn]
+	return ok
+}
+
+//NewCASecret creates secret with a new self signed CA. When previous CA secret is given,
+//its certificates which are not expired are kept in ca.crt, so certificates signed by them are still trusted
+func NewCASecret(cr *proext.PrometheusExt, previous *v1.Secret) (*v1.Secret, error) {
+	key, err := newPrivateKey(cr)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: AppLabelValue + "-ca", Organization: []string{"IBM"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(defaultCACertDuration),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	certPEM, keyPEM, err := signCertificate(template, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        CASecretName(cr),
+			Namespace:   cr.Namespace,
+			Labels:      getCertLabels(cr),
+			Annotations: map[string]string{SelfManagedCertAnn: "true"},
+		},
+		Type: v1.SecretTypeTLS,
+		Data: map[string][]byte{
+			v1.TLSCertKey:       certPEM,
+			v1.TLSPrivateKeyKey: keyPEM,
+			CACertKey:           certPEM,
+		},
+	}
+	if previous != nil {
+		secret.Annotations[CARotatedAnn] = now.UTC().Format(time.RFC3339)
+		secret.Data[CACertKey] = append(append([]byte{}, certPEM...), validCerts(previous.Data[CACertKey], now)...)
+	}
+	return secret, nil
+}
+
+//CATrustedAt returns time when new CA in caSecret is trusted by all pods, and certificates can be signed by it.
+//It is zero if CA is not rotated
+func CATrustedAt(caSecret *v1.Secret) time.Time {
+	rotated, err := time.Parse(time.RFC3339, caSecret.Annotations[CARotatedAnn])
+	if err != nil {
+		return time.Time{}
+	}
+	return rotated.Add(CATrustOverlap)
+}
+
+//WithCABundle returns copy of tls secret which trusts CA certificates of caSecret
+func WithCABundle(secret *v1.Secret, caSecret *v1.Secret) *v1.Secret {
+	updated := secret.DeepCopy()
+	updated.Data[CACertKey] = caSecret.Data[CACertKey]
+	return updated
+}
+
+//validCerts returns pem encoded certificates in bundle which are not expired at now
+func validCerts(bundle []byte, now time.Time) []byte {
+	var valid []byte
+	for {
+		block, rest := pem.Decode(bundle)
+		if block == nil {
+			return valid
+		}
+		bundle = rest
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil || now.After(cert.NotAfter) {
+			continue
+		}
+		valid = append(valid, pem.EncodeToMemory(block)...)
+	}
+}
+
+//NewSelfManagedCertSecret creates tls secret signed by CA in caSecret
+func NewSelfManagedCertSecret(cr *proext.PrometheusExt, name string, caSecret *v1.Secret, dnsNames []string, ipAddresses []string) (*v1.Secret, error) {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cr.Namespace,
+		},
+	}
+	return selfManagedCertSecret(cr, secret, caSecret, dnsNames, ipAddresses)
+}
+
+//NewSelfManagedCertPlaceholder returns secret of selfManaged mode which has placeholder instead of certificate and key.
+//Key material is created only in cluster, so rendered objects do not contain private keys and do not change between runs
+func NewSelfManagedCertPlaceholder(cr *proext.PrometheusExt, name string) *v1.Secret {
+	placeholder := func(key string) []byte {
+		return []byte(fmt.Sprintf("<created by operator in secret %s/%s key %s>", cr.Namespace, name, key))
+	}
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   cr.Namespace,
+			Labels:      getCertLabels(cr),
+			Annotations: map[string]string{SelfManagedCertAnn: "true"},
+		},
+		Type: v1.SecretTypeTLS,
+		Data: map[string][]byte{
+			v1.TLSCertKey:       placeholder(v1.TLSCertKey),
+			v1.TLSPrivateKeyKey: placeholder(v1.TLSPrivateKeyKey),
+			CACertKey:           placeholder(CACertKey),
+		},
+	}
+}
+
+//selfManagedCertSecret returns secret with a new key pair signed by CA in caSecret
+func selfManagedCertSecret(cr *proext.PrometheusExt, curr *v1.Secret, caSecret *v1.Secret, dnsNames []string, ipAddresses []string) (*v1.Secret, error) {
+	caCert, caKey, err := parseKeyPair(caSecret)
+	if err != nil {
+		return nil, err
+	}
+	key, err := newPrivateKey(cr)
+	if err != nil {
+		return nil, err
+	}
+	duration := defaultCertDuration
+	if cr.Spec.Certs.Duration != nil && cr.Spec.Certs.Duration.Duration > 0 {
+		duration = cr.Spec.Certs.Duration.Duration
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: AppLabelValue},
+		NotBefore:   now.Add(-time.Hour),
+		NotAfter:    now.Add(duration),
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:    dnsNames,
+	}
+	for _, ip := range ipAddresses {
+		parsed := net.ParseIP(ip)
+		if parsed == nil {
+			return nil, NewPermanentError("certificates", fmt.Sprintf("invalid ip address %s for certificate %s", ip, curr.Name))
+		}
+		template.IPAddresses = append(template.IPAddresses, parsed)
+	}
+	if template.NotAfter.After(caCert.NotAfter) {
+		template.NotAfter = caCert.NotAfter
+	}
+	certPEM, keyPEM, err := signCertificate(template, key, caCert, caKey)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := curr.DeepCopy()
+	secret.Labels = getCertLabels(cr)
+	if secret.Annotations == nil {
+		secret.Annotations = make(map[string]string)
+	}
+	secret.Annotations[SelfManagedCertAnn] = "true"
+	secret.Type = v1.SecretTypeTLS
+	secret.Data = map[string][]byte{
+		v1.TLSCertKey:       certPEM,
+		v1.TLSPrivateKeyKey: keyPEM,
+		CACertKey:           caSecret.Data[CACertKey],
+	}
+	return secret, nil
+}
+
+//CertNeedsRotation tells if certificate in secret needs to be created again and why.
+//It is true if certificate can not be parsed, expires within renew period, is not signed by current CA
+//or has different subject alternative names. caSecret is nil for CA itself
+func CertNeedsRotation(cr *proext.PrometheusExt, secret *v1.Secret, caSecret *v1.Secret, dnsNames []string, ipAddresses []string) (bool, string) {
+	cert, err := parseCert(secret.Data[v1.TLSCertKey])
+	if err != nil {
+		return true, "invalid certificate: " + err.Error()
+	}
+	if time.Now().Add(renewBefore(cr, cert)).After(cert.NotAfter) {
+		return true, "certificate expires at " + cert.NotAfter.String()
+	}
+	if caSecret == nil {
+		return false, ""
+	}
+	caCert, err := parseCert(caSecret.Data[v1.TLSCertKey])
+	if err != nil || cert.CheckSignatureFrom(caCert) != nil {
+		return true, NotSignedByCurrentCA
+	}
+	var ips []string
+	for _, ip := range cert.IPAddresses {
+		ips = append(ips, ip.String())
+	}
+	if !sameStrings(cert.DNSNames, dnsNames) || !sameStrings(ips, ipAddresses) {
+		return true, "subject alternative names are changed"
+	}
+	return false, ""
+}
+
+//CertExpiry returns expiry date of certificate stored in secret
+func CertExpiry(secret *v1.Secret) (*time.Time, error) {
+	cert, err := parseCert(secret.Data[v1.TLSCertKey])
+	if err != nil {
+		return nil, err
+	}
+	return &cert.NotAfter, nil
+}
+
+//CertRotationTime returns time when certificate in secret will be rotated
+func CertRotationTime(cr *proext.PrometheusExt, secret *v1.Secret) (*time.Time, error) {
+	cert, err := parseCert(secret.Data[v1.TLSCertKey])
+	if err != nil {
+		return nil, err
+	}
+	rotateAt := cert.NotAfter.Add(-renewBefore(cr, cert))
+	return &rotateAt, nil
+}
+
+func renewBefore(cr *proext.PrometheusExt, cert *x509.Certificate) time.Duration {
+	if cr.Spec.Certs.RenewBefore != nil && cr.Spec.Certs.RenewBefore.Duration > 0 {
+		return cr.Spec.Certs.RenewBefore.Duration
+	}
+	// renew when 2/3 of certificate lifetime is passed by default
+	return cert.NotAfter.Sub(cert.NotBefore) / 3
+}
+
+func newPrivateKey(cr *proext.PrometheusExt) (crypto.Signer, error) {
+	if cr.Spec.Certs.KeyAlgorithm == "ecdsa" {
+		var curve elliptic.Curve
+		switch cr.Spec.Certs.KeySize {
+		case 0, 256:
+			curve = elliptic.P256()
+		case 384:
+			curve = elliptic.P384()
+		case 521:
+			curve = elliptic.P521()
+		default:
+			return nil, NewPermanentError("certificates", fmt.Sprintf("unsupported ecdsa key size %d", cr.Spec.Certs.KeySize))
+		}
+		return ecdsa.GenerateKey(curve, rand.Reader)
+	}
+	size := defaultRSAKeySize
+	switch cr.Spec.Certs.KeySize {
+	case 0:
+	case 2048, 3072, 4096:
+		size = cr.Spec.Certs.KeySize
+	default:
+		return nil, NewPermanentError("certificates", fmt.Sprintf("unsupported rsa key size %d", cr.Spec.Certs.KeySize))
+	}
+	return rsa.GenerateKey(rand.Reader, size)
+}
+
+//signCertificate signs template with ca. template is self signed if caCert is nil
+func signCertificate(template *x509.Certificate, key crypto.Signer, caCert *x509.Certificate, caKey crypto.Signer) ([]byte, []byte, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	template.SerialNumber = serial
+	if caCert == nil {
+		caCert = template
+		caKey = key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, key.Public(), caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer})
+	return certPEM, keyPEM, nil
+}
+
+func parseCert(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no pem data found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func parseKeyPair(secret *v1.Secret) (*x509.Certificate, crypto.Signer, error) {
+	cert, err := parseCert(secret.Data[v1.TLSCertKey])
+	if err != nil {
+		return nil, nil, err
+	}
+	block, _ := pem.Decode(secret.Data[v1.TLSPrivateKeyKey])
+	if block == nil {
+		return nil, nil, fmt.Errorf("no private key found in secret %s", secret.Name)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported private key in secret %s", secret.Name)
+	}
+	return cert, signer, nil
+}
+
+func sameStrings(a []string, b []string) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	x := append([]string{}, a...)
+	y := append([]string{}, b...)
+	sort.Strings(x)
+	sort.Strings(y)
+	return reflect.DeepEqual(x, y)
+}
@@ -0,0 +1,146 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	proext "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+)
+
+func TestCertNeedsRotation(t *testing.T) {
+	cr := &proext.PrometheusExt{
+		ObjectMeta: metav1.ObjectMeta{Name: "ibm-monitoring", Namespace: "ibm-common-services"},
+		Spec:       proext.PrometheusExtSpec{Certs: proext.Certs{Mode: proext.CertsModeSelfManaged, KeyAlgorithm: "ecdsa"}},
+	}
+	shortLived := cr.DeepCopy()
+	shortLived.Spec.Certs.Duration = &metav1.Duration{Duration: time.Hour}
+	shortLived.Spec.Certs.RenewBefore = &metav1.Duration{Duration: 2 * time.Hour}
+
+	ca, err := NewCASecret(cr, nil)
+	if err != nil {
+		t.Fatalf("NewCASecret() error = %v", err)
+	}
+	otherCA, err := NewCASecret(cr, nil)
+	if err != nil {
+		t.Fatalf("NewCASecret() error = %v", err)
+	}
+	dnsNames := []string{"prometheus", "alertmanager"}
+	ips := []string{"127.0.0.1"}
+	cert, err := NewSelfManagedCertSecret(cr, "monitoring-cert", ca, dnsNames, ips)
+	if err != nil {
+		t.Fatalf("NewSelfManagedCertSecret() error = %v", err)
+	}
+	expiring, err := NewSelfManagedCertSecret(shortLived, "monitoring-cert", ca, dnsNames, ips)
+	if err != nil {
+		t.Fatalf("NewSelfManagedCertSecret() error = %v", err)
+	}
+	invalid := &v1.Secret{Data: map[string][]byte{v1.TLSCertKey: []byte("not a certificate")}}
+
+	tests := []struct {
+		name       string
+		cr         *proext.PrometheusExt
+		secret     *v1.Secret
+		caSecret   *v1.Secret
+		dnsNames   []string
+		ips        []string
+		want       bool
+		wantReason string
+	}{
+		{name: "valid ca", cr: cr, secret: ca, want: false},
+		{name: "valid certificate", cr: cr, secret: cert, caSecret: ca, dnsNames: dnsNames, ips: ips, want: false},
+		{name: "dns names in other order", cr: cr, secret: cert, caSecret: ca, dnsNames: []string{"alertmanager", "prometheus"}, ips: ips, want: false},
+		{name: "invalid certificate", cr: cr, secret: invalid, caSecret: ca, want: true, wantReason: "invalid certificate"},
+		{name: "expires within renew period", cr: shortLived, secret: expiring, caSecret: ca, dnsNames: dnsNames, ips: ips, want: true, wantReason: "certificate expires"},
+		{name: "signed by other ca", cr: cr, secret: cert, caSecret: otherCA, dnsNames: dnsNames, ips: ips, want: true, wantReason: "not signed by current CA"},
+		{name: "dns names are changed", cr: cr, secret: cert, caSecret: ca, dnsNames: []string{"prometheus"}, ips: ips, want: true, wantReason: "subject alternative names"},
+		{name: "ip addresses are changed", cr: cr, secret: cert, caSecret: ca, dnsNames: dnsNames, want: true, wantReason: "subject alternative names"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := CertNeedsRotation(tt.cr, tt.secret, tt.caSecret, tt.dnsNames, tt.ips)
+			if got != tt.want || !strings.Contains(reason, tt.wantReason) {
+				t.Errorf("CertNeedsRotation() = %v, %q, want %v, %q", got, reason, tt.want, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestNewCASecretKeepsPreviousCA(t *testing.T) {
+	cr := &proext.PrometheusExt{
+		ObjectMeta: metav1.ObjectMeta{Name: "ibm-monitoring", Namespace: "ibm-common-services"},
+		Spec:       proext.PrometheusExtSpec{Certs: proext.Certs{Mode: proext.CertsModeSelfManaged, KeyAlgorithm: "ecdsa"}},
+	}
+	oldCA, err := NewCASecret(cr, nil)
+	if err != nil {
+		t.Fatalf("NewCASecret() error = %v", err)
+	}
+	if !CATrustedAt(oldCA).IsZero() {
+		t.Errorf("CATrustedAt() of new CA = %v, want zero", CATrustedAt(oldCA))
+	}
+	cert, err := NewSelfManagedCertSecret(cr, "monitoring-cert", oldCA, []string{}, []string{})
+	if err != nil {
+		t.Fatalf("NewSelfManagedCertSecret() error = %v", err)
+	}
+	newCA, err := NewCASecret(cr, oldCA)
+	if err != nil {
+		t.Fatalf("NewCASecret() error = %v", err)
+	}
+	if CATrustedAt(newCA).Before(time.Now().Add(CATrustOverlap - time.Minute)) {
+		t.Errorf("CATrustedAt() of rotated CA = %v, want after overlap", CATrustedAt(newCA))
+	}
+	bundle := string(newCA.Data[CACertKey])
+	if !strings.HasPrefix(bundle, string(newCA.Data[v1.TLSCertKey])) || !strings.Contains(bundle, string(oldCA.Data[v1.TLSCertKey])) {
+		t.Errorf("ca.crt of rotated CA does not have new and old CA:\n%s", bundle)
+	}
+	if rotate, reason := CertNeedsRotation(cr, cert, newCA, []string{}, []string{}); !rotate || reason != NotSignedByCurrentCA {
+		t.Errorf("CertNeedsRotation() = %v, %q, want true, %q", rotate, reason, NotSignedByCurrentCA)
+	}
+	if got := WithCABundle(cert, newCA); string(got.Data[CACertKey]) != bundle || string(got.Data[v1.TLSCertKey]) != string(cert.Data[v1.TLSCertKey]) {
+		t.Errorf("WithCABundle() changed certificate or has no bundle")
+	}
+}
+
+func TestNewPrivateKeySize(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		size      int
+		wantErr   bool
+	}{
+		{algorithm: "rsa", size: 0},
+		{algorithm: "rsa", size: 2048},
+		{algorithm: "rsa", size: 1024, wantErr: true},
+		{algorithm: "rsa", size: 256, wantErr: true},
+		{algorithm: "ecdsa", size: 384},
+		{algorithm: "ecdsa", size: 2048, wantErr: true},
+	}
+	for _, tt := range tests {
+		cr := &proext.PrometheusExt{Spec: proext.PrometheusExtSpec{Certs: proext.Certs{KeyAlgorithm: tt.algorithm, KeySize: tt.size}}}
+		_, err := newPrivateKey(cr)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("newPrivateKey() of %s %d error = %v, wantErr %v", tt.algorithm, tt.size, err, tt.wantErr)
+		}
+		if err != nil && !IsPermanentErr(err) {
+			t.Errorf("newPrivateKey() of %s %d error = %v, want permanent error", tt.algorithm, tt.size, err)
+		}
+	}
+}
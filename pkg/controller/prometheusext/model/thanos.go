@@ -0,0 +1,124 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package model
+
+import (
+	"os"
+
+	promv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	promext "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+)
+
+const (
+	//ClusterExternalLabel is external label of prometheus which has cluster name when Thanos sidecar is enabled.
+	//Together with replica label it makes series of every prometheus pod unique in Thanos
+	ClusterExternalLabel = "cluster"
+	//ThanosGRPCPort is port of Store API of Thanos sidecar
+	ThanosGRPCPort = 10901
+
+	defaultThanosImage = "quay.io/thanos/thanos:v0.10.1"
+)
+
+//IsThanosEnabled tells if Thanos sidecar is added to prometheus pods
+func IsThanosEnabled(cr *promext.PrometheusExt) bool {
+	return cr.Spec.PrometheusConfig.Thanos != nil
+}
+
+//ThanosSecrets returns names of secrets referred by Thanos sidecar
+func ThanosSecrets(cr *promext.PrometheusExt) []string {
+	if !IsThanosEnabled(cr) || cr.Spec.PrometheusConfig.Thanos.ObjectStorageConfig == nil {
+		return nil
+	}
+	return []string{cr.Spec.PrometheusConfig.Thanos.ObjectStorageConfig.Name}
+}
+
+func thanosImage(cr *promext.PrometheusExt) string {
+	if cr.Spec.PrometheusConfig.Thanos.Image != "" {
+		return cr.Spec.PrometheusConfig.Thanos.Image
+	}
+	if image := os.Getenv(thanosImageEnv); image != "" {
+		return image
+	}
+	return defaultThanosImage
+}
+
+//thanosSpec returns Thanos sidecar of prometheus spec. Prometheus operator adds the sidecar container
+//and disables local compaction, so that blocks can be uploaded
+func thanosSpec(cr *promext.PrometheusExt) *promv1.ThanosSpec {
+	if !IsThanosEnabled(cr) {
+		return nil
+	}
+	image := thanosImage(cr)
+	return &promv1.ThanosSpec{
+		Image:               &image,
+		Resources:           cr.Spec.PrometheusConfig.Thanos.Resources,
+		ObjectStorageConfig: cr.Spec.PrometheusConfig.Thanos.ObjectStorageConfig,
+	}
+}
+
+//prometheusExternalLabels returns external labels of prometheus when Thanos is enabled. Replica label is added
+//by prometheus operator, so neither it nor cluster label can be overridden
+func prometheusExternalLabels(cr *promext.PrometheusExt) map[string]string {
+	if !IsThanosEnabled(cr) {
+		return nil
+	}
+	clusterName := defaultClusterName
+	if cr.Spec.ClusterName != "" {
+		clusterName = cr.Spec.ClusterName
+	}
+	labels := make(map[string]string)
+	for k, v := range cr.Spec.PrometheusConfig.Thanos.ExternalLabels {
+		if k != ReplicaExternalLabel {
+			labels[k] = v
+		}
+	}
+	labels[ClusterExternalLabel] = clusterName
+	return labels
+}
+
+//ThanosGRPCServiceName returns name of service of Thanos sidecar Store API
+func ThanosGRPCServiceName(cr *promext.PrometheusExt) string {
+	return PromethuesName(cr) + "-thanos-grpc"
+}
+
+//NewThanosGRPCService creates headless service of Store API of Thanos sidecars. Thanos Query discovers
+//all prometheus pods with its DNS SRV record _grpc._tcp.<name>.<namespace>.svc
+func NewThanosGRPCService(cr *promext.PrometheusExt) *v1.Service {
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ThanosGRPCServiceName(cr),
+			Namespace: cr.Namespace,
+			Labels:    PrometheusLabels(cr),
+		},
+		Spec: v1.ServiceSpec{
+			ClusterIP: v1.ClusterIPNone,
+			Ports: []v1.ServicePort{
+				{
+					Name:       "grpc",
+					Protocol:   "TCP",
+					Port:       ThanosGRPCPort,
+					TargetPort: intstr.FromString("grpc"),
+				},
+			},
+			Selector: prometheusSvcSelectors(cr),
+		},
+	}
+}
@@ -18,18 +18,27 @@ package prometheusext
 
 import (
 	"context"
-	"time"
+	"reflect"
 
 	promev1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	routev1 "github.com/openshift/api/route/v1"
 	secv1client "github.com/openshift/client-go/security/clientset/versioned/typed/security/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
@@ -48,20 +57,43 @@ var log = logf.Log.WithName("controller_prometheusext")
 // Add creates a new PrometheusExt Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr))
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	platform, err := model.DetectPlatform(discoveryClient)
+	if err != nil {
+		return err
+	}
+	log.Info("Detected platform", "openshift", platform.OpenShift, "routes", platform.Routes, "ingressV1", platform.IngressV1)
+	model.SetPlatform(platform)
+	// AlertRoutes are created in namespaces of application teams which may not be watched by manager
+	// so read them with a cache of all namespaces
+	alertRouteCache, err := cache.New(mgr.GetConfig(), cache.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return err
+	}
+	if err := mgr.Add(alertRouteCache); err != nil {
+		return err
+	}
+	return add(mgr, newReconciler(mgr, alertRouteCache), alertRouteCache)
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+func newReconciler(mgr manager.Manager, alertRouteCache cache.Cache) reconcile.Reconciler {
 	return &ReconcilePrometheusExt{
-		client:    mgr.GetClient(),
-		scheme:    mgr.GetScheme(),
-		secClient: secv1client.NewForConfigOrDie(mgr.GetConfig()),
+		alertRouteReader: alertRouteCache,
+		apiReader:        mgr.GetAPIReader(),
+		client:           mgr.GetClient(),
+		scheme:           mgr.GetScheme(),
+		secClient:        secv1client.NewForConfigOrDie(mgr.GetConfig()),
+		backoff:          model.NewRequeueBackoff(),
+		recorder:         reconsiler.NewEventRecorder(mgr.GetEventRecorderFor("prometheusext-controller"), reconsiler.DefaultEventInterval),
 	}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
-func add(mgr manager.Manager, r reconcile.Reconciler) error {
+func add(mgr manager.Manager, r reconcile.Reconciler, alertRouteCache cache.Cache) error {
 	// Create a new controller
 	c, err := controller.New("prometheusext-controller", mgr, controller.Options{Reconciler: r})
 	if err != nil {
@@ -69,7 +101,8 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	}
 
 	// Watch for changes to primary resource PrometheusExt
-	err = c.Watch(&source.Kind{Type: &monitoringv1alpha1.PrometheusExt{}}, &handler.EnqueueRequestForObject{})
+	// Updates of status only are ignored, otherwise every status update would trigger another reconciliation
+	err = c.Watch(&source.Kind{Type: &monitoringv1alpha1.PrometheusExt{}}, &handler.EnqueueRequestForObject{}, crPredicate())
 	if err != nil {
 		return err
 	}
@@ -101,9 +134,183 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	if err != nil {
 		return err
 	}
+	// Watch statefulsets created by prometheus operator for managed Prometheus and Alertmanager
+	// They are not owned by PrometheusExt CR so map them to CR with the managed label
+	err = c.Watch(&source.Kind{Type: &appsv1.StatefulSet{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+			crName := model.ManagedByCR(obj.Meta.GetLabels())
+			if crName == "" {
+				return nil
+			}
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: crName, Namespace: obj.Meta.GetNamespace()}}}
+		}),
+	})
+	if err != nil {
+		return err
+	}
+	// Watch AlertRoutes of all namespaces. They are merged into configuration of every PrometheusExt
+	alertRouteInformer, err := alertRouteCache.GetInformer(&monitoringv1alpha1.AlertRoute{})
+	if err != nil {
+		return err
+	}
+	mgrClient := mgr.GetClient()
+	err = c.Watch(&source.Informer{Informer: alertRouteInformer}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+			return requestsForCRs(mgrClient, "AlertRoute "+obj.Meta.GetName(), nil)
+		}),
+	}, alertRoutePredicate())
+	if err != nil {
+		return err
+	}
+	// Watch secrets referred by receivers of AlertRoutes. They are in namespaces of application teams,
+	// so they are read by the cache of all namespaces and mapped to all CRs when an AlertRoute of the namespace refers them
+	tenantSecretInformer, err := alertRouteCache.GetInformer(&v1.Secret{})
+	if err != nil {
+		return err
+	}
+	err = c.Watch(&source.Informer{Informer: tenantSecretInformer}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+			if !referredByAlertRoute(alertRouteCache, obj.Meta.GetNamespace(), obj.Meta.GetName()) {
+				return nil
+			}
+			return requestsForCRs(mgrClient, "secret "+obj.Meta.GetName()+" of AlertRoute", nil)
+		}),
+	})
+	if err != nil {
+		return err
+	}
+	// Watch router configmaps so that pods are restarted when they are changed
+	err = c.Watch(&source.Kind{Type: &v1.ConfigMap{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &monitoringv1alpha1.PrometheusExt{},
+	})
+	if err != nil {
+		return err
+	}
+	// Watch management ingress configmap which provides cluster host of all CRs in its namespace
+	err = c.Watch(&source.Kind{Type: &v1.ConfigMap{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+			if obj.Meta.GetName() != model.ManagedIngressCm {
+				return nil
+			}
+			return requestsForCRs(mgrClient, "configmap "+obj.Meta.GetName(), nil, client.InNamespace(obj.Meta.GetNamespace()))
+		}),
+	})
+	if err != nil {
+		return err
+	}
+	// Watch secrets owned by CR such as prometheus scrape targets and self managed certificates
+	err = c.Watch(&source.Kind{Type: &v1.Secret{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &monitoringv1alpha1.PrometheusExt{},
+	})
+	if err != nil {
+		return err
+	}
+	// Watch tls secrets, alertmanager configuration and secrets referred by alertmanager receivers.
+	// They may be created by cert-manager or users and not owned by PrometheusExt CR so map them to the CRs which refer them
+	err = c.Watch(&source.Kind{Type: &v1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+			return requestsForCRs(mgrClient, "secret "+obj.Meta.GetName(), func(cr *monitoringv1alpha1.PrometheusExt) bool {
+				for _, name := range model.SecretsReferredByCR(cr) {
+					if name == obj.Meta.GetName() {
+						return true
+					}
+				}
+				return false
+			}, client.InNamespace(obj.Meta.GetNamespace()))
+		}),
+	})
+	if err != nil {
+		return err
+	}
+	// Watch services, ingresses, routes, pod disruption budgets and default prometheus rules so that they are restored
+	// when they are changed or deleted. Ingresses are watched in the version served by cluster
+	owned := []runtime.Object{&v1.Service{}, model.NewIngressObject(), &policyv1beta1.PodDisruptionBudget{}, &promev1.PrometheusRule{}}
+	if model.CurrentPlatform().Routes {
+		owned = append(owned, &routev1.Route{})
+	}
+	for _, kind := range owned {
+		err = c.Watch(&source.Kind{Type: kind}, &handler.EnqueueRequestForOwner{
+			IsController: true,
+			OwnerType:    &monitoringv1alpha1.PrometheusExt{},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	// Watch storage classes. CRs without storage class in spec use the default storage class of cluster
+	err = c.Watch(&source.Kind{Type: &storagev1.StorageClass{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+			return requestsForCRs(mgrClient, "storage class "+obj.Meta.GetName(), func(cr *monitoringv1alpha1.PrometheusExt) bool {
+				return cr.Spec.StorageClassName == ""
+			})
+		}),
+	})
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
+// requestsForCRs lists PrometheusExt CRs and returns requests for the ones accepted by filter (all if filter is nil)
+func requestsForCRs(c client.Client, source string, filter func(*monitoringv1alpha1.PrometheusExt) bool, opts ...client.ListOption) []reconcile.Request {
+	crs := &monitoringv1alpha1.PrometheusExtList{}
+	if err := c.List(context.TODO(), crs, opts...); err != nil {
+		log.Error(err, "failed to list PrometheusExt for "+source)
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range crs.Items {
+		cr := &crs.Items[i]
+		if filter != nil && !filter(cr) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}})
+	}
+	return requests
+}
+
+// referredByAlertRoute tells if secret is referred by receivers of an AlertRoute in its namespace
+func referredByAlertRoute(reader client.Reader, namespace string, name string) bool {
+	alertRoutes := &monitoringv1alpha1.AlertRouteList{}
+	if err := reader.List(context.TODO(), alertRoutes, client.InNamespace(namespace)); err != nil {
+		log.Error(err, "failed to list AlertRoutes for secret "+name)
+		return false
+	}
+	for i := range alertRoutes.Items {
+		for _, secret := range model.AlertRouteSecrets(&alertRoutes.Items[i]) {
+			if secret == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// alertRoutePredicate ignores status updates of AlertRoutes made by this controller
+func alertRoutePredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.MetaOld.GetGeneration() != e.MetaNew.GetGeneration()
+		},
+	}
+}
+
+//crPredicate ignores updates of CR which change only its status. Annotations are checked too because
+//reconciliation is paused by annotation
+func crPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.MetaOld.GetGeneration() != e.MetaNew.GetGeneration() ||
+				!reflect.DeepEqual(e.MetaOld.GetLabels(), e.MetaNew.GetLabels()) ||
+				!reflect.DeepEqual(e.MetaOld.GetAnnotations(), e.MetaNew.GetAnnotations()) ||
+				!reflect.DeepEqual(e.MetaOld.GetFinalizers(), e.MetaNew.GetFinalizers()) ||
+				!e.MetaOld.GetDeletionTimestamp().Equal(e.MetaNew.GetDeletionTimestamp())
+		},
+	}
+}
+
 // blank assignment to verify that ReconcilePrometheusExt implements reconcile.Reconciler
 var _ reconcile.Reconciler = &ReconcilePrometheusExt{}
 
@@ -115,6 +322,14 @@ type ReconcilePrometheusExt struct {
 	scheme *runtime.Scheme
 	// This client is for SCC creation
 	secClient secv1client.SecurityV1Interface
+	// Reader of AlertRoutes in all namespaces
+	alertRouteReader client.Reader
+	// Reader of objects which are not cached by manager
+	apiReader client.Reader
+	// Backoff of requeues of every CR
+	backoff *model.RequeueBackoff
+	// Recorder of events which is shared by reconcile loops to rate limit identical events
+	recorder *reconsiler.EventRecorder
 }
 
 // Reconcile reads that state of the cluster for a PrometheusExt object and makes changes based on the state read
@@ -137,27 +352,45 @@ func (r *ReconcilePrometheusExt) Reconcile(request reconcile.Request) (reconcile
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			r.backoff.Forget(request.String())
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
 		return reconcile.Result{}, err
 	}
 	reconsiler := reconsiler.Reconsiler{
-		Client:    r.client,
-		SecClient: r.secClient,
-		CR:        instance.DeepCopy(),
-		Schema:    r.scheme,
-		Context:   ctx,
+		Client:           r.client,
+		SecClient:        r.secClient,
+		AlertRouteReader: r.alertRouteReader,
+		APIReader:        r.apiReader,
+		Recorder:         r.recorder,
+		CR:               instance.DeepCopy(),
+		Schema:           r.scheme,
+		Context:          ctx,
+	}
+	if instance.DeletionTimestamp != nil {
+		reqLogger.Info("Cleaning up resources of deleted PrometheusExt")
+		return reconcile.Result{}, reconsiler.Finalize()
 	}
 	if err := reconsiler.ReadClusterState(); err != nil {
 		return reconcile.Result{}, err
 	}
 	if err := reconsiler.Sync(); err != nil {
-		if !model.IsRequeueErr(err) {
+		if model.IsPermanentErr(err) {
+			// retrying does not help, CR is reconciled again when it is changed
+			r.backoff.Forget(request.String())
+			reqLogger.Info("PrometheusExt can not be reconciled until it is changed", "error", err.Error())
+			return reconcile.Result{}, nil
+		}
+		requeueErr, ok := err.(model.IReqeueError)
+		if !ok {
 			return reconcile.Result{}, err
 		}
-		return reconcile.Result{Requeue: true, RequeueAfter: time.Second}, nil
+		after := r.backoff.Next(request.String(), requeueErr.Category())
+		reqLogger.Info("Requeue PrometheusExt", "category", requeueErr.Category(), "reason", requeueErr.Reason(), "detail", requeueErr.Detail(), "after", after.String())
+		return reconcile.Result{RequeueAfter: after}, nil
 	}
+	r.backoff.Forget(request.String())
 
-	return reconcile.Result{}, nil
+	return reconcile.Result{RequeueAfter: reconsiler.RequeueAfter}, nil
 }
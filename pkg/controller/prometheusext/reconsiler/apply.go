@@ -0,0 +1,169 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package reconsiler
+
+import (
+	"fmt"
+	"reflect"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	promodel "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/controller/prometheusext/model"
+)
+
+//applyObject creates or updates object with server-side apply. live is current object in cluster, it is nil if object does not exist.
+//Operator owns only fields set in obj, so fields added by other controllers or admins are kept.
+//Apply is skipped if nothing is changed. Conflicts with other field managers are not forced but reported in Degraded condition
+//and returned, so callers do not go on with state which is not applied. Only objects which were never applied by operator,
+//like objects updated by older operator versions, are applied with force once to take over fields of the update manager
+func (r *Reconsiler) applyObject(live, obj runtime.Object) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	if err := controllerutil.SetControllerReference(r.CR, accessor, r.Schema); err != nil {
+		return err
+	}
+	gvk, err := apiutil.GVKForObject(obj, r.Schema)
+	if err != nil {
+		return err
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	if err := promodel.SetAppliedHash(obj); err != nil {
+		return err
+	}
+
+	kind := gvk.Kind
+	name := accessor.GetName()
+	changed, err := objectChanged(live, obj)
+	if err != nil {
+		return err
+	}
+	recordObjectUpdate(kind, name, changed)
+	if !changed {
+		log.V(1).Info("object is unchanged and not applied", "kind", kind, "name", name)
+		return nil
+	}
+	log.Info("applying changed object", "kind", kind, "name", name)
+	opts := []client.PatchOption{client.FieldOwner(promodel.FieldManager)}
+	if live != nil && !reflect.ValueOf(live).IsNil() && !appliedByOperator(live) {
+		log.Info("taking over fields of object which was not applied by operator", "kind", kind, "name", name)
+		opts = append(opts, client.ForceOwnership)
+	}
+	if err := r.Client.Patch(r.Context, obj, client.Apply, opts...); err != nil {
+		if kerrors.IsConflict(err) {
+			log.Info("fields of object are managed by others and not applied", "kind", kind, "name", name, "conflict", err.Error())
+			conflict := fmt.Sprintf("%s %s: %v", kind, name, err)
+			r.fieldConflicts = append(r.fieldConflicts, conflict)
+			r.warningEvent(eventReasonUpdateConflict, conflict)
+			return promodel.NewRequeueErrorWithDetail(promodel.RequeueConflict, kind, "field conflict", conflict)
+		}
+		return err
+	}
+	if live == nil || reflect.ValueOf(live).IsNil() {
+		r.normalEvent(eventReasonCreated, fmt.Sprintf("created %s %s", kind, name))
+	} else {
+		r.normalEvent(eventReasonUpdated, fmt.Sprintf("updated %s %s", kind, name))
+	}
+	return nil
+}
+
+//appliedByOperator tells if live object has fields applied by field manager of operator
+func appliedByOperator(live runtime.Object) bool {
+	accessor, err := meta.Accessor(live)
+	if err != nil {
+		return false
+	}
+	for _, entry := range accessor.GetManagedFields() {
+		if entry.Manager == promodel.FieldManager && entry.Operation == metav1.ManagedFieldsOperationApply {
+			return true
+		}
+	}
+	return false
+}
+
+//objectChanged checks if fields in desired object are different with live object in cluster.
+//Desired object only has fields owned by operator, so live object may have more fields, like defaulted values
+//and fields managed by others. Removed fields are found by applied hash annotation
+func objectChanged(live, desired runtime.Object) (bool, error) {
+	if live == nil || reflect.ValueOf(live).IsNil() {
+		return true, nil
+	}
+	liveMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(live)
+	if err != nil {
+		return false, err
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(desired)
+	if err != nil {
+		return false, err
+	}
+	// type meta is not always set for objects read from cache.
+	// Content of unstructured objects is not copied by converter, so keep desired object unchanged
+	desiredMap := make(map[string]interface{}, len(content))
+	for k, v := range content {
+		if k != "apiVersion" && k != "kind" {
+			desiredMap[k] = v
+		}
+	}
+	return !isSubset(desiredMap, liveMap), nil
+}
+
+//isSubset tells if all values set in desired are same in live
+func isSubset(desired, live interface{}) bool {
+	switch d := desired.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return len(d) == 0
+		}
+		for k, v := range d {
+			if !isSubset(v, l[k]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok {
+			return len(d) == 0
+		}
+		if len(d) > len(l) {
+			return false
+		}
+		for i := range d {
+			if !isSubset(d[i], l[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(desired, live)
+	}
+}
+
+//objectKind returns kind of object. Kind in type meta is not always set for objects read from cache
+func objectKind(obj runtime.Object) string {
+	return reflect.TypeOf(obj).Elem().Name()
+}
@@ -0,0 +1,241 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package reconsiler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	promv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monitoringv1alpha1 "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+	promodel "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/controller/prometheusext/model"
+)
+
+func TestIsSubset(t *testing.T) {
+	tests := []struct {
+		name    string
+		desired interface{}
+		live    interface{}
+		want    bool
+	}{
+		{name: "nil desired", desired: nil, live: "value", want: true},
+		{name: "same scalar", desired: "a", live: "a", want: true},
+		{name: "different scalar", desired: "a", live: "b", want: false},
+		{name: "number types differ", desired: int64(1), live: float64(1), want: false},
+		{name: "extra live keys",
+			desired: map[string]interface{}{"a": "1"},
+			live:    map[string]interface{}{"a": "1", "b": "2"},
+			want:    true},
+		{name: "missing live key",
+			desired: map[string]interface{}{"a": "1"},
+			live:    map[string]interface{}{"b": "2"},
+			want:    false},
+		{name: "empty map and no live value", desired: map[string]interface{}{}, live: nil, want: true},
+		{name: "map and no live value", desired: map[string]interface{}{"a": "1"}, live: nil, want: false},
+		{name: "nested map",
+			desired: map[string]interface{}{"a": map[string]interface{}{"b": "1"}},
+			live:    map[string]interface{}{"a": map[string]interface{}{"b": "1", "c": "2"}},
+			want:    true},
+		{name: "defaulted fields of list items",
+			desired: []interface{}{map[string]interface{}{"name": "c"}},
+			live:    []interface{}{map[string]interface{}{"name": "c", "imagePullPolicy": "Always"}},
+			want:    true},
+		{name: "longer desired list",
+			desired: []interface{}{"a", "b"},
+			live:    []interface{}{"a"},
+			want:    false},
+		{name: "list item changed",
+			desired: []interface{}{"a", "b"},
+			live:    []interface{}{"a", "c"},
+			want:    false},
+		{name: "empty list and no live value", desired: []interface{}{}, live: nil, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSubset(tt.desired, tt.live); got != tt.want {
+				t.Errorf("isSubset() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObjectChanged(t *testing.T) {
+	configMap := func(data map[string]string, labels map[string]string) *v1.ConfigMap {
+		return &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "ns", Labels: labels},
+			Data:       data,
+		}
+	}
+	var missing *v1.ConfigMap
+	tests := []struct {
+		name    string
+		live    runtime.Object
+		desired runtime.Object
+		want    bool
+	}{
+		{name: "no live object",
+			live:    nil,
+			desired: configMap(map[string]string{"a": "1"}, nil),
+			want:    true},
+		{name: "typed nil live object",
+			live:    missing,
+			desired: configMap(map[string]string{"a": "1"}, nil),
+			want:    true},
+		{name: "same object",
+			live:    configMap(map[string]string{"a": "1"}, map[string]string{"app": "x"}),
+			desired: configMap(map[string]string{"a": "1"}, map[string]string{"app": "x"}),
+			want:    false},
+		{name: "fields added by others",
+			live:    configMap(map[string]string{"a": "1", "b": "2"}, map[string]string{"app": "x", "team": "y"}),
+			desired: configMap(map[string]string{"a": "1"}, map[string]string{"app": "x"}),
+			want:    false},
+		{name: "data changed",
+			live:    configMap(map[string]string{"a": "1"}, nil),
+			desired: configMap(map[string]string{"a": "2"}, nil),
+			want:    true},
+		{name: "label added",
+			live:    configMap(map[string]string{"a": "1"}, nil),
+			desired: configMap(map[string]string{"a": "1"}, map[string]string{"app": "x"}),
+			want:    true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desired := tt.desired.DeepCopyObject()
+			desired.GetObjectKind().SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("ConfigMap"))
+			got, err := objectChanged(tt.live, desired)
+			if err != nil {
+				t.Fatalf("objectChanged() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("objectChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+//patchClient records options of patch requests and returns err
+type patchClient struct {
+	client.Client
+	err   error
+	force []bool
+}
+
+func (c *patchClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	po := (&client.PatchOptions{}).ApplyOptions(opts)
+	c.force = append(c.force, po.Force != nil && *po.Force)
+	return c.err
+}
+
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := promv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := monitoringv1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return scheme
+}
+
+func newTestReconsiler(t *testing.T, c client.Client) *Reconsiler {
+	return &Reconsiler{
+		CR:           &monitoringv1alpha1.PrometheusExt{ObjectMeta: metav1.ObjectMeta{Name: "monitoring", Namespace: "ns", UID: "uid"}},
+		CurrentState: &ClusterState{},
+		Schema:       newTestScheme(t),
+		Context:      context.TODO(),
+		Client:       c,
+	}
+}
+
+func TestApplyObjectForce(t *testing.T) {
+	desired := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "ns"},
+		Data:       map[string]string{"a": "2"},
+	}
+	legacy := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "ns", ManagedFields: []metav1.ManagedFieldsEntry{
+			{Manager: "ibm-monitoring-prometheusext-operator-update", Operation: metav1.ManagedFieldsOperationUpdate},
+		}},
+		Data: map[string]string{"a": "1"},
+	}
+	applied := legacy.DeepCopy()
+	applied.ManagedFields = append(applied.ManagedFields, metav1.ManagedFieldsEntry{Manager: promodel.FieldManager, Operation: metav1.ManagedFieldsOperationApply})
+	var missing *v1.ConfigMap
+	tests := []struct {
+		name      string
+		live      runtime.Object
+		wantForce bool
+	}{
+		{name: "new object", live: missing, wantForce: false},
+		{name: "object never applied by operator", live: legacy, wantForce: true},
+		{name: "object applied by operator", live: applied, wantForce: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &patchClient{}
+			r := newTestReconsiler(t, c)
+			if err := r.applyObject(tt.live, desired.DeepCopy()); err != nil {
+				t.Fatalf("applyObject() error = %v", err)
+			}
+			if len(c.force) != 1 || c.force[0] != tt.wantForce {
+				t.Errorf("force of patch requests = %v, want [%v]", c.force, tt.wantForce)
+			}
+		})
+	}
+}
+
+func TestApplyObjectConflict(t *testing.T) {
+	live := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "ns", ManagedFields: []metav1.ManagedFieldsEntry{
+			{Manager: promodel.FieldManager, Operation: metav1.ManagedFieldsOperationApply},
+		}},
+		Data: map[string]string{"a": "1"},
+	}
+	desired := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "ns"},
+		Data:       map[string]string{"a": "2"},
+	}
+	conflict := kerrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "cm", fmt.Errorf(`conflict with "kubectl": .data.a`))
+	r := newTestReconsiler(t, &patchClient{err: conflict})
+
+	err := r.applyObject(live, desired)
+	if !promodel.IsRequeueErr(err) {
+		t.Fatalf("applyObject() error = %v, want requeue error", err)
+	}
+	r.updateConditions(err)
+	var degraded *monitoringv1alpha1.PrometheusExtCondition
+	for i := range r.CR.Status.Conditions {
+		if r.CR.Status.Conditions[i].Type == monitoringv1alpha1.ConditionDegraded {
+			degraded = &r.CR.Status.Conditions[i]
+		}
+	}
+	if degraded == nil || degraded.Status != v1.ConditionTrue || degraded.Reason != reasonFieldConflict {
+		t.Errorf("Degraded condition = %+v, want True with reason %s", degraded, reasonFieldConflict)
+	}
+}
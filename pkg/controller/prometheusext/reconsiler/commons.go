@@ -19,67 +19,91 @@ package reconsiler
 import (
 	"fmt"
 
+	certv1alpha1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
 	v1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	monitoringv1alpha1 "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
 	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/controller/prometheusext/model"
 )
 
 func (r *Reconsiler) syncSecrets() error {
-	if err := r.syncSecret(r.CurrentState.MonitoringSecret, r.CR.Spec.Certs.MonitoringSecret, model.MonitoringDNSNames(r.CR)); err != nil {
+	if model.IsSelfManagedCerts(r.CR) {
+		return r.syncSelfManagedSecrets()
+	}
+	if err := r.syncSecret(r.CurrentState.MonitoringSecret, r.CR.Spec.Certs.MonitoringSecret, model.MonitoringDNSNames(r.CR), model.MonitoringIPAddresses(r.CR)); err != nil {
 		return err
 	}
 	log.Info("monitoring certificate is sync")
-	if err := r.syncSecret(r.CurrentState.MonitoringClientSecret, r.CR.Spec.Certs.MonitoringClientSecret, []string{}); err != nil {
+	if err := r.syncSecret(r.CurrentState.MonitoringClientSecret, r.CR.Spec.Certs.MonitoringClientSecret, []string{}, []string{}); err != nil {
 		return err
 	}
 	log.Info("monitoring client certificate is sync")
 	return nil
 }
-func (r *Reconsiler) syncSecret(currentSecret *v1.Secret, secretName string, dnsNames []string) error {
-	cert := model.NewCertitication(secretName, r.CR, dnsNames)
-	if currentSecret != nil {
-		if r.CR.Spec.Certs.AutoClean {
-			key := client.ObjectKey{Name: cert.Name, Namespace: cert.Namespace}
-			if err := r.Client.Get(r.Context, key, cert); err != nil {
-				if kerrors.IsNotFound(err) {
-					//secret exists but no certicate
-					//delete the secret and create new one
-					log.Info("deleting tls secret" + secretName + "which is old and out of control")
-					if err = r.Client.Delete(r.Context, currentSecret); err != nil {
-						log.Error(err, "failed to delete old tls secret: "+secretName)
-						return err
-					}
-
-				} else {
-					//failed to get certificate because other errors
-					log.Error(err, "failed to get certification object: "+secretName)
-					return err
-
-				}
-			} else {
+func (r *Reconsiler) syncSecret(currentSecret *v1.Secret, secretName string, dnsNames []string, ipAddresses []string) error {
+	cert := &certv1alpha1.Certificate{}
+	key := client.ObjectKey{Name: secretName, Namespace: r.CR.Namespace}
+	if err := r.Client.Get(r.Context, key, cert); err != nil {
+		if meta.IsNoMatchError(err) {
+			if currentSecret != nil && !r.CR.Spec.Certs.AutoClean {
+				//secret is provided by user and kept as it is, so cert-manager is not needed
+				log.Info("cert secret exists and cert-manager is not installed: " + secretName)
 				return nil
 			}
+			err = fmt.Errorf("cert-manager is not installed in the cluster, install it or set certs.mode to %s: %v", monitoringv1alpha1.CertsModeSelfManaged, err)
+			log.Error(err, "failed to get certification object: "+secretName)
+			return err
+		}
+		if !kerrors.IsNotFound(err) {
+			//failed to get certificate because other errors
+			log.Error(err, "failed to get certification object: "+secretName)
+			return err
+		}
+		cert = nil
+	}
 
-		} else {
+	if cert != nil {
+		//certificate is created already, keep it same as CR
+		updatedCert := model.NewCertitication(secretName, r.CR, dnsNames, ipAddresses)
+		if err := r.applyObject(cert, updatedCert); err != nil {
+			log.Error(err, "failed to update certificate: "+secretName)
+			return err
+		}
+		if currentSecret == nil {
+			r.normalEvent(eventReasonCertificatePending, "waiting for secret "+secretName+" to be created by certificate")
+			return model.NewRequeueError(model.RequeueWaitingForDependency, "syncCertSecret", "wait for cert secret to be created by certificate object")
+		}
+		return nil
+	}
+
+	if currentSecret != nil {
+		if !r.CR.Spec.Certs.AutoClean {
 			// when it is not autoclean keep secret no matter who created it
-			log.Info("exporter cert secret exists")
+			log.Info("cert secret exists: " + secretName)
 			return nil
 		}
+		//secret exists but no certicate
+		//delete the secret and create new one
+		log.Info("deleting tls secret" + secretName + "which is old and out of control")
+		if err := r.Client.Delete(r.Context, currentSecret); err != nil {
+			log.Error(err, "failed to delete old tls secret: "+secretName)
+			return err
+		}
 	}
 
-	if err := r.createObject(cert); err != nil {
-		if kerrors.IsAlreadyExists(err) {
-			log.Info("certificate object already exists.")
-			return model.NewRequeueError("syncCertSecret", "wait for cert secret to be created after creating certificate object")
-		}
+	cert = model.NewCertitication(secretName, r.CR, dnsNames, ipAddresses)
+	if err := r.applyObject(nil, cert); err != nil {
 		log.Error(err, "failed to create certificate")
 		return err
 	}
 	// We can not verify if secret is created or not for now so return to next loop
-	return model.NewRequeueError("syncCertSecret", "wait for cert secret to be created after creating certification object")
+	r.normalEvent(eventReasonCertificatePending, "waiting for secret "+secretName+" to be created by certificate")
+	return model.NewRequeueError(model.RequeueWaitingForDependency, "syncCertSecret", "wait for cert secret to be created after creating certification object")
 
 }
 func (r *Reconsiler) syncRouterCms() error {
@@ -107,120 +131,63 @@ func (r *Reconsiler) syncRouterCms() error {
 }
 
 func (r *Reconsiler) syncProRouterNgCm() error {
-	if r.CurrentState.PromeNgCm == nil {
-		cm, err := model.NewProRouterNgCm(r.CR)
-		if err != nil {
-			return err
-		}
-		if err = r.createObject(cm); err != nil {
-			log.Error(err, "failed to create prometheus router nginx configmap")
-			return err
-		}
-	} else {
-		cm, err := model.UpdatedProRouterNgCm(r.CR, r.CurrentState.PromeNgCm)
-		if err != nil {
-			return err
-		}
-		if err = r.updateObject(cm); err != nil {
-			return err
-		}
+	cm, err := model.NewProRouterNgCm(r.CR)
+	if err != nil {
+		return err
 	}
+	if err = r.applyObject(r.CurrentState.PromeNgCm, cm); err != nil {
+		log.Error(err, "failed to apply prometheus router nginx configmap")
+		return err
+	}
+	r.CurrentState.PromeNgCm = cm
 	return nil
 }
 func (r *Reconsiler) syncAlertRouterNgCm() error {
-	if r.CurrentState.AlertNgCm == nil {
-		cm := model.NewAlertmanagerRouterNgCm(r.CR)
-		if err := r.createObject(cm); err != nil {
-			log.Error(err, "failed to create configmap for alertmanager router nginx config in cluster")
-			return err
-		}
-	} else {
-		cm := model.UpdatedAlertRouterNgcm(r.CR, r.CurrentState.AlertNgCm)
-		if err := r.updateObject(cm); err != nil {
-			log.Error(err, "failed to update configmap for alertmanager router nginx config in cluster")
-			return err
-		}
-
+	cm := model.NewAlertmanagerRouterNgCm(r.CR)
+	if err := r.applyObject(r.CurrentState.AlertNgCm, cm); err != nil {
+		log.Error(err, "failed to apply configmap for alertmanager router nginx config in cluster")
+		return err
 	}
+	r.CurrentState.AlertNgCm = cm
 	return nil
 }
 func (r *Reconsiler) syncProLuaCm() error {
-	if r.CurrentState.ProLuaCm == nil {
-		cm, err := model.NewProLuaCm(r.CR)
-
-		if err != nil {
-			log.Error(err, "failed to create configmpa object for prometheus lua script")
-			return err
-		}
-		if err = r.createObject(cm); err != nil {
-			log.Error(err, "failed to create configmap in kubernetes for prometheus lua script")
-			return err
-		}
-
-	} else {
-		cm, err := model.UpdatedProLuaCm(r.CR, r.CurrentState.ProLuaCm)
-		if err != nil {
-			log.Error(err, "failed to update onfigmpa object for prometheus lua script")
-			return err
-		}
-		if err = r.updateObject(cm); err != nil {
-			log.Error(err, "failed to update configmap in kubernetes for prometheus lua script")
-			return err
-		}
-
+	cm, err := model.NewProLuaCm(r.CR)
+	if err != nil {
+		log.Error(err, "failed to create configmpa object for prometheus lua script")
+		return err
+	}
+	if err = r.applyObject(r.CurrentState.ProLuaCm, cm); err != nil {
+		log.Error(err, "failed to apply configmap in kubernetes for prometheus lua script")
+		return err
 	}
+	r.CurrentState.ProLuaCm = cm
 	return nil
 }
 func (r *Reconsiler) syncProLuaUtilsCm() error {
-	if r.CurrentState.ProLuaUtilsCm == nil {
-		cm, err := model.NewProLuaUtilsCm(r.CR)
-		if err != nil {
-			log.Error(err, "failed to create configmap for prometheus lua utils")
-			return err
-		}
-		if err = r.createObject(cm); err != nil {
-			log.Error(err, "failed to create prometheus lua script configmap in kubernets")
-			return err
-		}
-	} else {
-		cm, err := model.UpdatedProLuaUtilsCm(r.CR, r.CurrentState.ProLuaUtilsCm)
-		if err != nil {
-			log.Error(err, "failed to create updated configmap for prometheus lua utils script")
-			return err
-		}
-		if err = r.updateObject(cm); err != nil {
-			log.Error(err, "failed to update configmap in kubernetes for prometheus lua utils script")
-			return err
-		}
+	cm, err := model.NewProLuaUtilsCm(r.CR)
+	if err != nil {
+		log.Error(err, "failed to create configmap for prometheus lua utils")
+		return err
 	}
-
+	if err = r.applyObject(r.CurrentState.ProLuaUtilsCm, cm); err != nil {
+		log.Error(err, "failed to apply configmap in kubernetes for prometheus lua utils script")
+		return err
+	}
+	r.CurrentState.ProLuaUtilsCm = cm
 	return nil
 }
 func (r *Reconsiler) syncRouterEntryCm() error {
-
-	if r.CurrentState.RouterEntryCm == nil {
-		cm, err := model.NewRouterEntryCm(r.CR)
-		if err != nil {
-			log.Error(err, "failed to create configmap for router entrypoint")
-			return err
-		}
-
-		if err = r.createObject(cm); err != nil {
-			log.Error(err, "failed to create configmap for router entrypoint in kubernestes")
-			return err
-		}
-
-	} else {
-		cm, err := model.UpdatedRouterEntryCm(r.CR, r.CurrentState.RouterEntryCm)
-		if err != nil {
-			return err
-		}
-		if err = r.updateObject(cm); err != nil {
-			log.Error(err, "failed to update configmap for router entrypoint in kubernestes")
-			return err
-		}
-
+	cm, err := model.NewRouterEntryCm(r.CR)
+	if err != nil {
+		log.Error(err, "failed to create configmap for router entrypoint")
+		return err
 	}
+	if err = r.applyObject(r.CurrentState.RouterEntryCm, cm); err != nil {
+		log.Error(err, "failed to apply configmap for router entrypoint in kubernestes")
+		return err
+	}
+	r.CurrentState.RouterEntryCm = cm
 	return nil
 }
 func (r *Reconsiler) syncStorageClass() error {
@@ -228,6 +195,11 @@ func (r *Reconsiler) syncStorageClass() error {
 	if err != nil {
 		return err
 	}
+	changed := r.CR.Annotations[model.StorageClassAnn] != scName
+	recordObjectUpdate(objectKind(r.CR), r.CR.Name, changed)
+	if !changed {
+		return nil
+	}
 	if r.CR.Annotations == nil {
 		r.CR.Annotations = make(map[string]string)
 
@@ -245,6 +217,19 @@ func (r *Reconsiler) syncClusterHostInfo() error {
 		log.Error(err, "failed to get cluster host and port")
 		return err
 	}
+	if host == "" {
+		//host comes from address of prometheus ingress or route, so expose prometheus before waiting for it
+		if err := r.syncExposure(model.Prometheus, r.CurrentState.PromeIngress, r.CurrentState.PromeRoute); err != nil {
+			return err
+		}
+		return model.NewRequeueErrorWithDetail(model.RequeueWaitingForDependency, "clusterInfo", "wait for cluster host",
+			"set clusterAddress or wait for address of prometheus ingress or route")
+	}
+	changed := r.CR.Annotations[model.ClusterHostAnn] != host || r.CR.Annotations[model.ClusterPortAnn] != port
+	recordObjectUpdate(objectKind(r.CR), r.CR.Name, changed)
+	if !changed {
+		return nil
+	}
 	if r.CR.Annotations == nil {
 		r.CR.Annotations = make(map[string]string)
 	}
@@ -269,11 +254,36 @@ func (r *Reconsiler) getClusterInfo() (string, string, error) {
 	//get cluster host url from ingress configmap
 	key := client.ObjectKey{Namespace: r.CR.ObjectMeta.Namespace, Name: model.ManagedIngressCm}
 	cm := v1.ConfigMap{}
-	if err := r.Client.Get(r.Context, key, &cm); err != nil {
+	err := r.Client.Get(r.Context, key, &cm)
+	if err == nil {
+		host = cm.Data["MANAGEMENT_INGRESS_ROUTE_HOST"]
+		return host, port, nil
+	}
+	if !kerrors.IsNotFound(err) {
 		return host, port, err
 	}
-	host = cm.Data["MANAGEMENT_INGRESS_ROUTE_HOST"]
-
+	//configmap exists only in IBM Cloud Pak. Otherwise use address in CR, host of prometheus route or address of prometheus ingress
+	if r.CR.Spec.ClusterAddress != "" {
+		return r.CR.Spec.ClusterAddress, port, nil
+	}
+	if route := r.CurrentState.PromeRoute; route != nil && route.Spec.Host != "" {
+		return route.Spec.Host, port, nil
+	}
+	if ingress := r.CurrentState.PromeIngress; ingress != nil {
+		lbs, _, _ := unstructured.NestedSlice(ingress.Object, "status", "loadBalancer", "ingress")
+		for _, lb := range lbs {
+			lbMap, ok := lb.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if hostname, _, _ := unstructured.NestedString(lbMap, "hostname"); hostname != "" {
+				return hostname, port, nil
+			}
+			if ip, _, _ := unstructured.NestedString(lbMap, "ip"); ip != "" {
+				return ip, port, nil
+			}
+		}
+	}
 	return host, port, nil
 }
 
@@ -283,20 +293,25 @@ func (r *Reconsiler) getStorageClass() (string, error) {
 
 	}
 
-	ann, ok := r.CR.Annotations[model.StorageClassAnn]
-	if ok && ann != "" {
-		return ann, nil
-	}
-
 	scList := &storagev1.StorageClassList{}
 	err := r.Client.List(r.Context, scList)
 	if err != nil {
 		log.Error(err, "failed to get storage class list")
 		return "", err
 	}
+	//keep storage class selected before as long as it exists
+	if ann := r.CR.Annotations[model.StorageClassAnn]; ann != "" {
+		for _, sc := range scList.Items {
+			if sc.GetName() == ann {
+				return ann, nil
+			}
+		}
+		log.Info("storage class " + ann + " does not exist any more, select it again")
+	}
 	if len(scList.Items) == 0 {
 		err := fmt.Errorf("could not find storage class in the cluster")
 		log.Error(err, "")
+		r.warningEvent(eventReasonStorageClassNotFound, err.Error())
 		return "", err
 	}
 
@@ -325,6 +340,43 @@ func (r *Reconsiler) getStorageClass() (string, error) {
 
 	err = fmt.Errorf("could not find dynamic provisioner storage class in the cluster")
 	log.Error(err, "")
+	r.warningEvent(eventReasonStorageClassNotFound, err.Error())
 
 	return "", err
 }
+
+//prometheusConfigHash returns hash of secrets and configmaps mounted by prometheus pods
+func (r *Reconsiler) prometheusConfigHash() string {
+	secrets := []*v1.Secret{r.CurrentState.MonitoringSecret, r.CurrentState.MonitoringClientSecret}
+	for _, name := range model.RemoteStorageSecrets(r.CR) {
+		secrets = append(secrets, r.CurrentState.RemoteStorageSecrets[name])
+	}
+	return model.ConfigHash(
+		secrets,
+		[]*v1.ConfigMap{r.CurrentState.PromeNgCm, r.CurrentState.RouterEntryCm, r.CurrentState.ProLuaCm, r.CurrentState.ProLuaUtilsCm})
+}
+
+//alertmanagerConfigHash returns hash of secrets and configmaps mounted by alertmanager pods
+func (r *Reconsiler) alertmanagerConfigHash() string {
+	return model.ConfigHash(
+		[]*v1.Secret{r.CurrentState.MonitoringSecret, r.CurrentState.MonitoringClientSecret},
+		[]*v1.ConfigMap{r.CurrentState.RouterEntryCm, r.CurrentState.AlertNgCm})
+}
+
+//mcmCtlConfigHash returns hash of secrets mounted by mcm controller pods
+func (r *Reconsiler) mcmCtlConfigHash() string {
+	return model.ConfigHash(
+		[]*v1.Secret{r.CurrentState.MonitoringSecret, r.CurrentState.MonitoringClientSecret},
+		[]*v1.ConfigMap{})
+}
+
+//recordConfigHash saves hash applied to pods of component in status of CR
+func (r *Reconsiler) recordConfigHash(component string, hash string) {
+	if r.CR.Status.ConfigHashes == nil {
+		r.CR.Status.ConfigHashes = make(map[string]string)
+	}
+	if r.CR.Status.ConfigHashes[component] != hash {
+		log.Info("config hash of " + component + " pods is changed to " + hash)
+	}
+	r.CR.Status.ConfigHashes[component] = hash
+}
@@ -0,0 +1,67 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package reconsiler
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+//noCertManagerClient fails like API server without cert-manager CRDs
+type noCertManagerClient struct {
+	client.Client
+}
+
+func (c *noCertManagerClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	return &meta.NoKindMatchError{}
+}
+
+func TestSyncSecretWithoutCertManager(t *testing.T) {
+	secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "monitoring-certs", Namespace: "ns"}}
+	tests := []struct {
+		name      string
+		secret    *v1.Secret
+		autoClean bool
+		wantErr   bool
+	}{
+		{name: "user provided secret", secret: secret},
+		{name: "no secret", secret: nil, wantErr: true},
+		{name: "secret is cleaned", secret: secret, autoClean: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &noCertManagerClient{Client: fake.NewFakeClientWithScheme(newTestScheme(t), secret.DeepCopy())}
+			r := newTestReconsiler(t, c)
+			r.CR.Spec.Certs.AutoClean = tt.autoClean
+			err := r.syncSecret(tt.secret, secret.Name, []string{}, []string{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("syncSecret() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			stored := &v1.Secret{}
+			if err := c.Client.Get(context.TODO(), client.ObjectKey{Namespace: "ns", Name: secret.Name}, stored); err != nil {
+				t.Errorf("secret is deleted: %v", err)
+			}
+		})
+	}
+}
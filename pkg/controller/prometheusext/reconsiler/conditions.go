@@ -0,0 +1,236 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package reconsiler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	monitoringv1alpha1 "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+	promodel "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/controller/prometheusext/model"
+)
+
+const (
+	reasonReady             = "Ready"
+	reasonNotCreated        = "NotCreated"
+	reasonUnavailable       = "Unavailable"
+	reasonSyncFailed        = "SyncFailed"
+	reasonWaiting           = "Waiting"
+	reasonNotReconciled     = "NotReconciled"
+	reasonReconciled        = "Reconciled"
+	reasonReconcileFailed   = "ReconcileFailed"
+	reasonComponentNotReady = "ComponentsNotReady"
+	reasonAsExpected        = "AsExpected"
+	reasonFieldConflict     = "FieldConflict"
+	reasonInvalidSpec       = "InvalidSpec"
+	reasonPaused            = "Paused"
+)
+
+// componentConditionTypes lists component conditions in the order they are reported
+var componentConditionTypes = []monitoringv1alpha1.ConditionType{
+	monitoringv1alpha1.ConditionPrometheusOperator,
+	monitoringv1alpha1.ConditionCertificates,
+	monitoringv1alpha1.ConditionRouter,
+	monitoringv1alpha1.ConditionPrometheus,
+	monitoringv1alpha1.ConditionAlertmanager,
+	monitoringv1alpha1.ConditionMCMController,
+}
+
+//updateConditions sets observedGeneration and all conditions from cluster state and error returned by sync steps
+func (r *Reconsiler) updateConditions(syncErr error) {
+	var notReady []string
+	for _, t := range componentConditionTypes {
+		c := r.componentCondition(t, syncErr)
+		if c.Status != v1.ConditionTrue {
+			notReady = append(notReady, string(t))
+		}
+		recordComponentReady(r.CR.Namespace, r.CR.Name, string(t), c.Status == v1.ConditionTrue)
+		r.setCondition(c)
+	}
+
+	paused := promodel.IsPaused(r.CR)
+	if syncErr == nil && !paused {
+		r.CR.Status.ObservedGeneration = r.CR.Generation
+	}
+
+	degraded := newCondition(monitoringv1alpha1.ConditionDegraded, v1.ConditionFalse, reasonAsExpected, "")
+	if promodel.IsPermanentErr(syncErr) {
+		degraded = newCondition(monitoringv1alpha1.ConditionDegraded, v1.ConditionTrue, reasonInvalidSpec, syncErr.Error())
+	} else if syncErr != nil && !promodel.IsRequeueErr(syncErr) {
+		degraded = newCondition(monitoringv1alpha1.ConditionDegraded, v1.ConditionTrue, reasonReconcileFailed, syncErr.Error())
+	} else if len(r.fieldConflicts) != 0 {
+		degraded = newCondition(monitoringv1alpha1.ConditionDegraded, v1.ConditionTrue, reasonFieldConflict,
+			"fields managed by others are not applied: "+strings.Join(r.fieldConflicts, "; "))
+	}
+	r.setCondition(degraded)
+
+	progressing := newCondition(monitoringv1alpha1.ConditionProgressing, v1.ConditionFalse, reasonReconciled, "")
+	switch {
+	case paused:
+		progressing = newCondition(monitoringv1alpha1.ConditionProgressing, v1.ConditionFalse, reasonPaused,
+			"reconciliation is paused by annotation "+promodel.PausedAnn)
+	case r.CR.Status.Waiting != nil:
+		waiting := r.CR.Status.Waiting
+		message := fmt.Sprintf("waiting for %s since %s", waiting.Reason, waiting.Since.UTC().Format(time.RFC3339))
+		if requeueErr, ok := syncErr.(promodel.IReqeueError); ok && requeueErr.Detail() != "" {
+			message += ": " + requeueErr.Detail()
+		}
+		progressing = newCondition(monitoringv1alpha1.ConditionProgressing, v1.ConditionTrue, reasonWaiting, message)
+	case r.CR.Status.ObservedGeneration != r.CR.Generation:
+		progressing = newCondition(monitoringv1alpha1.ConditionProgressing, v1.ConditionTrue, reasonNotReconciled,
+			fmt.Sprintf("generation %d is not reconciled yet", r.CR.Generation))
+	case len(notReady) != 0:
+		progressing = newCondition(monitoringv1alpha1.ConditionProgressing, v1.ConditionTrue, reasonComponentNotReady,
+			"waiting for: "+strings.Join(notReady, ", "))
+	}
+	r.setCondition(progressing)
+
+	available := newCondition(monitoringv1alpha1.ConditionAvailable, v1.ConditionTrue, reasonReady, "all components are ready")
+	switch {
+	case len(notReady) != 0:
+		available = newCondition(monitoringv1alpha1.ConditionAvailable, v1.ConditionFalse, reasonComponentNotReady,
+			"components not ready: "+strings.Join(notReady, ", "))
+	case r.CR.Status.ObservedGeneration != r.CR.Generation:
+		available = newCondition(monitoringv1alpha1.ConditionAvailable, v1.ConditionFalse, reasonNotReconciled,
+			fmt.Sprintf("generation %d is not reconciled yet", r.CR.Generation))
+	}
+	r.setCondition(available)
+}
+
+//componentCondition returns condition of one component.
+//Error of the sync step of the component has higher priority than state read from cluster
+func (r *Reconsiler) componentCondition(t monitoringv1alpha1.ConditionType, syncErr error) monitoringv1alpha1.PrometheusExtCondition {
+	if syncErr != nil && r.failedComponent == t {
+		if promodel.IsRequeueErr(syncErr) {
+			return newCondition(t, v1.ConditionFalse, reasonWaiting, syncErr.Error())
+		}
+		return newCondition(t, v1.ConditionFalse, reasonSyncFailed, syncErr.Error())
+	}
+	state := r.CurrentState
+	switch t {
+	case monitoringv1alpha1.ConditionPrometheusOperator:
+		return deploymentCondition(t, state.PrometheusOperatorDeployment)
+	case monitoringv1alpha1.ConditionMCMController:
+		return deploymentCondition(t, state.MCMCtrlDeployment)
+	case monitoringv1alpha1.ConditionPrometheus:
+		if state.ManagedPrometheus == nil {
+			return newCondition(t, v1.ConditionFalse, reasonNotCreated, "prometheus "+promodel.PromethuesName(r.CR)+" is not created")
+		}
+		return statefulSetCondition(t, state.PrometheusStatefulSet)
+	case monitoringv1alpha1.ConditionAlertmanager:
+		if state.ManagedAlertmanager == nil {
+			return newCondition(t, v1.ConditionFalse, reasonNotCreated, "alertmanager "+promodel.AlertmanagerName(r.CR)+" is not created")
+		}
+		return statefulSetCondition(t, state.AlertmanagerStatefulSet)
+	case monitoringv1alpha1.ConditionRouter:
+		return objectsCondition(t, map[string]bool{
+			promodel.ProRouterNgCmName(r.CR):   state.PromeNgCm != nil,
+			promodel.RouterEntryCmName(r.CR):   state.RouterEntryCm != nil,
+			promodel.ProLuaUtilsCmName(r.CR):   state.ProLuaUtilsCm != nil,
+			promodel.ProLuaCmName(r.CR):        state.ProLuaCm != nil,
+			promodel.AlertRouterNgCmName(r.CR): state.AlertNgCm != nil,
+		})
+	case monitoringv1alpha1.ConditionCertificates:
+		return objectsCondition(t, map[string]bool{
+			r.CR.Spec.Certs.MonitoringSecret:       state.MonitoringSecret != nil,
+			r.CR.Spec.Certs.MonitoringClientSecret: state.MonitoringClientSecret != nil,
+		})
+	}
+	return newCondition(t, v1.ConditionUnknown, "", "")
+}
+
+func deploymentCondition(t monitoringv1alpha1.ConditionType, deployment *appsv1.Deployment) monitoringv1alpha1.PrometheusExtCondition {
+	if deployment == nil {
+		return newCondition(t, v1.ConditionFalse, reasonNotCreated, "deployment is not created")
+	}
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	if deployment.Status.ObservedGeneration < deployment.Generation ||
+		deployment.Status.UpdatedReplicas < replicas ||
+		deployment.Status.AvailableReplicas < replicas {
+		return newCondition(t, v1.ConditionFalse, reasonUnavailable,
+			fmt.Sprintf("deployment %s: %d of %d updated replicas are available", deployment.Name, deployment.Status.AvailableReplicas, replicas))
+	}
+	return newCondition(t, v1.ConditionTrue, reasonReady, fmt.Sprintf("deployment %s is available", deployment.Name))
+}
+
+func statefulSetCondition(t monitoringv1alpha1.ConditionType, sts *appsv1.StatefulSet) monitoringv1alpha1.PrometheusExtCondition {
+	if sts == nil {
+		return newCondition(t, v1.ConditionFalse, reasonNotCreated, "statefulset is not created by prometheus operator yet")
+	}
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+	if sts.Status.ObservedGeneration < sts.Generation ||
+		sts.Status.ReadyReplicas < replicas ||
+		(sts.Status.UpdateRevision != "" && sts.Status.CurrentRevision != sts.Status.UpdateRevision) {
+		return newCondition(t, v1.ConditionFalse, reasonUnavailable,
+			fmt.Sprintf("statefulset %s: %d of %d replicas are ready", sts.Name, sts.Status.ReadyReplicas, replicas))
+	}
+	return newCondition(t, v1.ConditionTrue, reasonReady, fmt.Sprintf("statefulset %s is ready", sts.Name))
+}
+
+func objectsCondition(t monitoringv1alpha1.ConditionType, objects map[string]bool) monitoringv1alpha1.PrometheusExtCondition {
+	var missing []string
+	for name, ok := range objects {
+		if !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) != 0 {
+		sort.Strings(missing)
+		return newCondition(t, v1.ConditionFalse, reasonNotCreated, "not created: "+strings.Join(missing, " "))
+	}
+	return newCondition(t, v1.ConditionTrue, reasonReady, "")
+}
+
+func newCondition(t monitoringv1alpha1.ConditionType, status v1.ConditionStatus, reason string, message string) monitoringv1alpha1.PrometheusExtCondition {
+	return monitoringv1alpha1.PrometheusExtCondition{
+		Type:    t,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+//setCondition adds or replaces condition with same type. LastTransitionTime changes only when status changes
+func (r *Reconsiler) setCondition(c monitoringv1alpha1.PrometheusExtCondition) {
+	conditions := r.CR.Status.Conditions
+	for i := range conditions {
+		if conditions[i].Type != c.Type {
+			continue
+		}
+		if conditions[i].Status == c.Status {
+			c.LastTransitionTime = conditions[i].LastTransitionTime
+		} else {
+			c.LastTransitionTime = metav1.Now()
+		}
+		conditions[i] = c
+		return
+	}
+	c.LastTransitionTime = metav1.Now()
+	r.CR.Status.Conditions = append(conditions, c)
+}
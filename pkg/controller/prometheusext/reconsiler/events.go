@@ -0,0 +1,111 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package reconsiler
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// Reasons of events recorded for PrometheusExt
+const (
+	eventReasonCreated              = "Created"
+	eventReasonUpdated              = "Updated"
+	eventReasonDeleted              = "Deleted"
+	eventReasonCertificatePending   = "CertificatePending"
+	eventReasonStorageClassNotFound = "StorageClassNotFound"
+	eventReasonUpdateConflict       = "UpdateConflict"
+	eventReasonSyncFailed           = "SyncFailed"
+	eventReasonPaused               = "Paused"
+	eventReasonMaintenanceStarted   = "MaintenanceStarted"
+	eventReasonMaintenanceEnded     = "MaintenanceEnded"
+	eventReasonInvalidMaintenance   = "InvalidMaintenance"
+	eventReasonPodSecurityLevel     = "PodSecurityLevel"
+	eventReasonRemoteWriteUnhealthy = "RemoteWriteUnhealthy"
+	eventReasonRemoteWriteHealthy   = "RemoteWriteHealthy"
+)
+
+//DefaultEventInterval is the minimal interval between identical events of same object
+const DefaultEventInterval = 5 * time.Minute
+
+//EventRecorder records events of PrometheusExt. Identical events of same object
+//are recorded only once in every interval, so waiting loops do not flood events
+type EventRecorder struct {
+	recorder record.EventRecorder
+	interval time.Duration
+	lock     sync.Mutex
+	recorded map[string]time.Time
+}
+
+//NewEventRecorder creates EventRecorder which sends events with recorder
+func NewEventRecorder(recorder record.EventRecorder, interval time.Duration) *EventRecorder {
+	return &EventRecorder{
+		recorder: recorder,
+		interval: interval,
+		recorded: make(map[string]time.Time),
+	}
+}
+
+//Event records event of obj if identical event is not recorded in last interval
+func (e *EventRecorder) Event(obj runtime.Object, eventtype, reason, message string) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		log.Error(err, "failed to record event "+reason)
+		return
+	}
+	key := fmt.Sprintf("%s/%s/%s/%s", accessor.GetUID(), eventtype, reason, message)
+	now := time.Now()
+
+	e.lock.Lock()
+	last, ok := e.recorded[key]
+	if ok && now.Sub(last) < e.interval {
+		e.lock.Unlock()
+		return
+	}
+	for k, t := range e.recorded {
+		if now.Sub(t) >= e.interval {
+			delete(e.recorded, k)
+		}
+	}
+	e.recorded[key] = now
+	e.lock.Unlock()
+
+	e.recorder.Event(obj, eventtype, reason, message)
+}
+
+//normalEvent records Normal event of CR
+func (r *Reconsiler) normalEvent(reason, message string) {
+	r.event(v1.EventTypeNormal, reason, message)
+}
+
+//warningEvent records Warning event of CR
+func (r *Reconsiler) warningEvent(reason, message string) {
+	r.event(v1.EventTypeWarning, reason, message)
+}
+
+func (r *Reconsiler) event(eventtype, reason, message string) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Event(r.CR, eventtype, reason, message)
+}
@@ -0,0 +1,182 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package reconsiler
+
+import (
+	"strings"
+
+	certv1alpha1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monitoringv1alpha1 "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/controller/prometheusext/model"
+)
+
+//syncFinalizer adds cleanup finalizer to CR
+func (r *Reconsiler) syncFinalizer() error {
+	if model.HasFinalizer(r.CR, model.CleanupFinalizer) {
+		return nil
+	}
+	r.CR.Finalizers = append(r.CR.Finalizers, model.CleanupFinalizer)
+	if err := r.Client.Update(r.Context, r.CR); err != nil {
+		log.Error(err, "failed to add finalizer to CR")
+		return err
+	}
+	return nil
+}
+
+// Finalize cleans up resources which are not garbage collected when CR is deleted and removes finalizer from CR
+func (r *Reconsiler) Finalize() error {
+	if !model.HasFinalizer(r.CR, model.CleanupFinalizer) {
+		return nil
+	}
+	others, err := r.otherInstances()
+	if err != nil {
+		return err
+	}
+	// SCC users and default prometheus rules are shared by all CRs in the namespace
+	if len(others) == 0 {
+		if model.IsOpenShift() {
+			if err := model.RemoveSCCUsers(r.SecClient, r.CR.Namespace); err != nil {
+				log.Error(err, "failed to remove users from SCC")
+				return err
+			}
+			log.Info("SCC is cleaned up")
+		}
+		if err := r.cleanupPrometheusRules(); err != nil {
+			return err
+		}
+		log.Info("default prometheus rules are cleaned up")
+	} else {
+		log.Info("SCC users and default prometheus rules are kept for PrometheusExt " + strings.Join(others, ","))
+	}
+	if r.CR.Spec.Certs.AutoClean {
+		if err := r.cleanupCerts(); err != nil {
+			return err
+		}
+		log.Info("certificates and tls secrets are cleaned up")
+	}
+	if !model.RetainStorage(r.CR) {
+		if err := r.cleanupPVCs(); err != nil {
+			return err
+		}
+		log.Info("persistent volume claims are cleaned up")
+	}
+	if err := r.cleanupAlertRoutesStatus(); err != nil {
+		return err
+	}
+	log.Info("status of AlertRoutes is cleaned up")
+	forgetCertExpiry(r.CR.Namespace, r.CR.Spec.Certs.MonitoringSecret, r.CR.Spec.Certs.MonitoringClientSecret, model.CASecretName(r.CR))
+	forgetCR(r.CR.Namespace, r.CR.Name)
+
+	model.RemoveFinalizer(r.CR, model.CleanupFinalizer)
+	if err := r.Client.Update(r.Context, r.CR); err != nil {
+		log.Error(err, "failed to remove finalizer from CR")
+		return err
+	}
+	return nil
+}
+
+//otherInstances returns names of other CRs in the namespace which are not being deleted
+func (r *Reconsiler) otherInstances() ([]string, error) {
+	crs := &monitoringv1alpha1.PrometheusExtList{}
+	if err := r.Client.List(r.Context, crs, client.InNamespace(r.CR.Namespace)); err != nil {
+		log.Error(err, "failed to list PrometheusExt CRs")
+		return nil, err
+	}
+	var names []string
+	for _, cr := range crs.Items {
+		if cr.Name != r.CR.Name && cr.DeletionTimestamp == nil {
+			names = append(names, cr.Name)
+		}
+	}
+	return names, nil
+}
+
+func (r *Reconsiler) cleanupCerts() error {
+	for _, name := range []string{r.CR.Spec.Certs.MonitoringSecret, r.CR.Spec.Certs.MonitoringClientSecret} {
+		if !model.IsSelfManagedCerts(r.CR) {
+			cert := &certv1alpha1.Certificate{}
+			cert.Name = name
+			cert.Namespace = r.CR.Namespace
+			if err := r.deleteObject(cert); err != nil && !meta.IsNoMatchError(err) {
+				log.Error(err, "failed to delete certificate: "+name)
+				return err
+			}
+		}
+		secret := &v1.Secret{}
+		secret.Name = name
+		secret.Namespace = r.CR.Namespace
+		if err := r.deleteObject(secret); err != nil {
+			log.Error(err, "failed to delete tls secret: "+name)
+			return err
+		}
+	}
+	if model.IsSelfManagedCerts(r.CR) {
+		secret := &v1.Secret{}
+		secret.Name = model.CASecretName(r.CR)
+		secret.Namespace = r.CR.Namespace
+		if err := r.deleteObject(secret); err != nil {
+			log.Error(err, "failed to delete CA secret: "+secret.Name)
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Reconsiler) cleanupPrometheusRules() error {
+	rules, err := r.listOwnedDefaultRules()
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if err := r.deleteObject(rule); err != nil {
+			log.Error(err, "failed to delete prometheus rule: "+rule.Name)
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Reconsiler) cleanupPVCs() error {
+	for _, labels := range []map[string]string{model.PrometheusPVCLabels(r.CR), model.AlertmanagerPVCLabels(r.CR)} {
+		pvcs := &v1.PersistentVolumeClaimList{}
+		if err := r.Client.List(r.Context, pvcs, client.InNamespace(r.CR.Namespace), client.MatchingLabels(labels)); err != nil {
+			log.Error(err, "failed to list persistent volume claims")
+			return err
+		}
+		for i := range pvcs.Items {
+			if err := r.deleteObject(&pvcs.Items[i]); err != nil {
+				log.Error(err, "failed to delete persistent volume claim: "+pvcs.Items[i].Name)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//deleteObject deletes object and ignores it if it does not exist
+func (r *Reconsiler) deleteObject(obj runtime.Object) error {
+	if err := r.Client.Delete(r.Context, obj); err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package reconsiler
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/controller/prometheusext/model"
+)
+
+//certExpiry exports expiry of monitoring tls certificates. It is used by certificate-expiry default rule
+var certExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: model.CertExpiryMetric,
+	Help: "Expiry time of tls certificates used by monitoring stack in seconds since epoch",
+}, []string{"secret_namespace", "secret"})
+
+func init() {
+	metrics.Registry.MustRegister(certExpiry, objectUpdates, syncDuration, syncErrors, requeues, componentReady, lastSuccessfulSync)
+}
+
+//recordCertExpiry updates metric of certificate expiry
+func recordCertExpiry(namespace, secret string, expiry float64) {
+	certExpiry.WithLabelValues(namespace, secret).Set(expiry)
+}
+
+//forgetCertExpiry removes metric of certificate expiry when secret is deleted
+func forgetCertExpiry(namespace string, secrets ...string) {
+	for _, secret := range secrets {
+		certExpiry.DeleteLabelValues(namespace, secret)
+	}
+}
+
+//objectUpdates counts updates of objects which are changed or skipped because nothing is changed
+var objectUpdates = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "ibm_monitoring_operator_object_updates_total",
+	Help: "Number of times objects managed by operator are synchronized, by result changed or unchanged",
+}, []string{"kind", "name", "result"})
+
+//recordObjectUpdate counts result of object update
+func recordObjectUpdate(kind, name string, changed bool) {
+	result := "unchanged"
+	if changed {
+		result = "changed"
+	}
+	objectUpdates.WithLabelValues(kind, name, result).Inc()
+}
+
+//syncDuration observes duration of every sync step
+var syncDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "ibm_monitoring_operator_sync_duration_seconds",
+	Help:    "Duration of sync steps of PrometheusExt reconciliation in seconds",
+	Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+}, []string{"step"})
+
+//syncErrors counts sync steps which failed. Steps waiting to be requeued are not counted
+var syncErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "ibm_monitoring_operator_sync_errors_total",
+	Help: "Number of failed sync steps of PrometheusExt reconciliation",
+}, []string{"step"})
+
+//requeues counts reconciliations which are requeued to wait for something
+var requeues = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "ibm_monitoring_operator_requeues_total",
+	Help: "Number of PrometheusExt reconciliations requeued, by reason",
+}, []string{"reason"})
+
+//componentReady exports readiness of components of every CR
+var componentReady = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "ibm_monitoring_operator_component_ready",
+	Help: "Whether component managed by PrometheusExt is ready (1) or not (0)",
+}, []string{"namespace", "prometheusext", "component"})
+
+//lastSuccessfulSync exports time when all sync steps of CR succeeded last time
+var lastSuccessfulSync = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "ibm_monitoring_operator_last_successful_sync_timestamp_seconds",
+	Help: "Time when PrometheusExt was reconciled successfully last time in seconds since epoch",
+}, []string{"namespace", "prometheusext"})
+
+//recordSyncStep records duration of sync step and counts it if it failed
+func recordSyncStep(step string, duration time.Duration, err error) {
+	syncDuration.WithLabelValues(step).Observe(duration.Seconds())
+	if err != nil && !model.IsRequeueErr(err) {
+		syncErrors.WithLabelValues(step).Inc()
+	}
+}
+
+//recordRequeue counts requeue with reason of requeue error
+func recordRequeue(err error) {
+	if requeueErr, ok := err.(model.IReqeueError); ok {
+		requeues.WithLabelValues(requeueErr.Reason()).Inc()
+	}
+}
+
+//recordComponentReady updates readiness metric of component of CR
+func recordComponentReady(namespace, cr, component string, ready bool) {
+	value := 0.0
+	if ready {
+		value = 1
+	}
+	componentReady.WithLabelValues(namespace, cr, component).Set(value)
+}
+
+//recordSuccessfulSync sets time of last successful sync of CR to now
+func recordSuccessfulSync(namespace, cr string) {
+	lastSuccessfulSync.WithLabelValues(namespace, cr).SetToCurrentTime()
+}
+
+//forgetCR removes metrics of CR when it is deleted
+func forgetCR(namespace, cr string) {
+	for _, t := range componentConditionTypes {
+		componentReady.DeleteLabelValues(namespace, cr, string(t))
+	}
+	lastSuccessfulSync.DeleteLabelValues(namespace, cr)
+}
@@ -20,7 +20,6 @@ import (
 	promev1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
-	ev1beta1 "k8s.io/api/extensions/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -44,21 +43,21 @@ func (r *Reconsiler) readPrometheus() error {
 
 		r.CurrentState.ManagedPrometheus = &prome
 	}
-	//read ingress and service for the prometheus
-	ingress := ev1beta1.Ingress{}
+	//read ingress, route, pod disruption budget, thanos service and service for the prometheus
+	ingress, route, err := r.readExposure(model.PromethuesName(r.CR))
+	if err != nil {
+		return err
+	}
+	r.CurrentState.PromeIngress = ingress
+	r.CurrentState.PromeRoute = route
+	if r.CurrentState.PromePDB, err = r.readPodDisruptionBudget(model.Prometheus); err != nil {
+		return err
+	}
+	if r.CurrentState.ThanosGRPCSvc, err = r.readThanosGRPCService(); err != nil {
+		return err
+	}
 	svc := v1.Service{}
 	key = client.ObjectKey{Namespace: r.CR.Namespace, Name: model.PromethuesName(r.CR)}
-	if err := r.Client.Get(r.Context, key, &ingress); err != nil {
-		if errors.IsNotFound(err) {
-			r.CurrentState.PromeIngress = nil
-
-		} else {
-			log.Error(err, "failed to get ingress of Prometheus "+prome.Name)
-			return err
-		}
-	} else {
-		r.CurrentState.PromeIngress = &ingress
-	}
 	if err := r.Client.Get(r.Context, key, &svc); err != nil {
 		if errors.IsNotFound(err) {
 			r.CurrentState.PromeSvc = nil
@@ -117,22 +116,18 @@ func (r *Reconsiler) readAlertmanager() error {
 		r.CurrentState.ManagedAlertmanager = &am
 	}
 
-	//read ingress and service for the alermanager
-	ingress := ev1beta1.Ingress{}
+	//read ingress, route, pod disruption budget and service for the alermanager
+	ingress, route, err := r.readExposure(model.AlertmanagerName(r.CR))
+	if err != nil {
+		return err
+	}
+	r.CurrentState.AlertManagerIngress = ingress
+	r.CurrentState.AlertmanagerRoute = route
+	if r.CurrentState.AlertmanagerPDB, err = r.readPodDisruptionBudget(model.Alertmanager); err != nil {
+		return err
+	}
 	svc := v1.Service{}
 	key = client.ObjectKey{Namespace: r.CR.Namespace, Name: model.AlertmanagerName(r.CR)}
-	if err := r.Client.Get(r.Context, key, &ingress); err != nil {
-		if errors.IsNotFound(err) {
-			r.CurrentState.AlertManagerIngress = nil
-		} else {
-			log.Error(err, "Failed to get ingress of Alertmanager "+am.Name)
-			return err
-		}
-
-	} else {
-		r.CurrentState.AlertManagerIngress = &ingress
-
-	}
 	if err := r.Client.Get(r.Context, key, &svc); err != nil {
 		if errors.IsNotFound(err) {
 			r.CurrentState.AlertmanagerSvc = nil
@@ -207,6 +202,35 @@ func (r *Reconsiler) readSecrets() error {
 		r.CurrentState.MonitoringClientSecret = secret.DeepCopy()
 	}
 
+	//CA secret created in selfManaged mode
+	r.CurrentState.MonitoringCASecret = nil
+	if model.IsSelfManagedCerts(r.CR) {
+		key = client.ObjectKey{Name: model.CASecretName(r.CR), Namespace: r.CR.Namespace}
+		if err := r.Client.Get(r.Context, key, secret); err != nil {
+			if !errors.IsNotFound(err) {
+				log.Error(err, "failed to get monitoring CA secet "+key.Name)
+				return err
+			}
+		} else {
+			r.CurrentState.MonitoringCASecret = secret.DeepCopy()
+		}
+	}
+
+	//secrets of remote write and remote read endpoints
+	r.CurrentState.RemoteStorageSecrets = make(map[string]*v1.Secret)
+	for _, name := range model.RemoteStorageSecrets(r.CR) {
+		r.CurrentState.RemoteStorageSecrets[name] = nil
+		key = client.ObjectKey{Name: name, Namespace: r.CR.Namespace}
+		if err := r.Client.Get(r.Context, key, secret); err != nil {
+			if !errors.IsNotFound(err) {
+				log.Error(err, "failed to get remote storage secret "+name)
+				return err
+			}
+		} else {
+			r.CurrentState.RemoteStorageSecrets[name] = secret.DeepCopy()
+		}
+	}
+
 	return nil
 
 }
@@ -278,3 +302,30 @@ func (r *Reconsiler) readRouterCms() error {
 
 	return nil
 }
+
+//readStatefulSets reads statefulsets created by prometheus operator for managed Prometheus and Alertmanager
+func (r *Reconsiler) readStatefulSets() error {
+	sts := &appsv1.StatefulSet{}
+	key := client.ObjectKey{Namespace: r.CR.Namespace, Name: model.PrometheusStatefulSetName(r.CR)}
+	if err := r.Client.Get(r.Context, key, sts); err != nil {
+		if !errors.IsNotFound(err) {
+			log.Error(err, "Failed to get prometheus statefulset "+key.Name)
+			return err
+		}
+		r.CurrentState.PrometheusStatefulSet = nil
+	} else {
+		r.CurrentState.PrometheusStatefulSet = sts.DeepCopy()
+	}
+
+	key.Name = model.AlertmanagerStatefulSetName(r.CR)
+	if err := r.Client.Get(r.Context, key, sts); err != nil {
+		if !errors.IsNotFound(err) {
+			log.Error(err, "Failed to get alertmanager statefulset "+key.Name)
+			return err
+		}
+		r.CurrentState.AlertmanagerStatefulSet = nil
+	} else {
+		r.CurrentState.AlertmanagerStatefulSet = sts.DeepCopy()
+	}
+	return nil
+}
@@ -19,18 +19,20 @@ package reconsiler
 import (
 	"context"
 	"strings"
+	"time"
 
 	promev1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	routev1 "github.com/openshift/api/route/v1"
 	secv1client "github.com/openshift/client-go/security/clientset/versioned/typed/security/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	apisv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 
-	ev1beta1 "k8s.io/api/extensions/v1beta1"
-	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	monitoringv1alpha1 "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
@@ -48,6 +50,18 @@ type Reconsiler struct {
 	Client       client.Client
 	// This client is for SCC creation
 	SecClient secv1client.SecurityV1Interface
+	// AlertRouteReader reads AlertRoutes of all namespaces
+	AlertRouteReader client.Reader
+	// APIReader reads objects which are not in cache of manager
+	APIReader client.Reader
+	// Recorder records events of CR. Events are not recorded if it is nil
+	Recorder *EventRecorder
+	// RequeueAfter is set when CR needs to be reconciled again later even if nothing changes
+	RequeueAfter time.Duration
+	// component whose sync step failed in current loop
+	failedComponent monitoringv1alpha1.ConditionType
+	// objects not applied in current loop because their fields are managed by others
+	fieldConflicts []string
 }
 
 // ClusterState store current state of observed objects in the cluster
@@ -56,11 +70,18 @@ type ClusterState struct {
 	ManagedPrometheus             *promev1.Prometheus   //create by this CR
 	ManagedAlertmanager           *promev1.Alertmanager //created by this CR
 	PromeSvc                      *v1.Service
-	PromeIngress                  *ev1beta1.Ingress
+	PromeIngress                  *unstructured.Unstructured //extensions/v1beta1 or networking.k8s.io/v1 ingress
+	PromeRoute                    *routev1.Route
 	AlertmanagerSvc               *v1.Service
-	AlertManagerIngress           *ev1beta1.Ingress
+	AlertManagerIngress           *unstructured.Unstructured //extensions/v1beta1 or networking.k8s.io/v1 ingress
+	AlertmanagerRoute             *routev1.Route
+	PromePDB                      *policyv1beta1.PodDisruptionBudget
+	AlertmanagerPDB               *policyv1beta1.PodDisruptionBudget
+	ThanosGRPCSvc                 *v1.Service //store api of thanos sidecars
 	MonitoringSecret              *v1.Secret
 	MonitoringClientSecret        *v1.Secret
+	MonitoringCASecret            *v1.Secret            //created in selfManaged certs mode
+	RemoteStorageSecrets          map[string]*v1.Secret //referred by remote write and remote read endpoints, nil if not found
 	PrometheusScrapeTargetsSecret *v1.Secret
 	PromeNgCm                     *v1.ConfigMap
 	RouterEntryCm                 *v1.ConfigMap
@@ -68,6 +89,8 @@ type ClusterState struct {
 	ProLuaCm                      *v1.ConfigMap
 	AlertNgCm                     *v1.ConfigMap
 	PrometheusOperatorDeployment  *appsv1.Deployment
+	PrometheusStatefulSet         *appsv1.StatefulSet //created by prometheus operator
+	AlertmanagerStatefulSet       *appsv1.StatefulSet //created by prometheus operator
 }
 
 // ReadClusterState Read objects managed by this CR from cluster
@@ -91,45 +114,120 @@ func (r *Reconsiler) ReadClusterState() error {
 	if err := r.readPrometheusOperatorDeployment(); err != nil {
 		return err
 	}
+	if err := r.readStatefulSets(); err != nil {
+		return err
+	}
 	return nil
 }
 
-// Sync makes cluster state as expected
+// Sync makes cluster state as expected and reports result in status of CR
 func (r *Reconsiler) Sync() error {
-	err := promodel.CreateOrUpdateSCC(r.SecClient, r.CR.Namespace)
-	if err != nil {
-		log.Error(err, "Fail to reconsile SCC")
+	if promodel.IsPaused(r.CR) {
+		log.Info("reconciliation is paused, only status is updated", "annotation", promodel.PausedAnn)
+		r.normalEvent(eventReasonPaused, "reconciliation is paused by annotation "+promodel.PausedAnn)
+		r.updateStatus(nil)
+		return nil
+	}
+	err := classifyError(r.sync())
+	switch {
+	case err == nil:
+		recordSuccessfulSync(r.CR.Namespace, r.CR.Name)
+	case promodel.IsRequeueErr(err):
+		recordRequeue(err)
+	default:
+		r.warningEvent(eventReasonSyncFailed, err.Error())
+	}
+	r.updateStatus(err)
+	return err
+}
+
+func (r *Reconsiler) sync() error {
+	if err := r.syncFinalizer(); err != nil {
 		return err
 	}
-	log.Info("SCC is reconciled")
-	r.updateStatus()
-	if err := r.syncStorageClass(); err != nil {
+	//maintenance silence is needed most when other components fail, so it does not wait for the other steps
+	maintenanceErr := r.syncStep("maintenance", r.syncMaintenance)
+	if err := r.syncStep("pod_security", r.syncPodSecurity); err != nil {
 		return err
 	}
-	if err := r.syncClusterHostInfo(); err != nil {
+	if err := r.syncStep("storage_class", r.syncStorageClass); err != nil {
 		return err
 	}
-	if err := r.syncProOperatorDeployment(); err != nil {
+	if err := r.syncStep("cluster_info", r.syncClusterHostInfo); err != nil {
 		return err
 	}
-	if err := r.syncSecrets(); err != nil {
-		return err
+	if err := r.syncStep("prometheus_operator", r.syncProOperatorDeployment); err != nil {
+		return r.componentFailed(monitoringv1alpha1.ConditionPrometheusOperator, err)
 	}
-	if err := r.syncRouterCms(); err != nil {
-		return err
+	if err := r.syncStep("secrets", r.syncSecrets); err != nil {
+		return r.componentFailed(monitoringv1alpha1.ConditionCertificates, err)
 	}
-	if err := r.syncPrometheus(); err != nil {
-		return err
+	if err := r.syncStep("router_configmaps", r.syncRouterCms); err != nil {
+		return r.componentFailed(monitoringv1alpha1.ConditionRouter, err)
 	}
-	if err := r.syncAlertmanager(); err != nil {
-		return err
+	if err := r.syncStep("prometheus", r.syncPrometheus); err != nil {
+		return r.componentFailed(monitoringv1alpha1.ConditionPrometheus, err)
 	}
-	if err := r.syncMCMCtl(); err != nil {
-		return err
+	if err := r.syncStep("remote_write_status", r.syncRemoteWriteStatus); err != nil {
+		return r.componentFailed(monitoringv1alpha1.ConditionPrometheus, err)
+	}
+	if err := r.syncStep("alertmanager", r.syncAlertmanager); err != nil {
+		return r.componentFailed(monitoringv1alpha1.ConditionAlertmanager, err)
+	}
+	if err := r.syncStep("mcm_controller", r.syncMCMCtl); err != nil {
+		return r.componentFailed(monitoringv1alpha1.ConditionMCMController, err)
+	}
+	if maintenanceErr != nil {
+		return r.componentFailed(monitoringv1alpha1.ConditionAlertmanager, maintenanceErr)
 	}
 	return nil
 }
-func (r *Reconsiler) updateStatus() {
+
+//syncStep runs one sync step and records its duration and result in metrics
+func (r *Reconsiler) syncStep(step string, f func() error) error {
+	start := time.Now()
+	err := classifyError(f())
+	recordSyncStep(step, time.Since(start), err)
+	return err
+}
+
+//classifyError turns conflicts and temporary failures of api server into requeue errors, which are retried with backoff
+func classifyError(err error) error {
+	switch {
+	case err == nil, promodel.IsRequeueErr(err), promodel.IsPermanentErr(err):
+		return err
+	case kerrors.IsConflict(err):
+		return promodel.NewRequeueErrorWithDetail(promodel.RequeueConflict, "sync", "conflict", err.Error())
+	case kerrors.IsServerTimeout(err), kerrors.IsTimeout(err), kerrors.IsTooManyRequests(err),
+		kerrors.IsServiceUnavailable(err), kerrors.IsInternalError(err):
+		return promodel.NewRequeueErrorWithDetail(promodel.RequeueTransient, "sync", "transient", err.Error())
+	}
+	return err
+}
+
+//waitingStatus returns what reconciliation is waiting for. The time it started waiting is kept while it waits for the same thing
+func (r *Reconsiler) waitingStatus(syncErr error) *monitoringv1alpha1.WaitingStatus {
+	requeueErr, ok := syncErr.(promodel.IReqeueError)
+	if !ok {
+		return nil
+	}
+	waiting := &monitoringv1alpha1.WaitingStatus{
+		Category: string(requeueErr.Category()),
+		Reason:   requeueErr.Reason(),
+		Since:    apisv1.Now(),
+	}
+	if old := r.CR.Status.Waiting; old != nil && old.Category == waiting.Category && old.Reason == waiting.Reason {
+		waiting.Since = old.Since
+	}
+	return waiting
+}
+
+func (r *Reconsiler) componentFailed(t monitoringv1alpha1.ConditionType, err error) error {
+	r.failedComponent = t
+	return err
+}
+
+func (r *Reconsiler) updateStatus(syncErr error) {
 	if r.CurrentState.PrometheusOperatorDeployment != nil {
 		r.CR.Status.PrometheusOperator = r.CurrentState.PrometheusOperatorDeployment.Status
 	}
@@ -148,6 +246,9 @@ func (r *Reconsiler) updateStatus() {
 
 	r.CR.Status.Configmaps = r.cmStatus()
 	r.CR.Status.Secrets = r.secretStatus()
+	r.CR.Status.Certificates = r.certificatesStatus()
+	r.CR.Status.Waiting = r.waitingStatus(syncErr)
+	r.updateConditions(syncErr)
 	if err := r.Client.Status().Update(r.Context, r.CR); err != nil {
 		log.Error(err, "Failed to update status")
 	}
@@ -227,25 +328,22 @@ func (r *Reconsiler) secretStatus() string {
 
 	return promodel.Ready + ": " + readyStr + ", " + promodel.NotReady + ": " + notReadyStr
 }
-
-func (r *Reconsiler) createObject(obj runtime.Object) error {
-	if err := controllerutil.SetControllerReference(r.CR, obj.(apisv1.Object), r.Schema); err != nil {
-		return err
-	}
-	return r.Client.Create(r.Context, obj)
-}
-
-func (r *Reconsiler) updateObject(obj runtime.Object) error {
-	if err := controllerutil.SetControllerReference(r.CR, obj.(apisv1.Object), r.Schema); err != nil {
-		return err
-	}
-	if err := r.Client.Update(r.Context, obj); err != nil {
-		if kerrors.IsConflict(err) {
-			return promodel.NewRequeueError("sync.UpdateObject", "Object version conflict when updating and requeue it")
+func (r *Reconsiler) certificatesStatus() []monitoringv1alpha1.CertificateStatus {
+	var status []monitoringv1alpha1.CertificateStatus
+	for _, secret := range []*v1.Secret{r.CurrentState.MonitoringCASecret, r.CurrentState.MonitoringSecret, r.CurrentState.MonitoringClientSecret} {
+		if secret == nil {
+			continue
 		}
-		return err
-
+		expiry, err := promodel.CertExpiry(secret)
+		if err != nil {
+			log.Info("failed to read expiry date of certificate in secret " + secret.Name + ": " + err.Error())
+			continue
+		}
+		recordCertExpiry(secret.Namespace, secret.Name, float64(expiry.Unix()))
+		status = append(status, monitoringv1alpha1.CertificateStatus{
+			SecretName: secret.Name,
+			NotAfter:   apisv1.Time{Time: *expiry},
+		})
 	}
-	return nil
-
+	return status
 }
@@ -0,0 +1,140 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package reconsiler
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	monitoringv1alpha1 "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+)
+
+//readAlertRoutes reads AlertRoutes of all namespaces sorted by namespace and name
+func (r *Reconsiler) readAlertRoutes() ([]monitoringv1alpha1.AlertRoute, error) {
+	if r.AlertRouteReader == nil {
+		return nil, nil
+	}
+	list := &monitoringv1alpha1.AlertRouteList{}
+	if err := r.AlertRouteReader.List(r.Context, list); err != nil {
+		log.Error(err, "Failed to list AlertRoutes")
+		return nil, err
+	}
+	alertRoutes := list.Items
+	sort.Slice(alertRoutes, func(i, j int) bool {
+		if alertRoutes[i].Namespace != alertRoutes[j].Namespace {
+			return alertRoutes[i].Namespace < alertRoutes[j].Namespace
+		}
+		return alertRoutes[i].Name < alertRoutes[j].Name
+	})
+	return alertRoutes, nil
+}
+
+//syncAlertRoutesStatus reports which routes of AlertRoutes are merged into alertmanager configuration
+func (r *Reconsiler) syncAlertRoutesStatus(alertRoutes []monitoringv1alpha1.AlertRoute, routeErrors map[string][]monitoringv1alpha1.AlertRouteError) error {
+	for i := range alertRoutes {
+		alertRoute := &alertRoutes[i]
+		errs := routeErrors[alertRoute.Namespace+"/"+alertRoute.Name]
+		status := monitoringv1alpha1.AlertRoutePrometheusExtStatus{
+			State:              monitoringv1alpha1.AlertRouteAccepted,
+			Message:            "routes are merged into alertmanager configuration",
+			Errors:             errs,
+			ObservedGeneration: alertRoute.Generation,
+		}
+		if len(errs) != 0 {
+			status.State = monitoringv1alpha1.AlertRoutePartiallyAccepted
+			status.Message = "valid routes are merged into alertmanager configuration"
+			if invalidRoutes(errs) == len(alertRoute.Spec.Routes) {
+				status.State = monitoringv1alpha1.AlertRouteRejected
+				status.Message = "no valid route"
+			}
+		}
+		if err := r.updateAlertRouteStatus(alertRoute, &status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//syncAlertRoutesNotApplied reports that AlertRoutes are not merged because alertmanager configuration is not managed by the operator
+func (r *Reconsiler) syncAlertRoutesNotApplied(alertRoutes []monitoringv1alpha1.AlertRoute, message string) error {
+	for i := range alertRoutes {
+		status := monitoringv1alpha1.AlertRoutePrometheusExtStatus{
+			State:              monitoringv1alpha1.AlertRouteNotApplied,
+			Message:            message,
+			ObservedGeneration: alertRoutes[i].Generation,
+		}
+		if err := r.updateAlertRouteStatus(&alertRoutes[i], &status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//cleanupAlertRoutesStatus removes status of CR from AlertRoutes when CR is deleted
+func (r *Reconsiler) cleanupAlertRoutesStatus() error {
+	alertRoutes, err := r.readAlertRoutes()
+	if err != nil {
+		return err
+	}
+	for i := range alertRoutes {
+		if err := r.updateAlertRouteStatus(&alertRoutes[i], nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//updateAlertRouteStatus sets status of AlertRoute for this CR, or removes it if status is nil.
+//Every PrometheusExt has its own entry, so that CRs do not overwrite results of each other
+func (r *Reconsiler) updateAlertRouteStatus(alertRoute *monitoringv1alpha1.AlertRoute, status *monitoringv1alpha1.AlertRoutePrometheusExtStatus) error {
+	key := r.CR.Namespace + "/" + r.CR.Name
+	var statuses []monitoringv1alpha1.AlertRoutePrometheusExtStatus
+	for _, s := range alertRoute.Status.PrometheusExts {
+		if s.PrometheusExt != key {
+			statuses = append(statuses, s)
+		}
+	}
+	if status != nil {
+		status.PrometheusExt = key
+		statuses = append(statuses, *status)
+		sort.Slice(statuses, func(i, j int) bool { return statuses[i].PrometheusExt < statuses[j].PrometheusExt })
+	}
+	if reflect.DeepEqual(alertRoute.Status.PrometheusExts, statuses) {
+		return nil
+	}
+	updated := alertRoute.DeepCopy()
+	updated.Status.PrometheusExts = statuses
+	if err := r.Client.Status().Update(r.Context, updated); err != nil {
+		log.Error(err, "Failed to update status of AlertRoute "+alertRoute.Namespace+"/"+alertRoute.Name)
+		return err
+	}
+	if status != nil {
+		log.Info("AlertRoute " + alertRoute.Namespace + "/" + alertRoute.Name + " is " + string(status.State))
+	}
+	return nil
+}
+
+func invalidRoutes(errs []monitoringv1alpha1.AlertRouteError) int {
+	count := 0
+	for _, err := range errs {
+		if strings.HasPrefix(err.Field, "routes[") {
+			count++
+		}
+	}
+	return count
+}
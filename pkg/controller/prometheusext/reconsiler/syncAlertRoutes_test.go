@@ -0,0 +1,77 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package reconsiler
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	monitoringv1alpha1 "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+)
+
+func TestAlertRouteStatusPerCR(t *testing.T) {
+	alertRoute := &monitoringv1alpha1.AlertRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "team", Namespace: "app", Generation: 1},
+		Spec:       monitoringv1alpha1.AlertRouteSpec{Routes: []monitoringv1alpha1.AlertmanagerRoute{{Receiver: "team"}}},
+	}
+	c := fake.NewFakeClientWithScheme(newTestScheme(t), alertRoute)
+	first := newTestReconsiler(t, c)
+	first.AlertRouteReader = c
+	second := newTestReconsiler(t, c)
+	second.CR.Name = "other"
+	second.AlertRouteReader = c
+
+	sync := func(r *Reconsiler, errs []monitoringv1alpha1.AlertRouteError) {
+		alertRoutes, err := r.readAlertRoutes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := r.syncAlertRoutesStatus(alertRoutes, map[string][]monitoringv1alpha1.AlertRouteError{"app/team": errs}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	states := func() map[string]monitoringv1alpha1.AlertRouteState {
+		stored := &monitoringv1alpha1.AlertRoute{}
+		if err := c.Get(context.TODO(), client.ObjectKey{Namespace: "app", Name: "team"}, stored); err != nil {
+			t.Fatal(err)
+		}
+		got := make(map[string]monitoringv1alpha1.AlertRouteState)
+		for _, s := range stored.Status.PrometheusExts {
+			got[s.PrometheusExt] = s.State
+		}
+		return got
+	}
+
+	sync(first, nil)
+	sync(second, []monitoringv1alpha1.AlertRouteError{{Field: "routes[0]", Message: "invalid"}})
+	sync(first, nil)
+	got := states()
+	if len(got) != 2 || got["ns/monitoring"] != monitoringv1alpha1.AlertRouteAccepted || got["ns/other"] != monitoringv1alpha1.AlertRouteRejected {
+		t.Errorf("states of AlertRoute = %v, want accepted by ns/monitoring and rejected by ns/other", got)
+	}
+
+	if err := second.cleanupAlertRoutesStatus(); err != nil {
+		t.Fatal(err)
+	}
+	if got := states(); len(got) != 1 || got["ns/monitoring"] != monitoringv1alpha1.AlertRouteAccepted {
+		t.Errorf("states of AlertRoute after cleanup = %v, want only ns/monitoring", got)
+	}
+}
@@ -17,6 +17,9 @@
 package reconsiler
 
 import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -26,78 +29,102 @@ import (
 
 func (r *Reconsiler) syncAlertmanager() error {
 	//config secret
-	secret := model.AlertmanagerConfigSecret(r.CR)
-	if err := r.Client.Get(r.Context, client.ObjectKey{Name: secret.Name, Namespace: r.CR.Namespace}, secret); err != nil {
-		if errors.IsNotFound(err) {
-			if err = r.createObject(secret); err != nil {
-				log.Error(err, "Failed to create secret for alertmanager configration")
-				return err
-			}
-		} else {
-			return err
-		}
-
+	if err := r.syncAlertmanagerConfig(); err != nil {
+		return err
 	}
 	log.Info("alertmanager configration secret is sync")
 	//alertmanager
-	if r.CurrentState.ManagedAlertmanager == nil {
-		am, err := model.NewAlertmanager(r.CR)
-		if err != nil {
-			log.Error(err, "Failed to create alertmanager object")
-			return err
-		}
-		if err = r.createObject(am); err != nil {
-			log.Error(err, "Failed to create alertmanager in cluster")
-			return err
-		}
-
-	} else {
-		am, err := model.UpdatedAlertmanager(r.CR, r.CurrentState.ManagedAlertmanager)
-		if err != nil {
-			log.Error(err, "failed to create updated alertmanager object")
-			return err
-		}
-		if err = r.updateObject(am); err != nil {
-			log.Error(err, "Failed to update alertmanager object in cluster")
-			return err
-		}
-
+	hash := r.alertmanagerConfigHash()
+	am, err := model.NewAlertmanager(r.CR)
+	if err != nil {
+		log.Error(err, "Failed to create alertmanager object")
+		return err
 	}
+	model.SetConfigHash(am.Spec.PodMetadata, hash)
+	if err = r.applyObject(r.CurrentState.ManagedAlertmanager, am); err != nil {
+		log.Error(err, "Failed to apply alertmanager in cluster")
+		return err
+	}
+	r.recordConfigHash(string(model.Alertmanager), hash)
 	log.Info("alertmanager object is sync")
 	//service
-	if r.CurrentState.AlertmanagerSvc == nil {
-		svc := model.NewAlertmanagetSvc(r.CR)
-		if err := r.createObject(svc); err != nil {
-			log.Error(err, "Failed to create service for alertmanager in cluster")
-			return err
-		}
-
-	} else {
-		svc := model.UpdatedAlertmanagetSvc(r.CR, r.CurrentState.AlertmanagerSvc)
-		if err := r.updateObject(svc); err != nil {
-			log.Error(err, "Failed to update alertmanager service in cluster")
-			return err
-		}
-
+	svc := model.NewAlertmanagetSvc(r.CR)
+	if err := r.applyObject(r.CurrentState.AlertmanagerSvc, svc); err != nil {
+		log.Error(err, "Failed to apply service for alertmanager in cluster")
+		return err
 	}
 	log.Info("alertmanager service object is sync")
-	//ingress
-	if r.CurrentState.AlertManagerIngress == nil {
-		ingress := model.NewAlertmanagerIngress(r.CR)
-		if err := r.createObject(ingress); err != nil {
-			log.Error(err, "Failed to create ingress for alertmanager in cluster")
+	//ingress or route
+	if err := r.syncExposure(model.Alertmanager, r.CurrentState.AlertManagerIngress, r.CurrentState.AlertmanagerRoute); err != nil {
+		return err
+	}
+	//pod disruption budget
+	if err := r.syncPodDisruptionBudget(model.Alertmanager, r.CurrentState.AlertmanagerPDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+//syncAlertmanagerConfig renders alertmanager configuration from routing section of CR and AlertRoutes.
+//Default configuration is created once if routing section is not set
+func (r *Reconsiler) syncAlertmanagerConfig() error {
+	secret := &v1.Secret{}
+	key := client.ObjectKey{Name: model.AlertmanagerConfigSecretName(r.CR), Namespace: r.CR.Namespace}
+	if err := r.Client.Get(r.Context, key, secret); err != nil {
+		if !errors.IsNotFound(err) {
+			log.Error(err, "Failed to get secret for alertmanager configration")
 			return err
 		}
+		secret = nil
+	}
+	alertRoutes, err := r.readAlertRoutes()
+	if err != nil {
+		return err
+	}
 
-	} else {
-		ingress := model.UpdatedAlertmanagetIngress(r.CR, r.CurrentState.AlertManagerIngress)
-		if err := r.updateObject(ingress); err != nil {
-			log.Error(err, "Failed to update alertmanager ingress in cluster")
-			return err
+	if r.CR.Spec.AlertManagerConfig.Routing == nil {
+		if secret == nil {
+			if err := r.applyObject(nil, model.AlertmanagerConfigSecret(r.CR)); err != nil {
+				log.Error(err, "Failed to create secret for alertmanager configration")
+				return err
+			}
 		}
+		return r.syncAlertRoutesNotApplied(alertRoutes, "alertManagerConfig.routing is not set in PrometheusExt "+r.CR.Name)
+	}
 
+	if secret != nil && model.IsUnmanagedAlertmanagerConfig(secret) {
+		log.Info("alertmanager configration secret is managed by user")
+		return r.syncAlertRoutesNotApplied(alertRoutes, "alertmanager configuration secret "+secret.Name+" is managed by user")
+	}
+	config, routeErrors, err := model.AlertmanagerConfigYaml(r.CR, alertRoutes, r.secretValue)
+	if err != nil {
+		log.Error(err, "Invalid alertmanager routing configuration")
+		return err
+	}
+	desired := model.AlertmanagerConfigSecret(r.CR)
+	desired.Data[model.AlertmanagerConfigKey] = config
+	if err = r.applyObject(secret, desired); err != nil {
+		log.Error(err, "Failed to apply secret for alertmanager configration")
+		return err
 	}
-	log.Info("alertmanager ingress object is sync")
+	return r.syncAlertRoutesStatus(alertRoutes, routeErrors)
+}
 
-	return nil
+//secretValue reads value of key in a secret
+func (r *Reconsiler) secretValue(namespace string, selector *v1.SecretKeySelector) (string, error) {
+	reader := client.Reader(r.Client)
+	if namespace != r.CR.Namespace && r.APIReader != nil {
+		// secrets of other namespaces are not in cache of manager
+		reader = r.APIReader
+	}
+	secret := &v1.Secret{}
+	if err := reader.Get(r.Context, client.ObjectKey{Name: selector.Name, Namespace: namespace}, secret); err != nil {
+		return "", err
+	}
+	value, ok := secret.Data[selector.Key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in secret %s", selector.Key, selector.Name)
+	}
+	return string(value), nil
 }
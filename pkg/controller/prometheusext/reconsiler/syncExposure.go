@@ -0,0 +1,96 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package reconsiler
+
+import (
+	"fmt"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monitoringv1alpha1 "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/controller/prometheusext/model"
+)
+
+//readExposure reads ingress and route which expose prometheus or alertmanager. Route is nil if cluster does not serve routes
+func (r *Reconsiler) readExposure(name string) (*unstructured.Unstructured, *routev1.Route, error) {
+	key := client.ObjectKey{Namespace: r.CR.Namespace, Name: name}
+	ingress := model.NewIngressObject()
+	if err := r.Client.Get(r.Context, key, ingress); err != nil {
+		if !errors.IsNotFound(err) {
+			log.Error(err, "failed to get ingress "+name)
+			return nil, nil, err
+		}
+		ingress = nil
+	}
+	if !model.CurrentPlatform().Routes {
+		return ingress, nil, nil
+	}
+	route := &routev1.Route{}
+	if err := r.Client.Get(r.Context, key, route); err != nil {
+		if !errors.IsNotFound(err) {
+			log.Error(err, "failed to get route "+name)
+			return nil, nil, err
+		}
+		route = nil
+	}
+	return ingress, route, nil
+}
+
+//syncExposure applies ingress or route selected in exposure of prometheus or alertmanager
+//and deletes the one which is not selected any more
+func (r *Reconsiler) syncExposure(ot model.ObjectType, ingress *unstructured.Unstructured, route *routev1.Route) error {
+	exposure := model.ExposureOf(r.CR, ot)
+	if exposure.Type == monitoringv1alpha1.ExposureRoute && !model.CurrentPlatform().Routes {
+		return model.NewPermanentError(string(ot)+"Exposure", "cluster does not serve OpenShift routes, set exposure type to Ingress or None")
+	}
+
+	if exposure.Type == monitoringv1alpha1.ExposureIngress {
+		if err := r.applyObject(ingress, model.NewIngress(r.CR, ot)); err != nil {
+			log.Error(err, "Failed to apply ingress for "+string(ot))
+			return err
+		}
+		log.Info(string(ot) + " ingress object is sync")
+	} else if ingress != nil {
+		if err := r.deleteObject(ingress); err != nil {
+			log.Error(err, "Failed to delete ingress for "+string(ot))
+			return err
+		}
+		r.normalEvent(eventReasonDeleted, fmt.Sprintf("deleted Ingress %s which is not selected in exposure of %s", ingress.GetName(), ot))
+	}
+
+	if exposure.Type == monitoringv1alpha1.ExposureRoute {
+		secret := r.CurrentState.MonitoringSecret
+		if secret == nil || len(secret.Data[model.CACertKey]) == 0 {
+			return model.NewRequeueErrorWithDetail(model.RequeueWaitingForDependency, string(ot)+"Exposure", "wait for ca certificate of route", "secret "+r.CR.Spec.Certs.MonitoringSecret)
+		}
+		if err := r.applyObject(route, model.NewRoute(r.CR, ot, string(secret.Data[model.CACertKey]))); err != nil {
+			log.Error(err, "Failed to apply route for "+string(ot))
+			return err
+		}
+		log.Info(string(ot) + " route object is sync")
+	} else if route != nil {
+		if err := r.deleteObject(route); err != nil {
+			log.Error(err, "Failed to delete route for "+string(ot))
+			return err
+		}
+		r.normalEvent(eventReasonDeleted, fmt.Sprintf("deleted Route %s which is not selected in exposure of %s", route.Name, ot))
+	}
+	return nil
+}
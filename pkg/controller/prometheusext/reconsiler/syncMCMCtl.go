@@ -18,30 +18,21 @@ package reconsiler
 
 import "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/controller/prometheusext/model"
 
-func (r *Reconsiler) syncMCMCtl() error {
-
-	if r.CurrentState.MCMCtrlDeployment == nil {
-		deployment, err := model.NewMCMCtlDeployment(r.CR)
-		if err != nil {
-			log.Error(err, "Failed to create deployment object for MCM controller")
-			return err
-		}
-		if err = r.createObject(deployment); err != nil {
-			log.Error(err, "Failed to create deployment for MCM controller in cluster")
-			return err
-		}
-	} else {
-		deployment, err := model.UpdatedMCMCtlDeployment(r.CR, r.CurrentState.MCMCtrlDeployment)
-		if err != nil {
-			log.Error(err, "Failed to create deployment object for MCM controller")
-			return err
+const mcmCtlComponent = "mcmController"
 
-		}
-		if err = r.updateObject(deployment); err != nil {
-			log.Error(err, "Failed to update mcm controller deployment in cluster")
-			return err
-		}
+func (r *Reconsiler) syncMCMCtl() error {
+	hash := r.mcmCtlConfigHash()
+	deployment, err := model.NewMCMCtlDeployment(r.CR)
+	if err != nil {
+		log.Error(err, "Failed to create deployment object for MCM controller")
+		return err
+	}
+	model.SetConfigHash(&deployment.Spec.Template.ObjectMeta, hash)
+	if err = r.applyObject(r.CurrentState.MCMCtrlDeployment, deployment); err != nil {
+		log.Error(err, "Failed to apply deployment for MCM controller in cluster")
+		return err
 	}
+	r.recordConfigHash(mcmCtlComponent, hash)
 	log.Info("mcm controller deployment is sync")
 
 	return nil
@@ -0,0 +1,152 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package reconsiler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	monitoringv1alpha1 "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/controller/prometheusext/model"
+)
+
+//alertmanagerClient calls Alertmanager API
+var alertmanagerClient = &http.Client{Timeout: 10 * time.Second}
+
+//syncMaintenance creates silence in Alertmanager when maintenance window is set by annotation
+//and expires it when the annotation is removed or changed. Silence expires by itself when the window ends
+func (r *Reconsiler) syncMaintenance() error {
+	now := time.Now()
+	end, active, err := model.MaintenanceEnd(r.CR, now)
+	if err != nil {
+		log.Error(err, "maintenance window is ignored")
+		r.warningEvent(eventReasonInvalidMaintenance, err.Error())
+	}
+	current := r.CR.Status.Maintenance
+	if active && current != nil && current.EndsAt.Time.Equal(end) {
+		r.requeueAt(end)
+		return nil
+	}
+	callsAPI := active || (current != nil && current.EndsAt.Time.After(now))
+	if sts := r.CurrentState.AlertmanagerStatefulSet; callsAPI && (sts == nil || sts.Status.ReadyReplicas == 0) {
+		return model.NewRequeueError(model.RequeueWaitingForDependency, "maintenance", "wait for alertmanager")
+	}
+	if current != nil {
+		if current.EndsAt.Time.After(now) {
+			if err := r.expireSilence(current.SilenceID); err != nil {
+				log.Error(err, "failed to expire maintenance silence "+current.SilenceID)
+				return err
+			}
+		}
+		r.CR.Status.Maintenance = nil
+		if err := r.updateMaintenanceStatus(); err != nil {
+			return err
+		}
+		r.normalEvent(eventReasonMaintenanceEnded, "maintenance window ended, silence "+current.SilenceID+" is expired")
+	}
+	if !active {
+		return nil
+	}
+	id, err := r.createSilence(model.NewMaintenanceSilence(r.CR, now, end))
+	if err != nil {
+		log.Error(err, "failed to create maintenance silence")
+		return err
+	}
+	r.CR.Status.Maintenance = &monitoringv1alpha1.MaintenanceStatus{SilenceID: id, EndsAt: metav1.NewTime(end)}
+	if err := r.updateMaintenanceStatus(); err != nil {
+		// silence which is not in status would never be expired
+		if expireErr := r.expireSilence(id); expireErr != nil {
+			log.Error(expireErr, "failed to expire maintenance silence "+id)
+		}
+		return err
+	}
+	r.normalEvent(eventReasonMaintenanceStarted, fmt.Sprintf("alerts are silenced by silence %s until %s", id, end.UTC().Format(time.RFC3339)))
+	r.requeueAt(end)
+	return nil
+}
+
+//updateMaintenanceStatus stores status of CR right after silence is created or expired. Otherwise the silence is lost
+//when CR is updated by a later sync step, because the response of the update replaces status which is not stored yet
+func (r *Reconsiler) updateMaintenanceStatus() error {
+	if err := r.Client.Status().Update(r.Context, r.CR); err != nil {
+		log.Error(err, "failed to update maintenance status of CR")
+		return err
+	}
+	return nil
+}
+
+//requeueAt makes sure that CR is reconciled again at t
+func (r *Reconsiler) requeueAt(t time.Time) {
+	after := time.Until(t)
+	if r.RequeueAfter == 0 || after < r.RequeueAfter {
+		r.RequeueAfter = after
+	}
+}
+
+func (r *Reconsiler) createSilence(silence *model.Silence) (string, error) {
+	body, err := json.Marshal(silence)
+	if err != nil {
+		return "", err
+	}
+	resp, err := r.callAlertmanager(http.MethodPost, "/silences", body)
+	if err != nil {
+		return "", err
+	}
+	result := struct {
+		SilenceID string `json:"silenceID"`
+	}{}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return "", fmt.Errorf("invalid response of alertmanager: %v", err)
+	}
+	return result.SilenceID, nil
+}
+
+func (r *Reconsiler) expireSilence(id string) error {
+	_, err := r.callAlertmanager(http.MethodDelete, "/silence/"+id, nil)
+	return err
+}
+
+func (r *Reconsiler) callAlertmanager(method, path string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(method, model.AlertmanagerAPIURL(r.CR)+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := alertmanagerClient.Do(req.WithContext(r.Context))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	// silence is expired or deleted already
+	if method == http.MethodDelete && resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s %s of alertmanager failed with %s: %s", method, path, resp.Status, string(data))
+	}
+	return data, nil
+}
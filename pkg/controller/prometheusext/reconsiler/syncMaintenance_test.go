@@ -0,0 +1,108 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package reconsiler
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	monitoringv1alpha1 "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/controller/prometheusext/model"
+)
+
+//statusClient keeps stored status of PrometheusExt on Update like API server does with status subresource
+type statusClient struct {
+	client.Client
+}
+
+func (c *statusClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	if cr, ok := obj.(*monitoringv1alpha1.PrometheusExt); ok {
+		stored := &monitoringv1alpha1.PrometheusExt{}
+		if err := c.Client.Get(ctx, client.ObjectKey{Namespace: cr.Namespace, Name: cr.Name}, stored); err != nil {
+			return err
+		}
+		cr.Status = stored.Status
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *statusClient) Status() client.StatusWriter {
+	return c.Client
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestMaintenanceStatusSurvivesCRUpdate(t *testing.T) {
+	posts := 0
+	saved := alertmanagerClient
+	defer func() { alertmanagerClient = saved }()
+	alertmanagerClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body := ""
+		if req.Method == http.MethodPost {
+			posts++
+			body = `{"silenceID":"s1"}`
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body)), Header: http.Header{}}, nil
+	})}
+
+	newReconsiler := func(c client.Client, cr *monitoringv1alpha1.PrometheusExt) *Reconsiler {
+		r := newTestReconsiler(t, c)
+		r.CR = cr
+		r.CurrentState.AlertmanagerStatefulSet = &appsv1.StatefulSet{Status: appsv1.StatefulSetStatus{ReadyReplicas: 1}}
+		return r
+	}
+	cr := newTestReconsiler(t, nil).CR
+	cr.Annotations = map[string]string{model.MaintenanceAnn: time.Now().Add(time.Hour).UTC().Format(time.RFC3339)}
+	cr.Spec.ClusterAddress = "host.example.com"
+	c := &statusClient{Client: fake.NewFakeClientWithScheme(newTestScheme(t), cr.DeepCopy())}
+
+	r := newReconsiler(c, cr)
+	if err := r.syncMaintenance(); err != nil {
+		t.Fatal(err)
+	}
+	//cluster host annotations are added by update of CR in same reconciliation
+	if err := r.syncClusterHostInfo(); err != nil {
+		t.Fatal(err)
+	}
+	if r.CR.Status.Maintenance == nil || r.CR.Status.Maintenance.SilenceID != "s1" {
+		t.Fatalf("maintenance status is lost after update of CR: %v", r.CR.Status.Maintenance)
+	}
+
+	stored := &monitoringv1alpha1.PrometheusExt{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: cr.Namespace, Name: cr.Name}, stored); err != nil {
+		t.Fatal(err)
+	}
+	if err := newReconsiler(c, stored).syncMaintenance(); err != nil {
+		t.Fatal(err)
+	}
+	if posts != 1 {
+		t.Errorf("silence is created %d times, want 1", posts)
+	}
+}
@@ -0,0 +1,62 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package reconsiler
+
+import (
+	"fmt"
+
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/controller/prometheusext/model"
+)
+
+//readPodDisruptionBudget reads pod disruption budget of prometheus or alertmanager. It is nil if it does not exist
+func (r *Reconsiler) readPodDisruptionBudget(ot model.ObjectType) (*policyv1beta1.PodDisruptionBudget, error) {
+	pdb := &policyv1beta1.PodDisruptionBudget{}
+	key := client.ObjectKey{Namespace: r.CR.Namespace, Name: model.ObjectName(r.CR, ot)}
+	if err := r.Client.Get(r.Context, key, pdb); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		log.Error(err, "failed to get pod disruption budget of "+string(ot))
+		return nil, err
+	}
+	return pdb, nil
+}
+
+//syncPodDisruptionBudget applies pod disruption budget of prometheus or alertmanager when it has more than one replica.
+//A single replica can not be kept available during node drain, so its pod disruption budget is deleted
+func (r *Reconsiler) syncPodDisruptionBudget(ot model.ObjectType, live *policyv1beta1.PodDisruptionBudget) error {
+	if model.Replicas(r.CR, ot) > 1 {
+		if err := r.applyObject(live, model.NewPodDisruptionBudget(r.CR, ot)); err != nil {
+			log.Error(err, "Failed to apply pod disruption budget for "+string(ot))
+			return err
+		}
+		log.Info(string(ot) + " pod disruption budget is sync")
+		return nil
+	}
+	if live != nil {
+		if err := r.deleteObject(live); err != nil {
+			log.Error(err, "Failed to delete pod disruption budget for "+string(ot))
+			return err
+		}
+		r.normalEvent(eventReasonDeleted, fmt.Sprintf("deleted PodDisruptionBudget %s because %s has one replica", live.Name, ot))
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package reconsiler
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/controller/prometheusext/model"
+)
+
+//syncPodSecurity allows operands to run with the security settings they require.
+//On OpenShift service accounts are added to SCC, on plain Kubernetes namespace gets Pod Security Standards labels
+func (r *Reconsiler) syncPodSecurity() error {
+	if model.IsOpenShift() {
+		if err := model.CreateOrUpdateSCC(r.SecClient, r.CR.Namespace); err != nil {
+			log.Error(err, "Fail to reconsile SCC")
+			return err
+		}
+		log.Info("SCC is reconciled")
+		return nil
+	}
+	ns := &v1.Namespace{}
+	if err := r.APIReader.Get(r.Context, client.ObjectKey{Name: r.CR.Namespace}, ns); err != nil {
+		log.Error(err, "failed to get namespace "+r.CR.Namespace)
+		return err
+	}
+	original := ns.DeepCopy()
+	changed := false
+	for key, value := range model.PodSecurityLabels() {
+		current, ok := ns.Labels[key]
+		if ok {
+			// security level of namespace is set by admin
+			if current != value {
+				r.warningEvent(eventReasonPodSecurityLevel, "namespace label "+key+"="+current+" may not allow operands which require "+value)
+			}
+			continue
+		}
+		if ns.Labels == nil {
+			ns.Labels = make(map[string]string)
+		}
+		ns.Labels[key] = value
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	if err := r.Client.Patch(r.Context, ns, client.MergeFrom(original)); err != nil {
+		log.Error(err, "failed to set pod security labels of namespace "+r.CR.Namespace)
+		return err
+	}
+	log.Info("pod security labels are set on namespace " + r.CR.Namespace)
+	return nil
+}
@@ -17,6 +17,8 @@
 package reconsiler
 
 import (
+	"strings"
+
 	promv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -27,79 +29,52 @@ import (
 
 func (r *Reconsiler) syncPrometheus() error {
 	//scrape targets secret
-	if r.CurrentState.PrometheusScrapeTargetsSecret == nil {
-		secret, err := model.NewScrapeTargetsSecret(r.CR)
-		if err != nil {
-			log.Error(err, "Faild to create secret object for prometheus scrape target")
-			return err
-		}
-		if err = r.createObject(secret); err != nil {
-			log.Error(err, "Faild to create secret object in kubernetes for prometheus scrape target")
-			return err
-		}
-
-	} else {
-		secret, err := model.UpdatedScrapeTargetsSecret(r.CR, r.CurrentState.PrometheusScrapeTargetsSecret)
-		if err != nil {
-			return err
-		}
-		if err = r.updateObject(secret); err != nil {
-			return err
-		}
+	secret, err := model.NewScrapeTargetsSecret(r.CR)
+	if err != nil {
+		log.Error(err, "Faild to create secret object for prometheus scrape target")
+		return err
+	}
+	if err = r.applyObject(r.CurrentState.PrometheusScrapeTargetsSecret, secret); err != nil {
+		log.Error(err, "Faild to apply secret in kubernetes for prometheus scrape target")
+		return err
 	}
 	log.Info("prometheus scrape targets secret is sync")
 	//Prometheus instance
-	if r.CurrentState.ManagedPrometheus == nil {
-		prometheus, err := model.NewPrometheus(r.CR)
-		if err != nil {
-			log.Error(err, "Failed to create prometheus object")
-			return err
-		}
-		if err = r.createObject(prometheus); err != nil {
-			log.Error(err, "Failed to create prometheus in cluster")
-			return err
-		}
-	} else {
-		prometheus, err := model.UpdatedPrometheus(r.CR, r.CurrentState.ManagedPrometheus)
-		if err != nil {
-			log.Error(err, "Failed to create updated prometheus object")
-			return err
-		}
-		if err := r.updateObject(prometheus); err != nil {
-			log.Error(err, "Failed to update prometheus object")
-			return nil
-		}
-
+	if missing := r.missingRemoteStorageSecrets(); len(missing) != 0 {
+		return model.NewRequeueErrorWithDetail(model.RequeueWaitingForDependency, "prometheus", "wait for secrets of remote storage", strings.Join(missing, ", "))
+	}
+	hash := r.prometheusConfigHash()
+	prometheus, err := model.NewPrometheus(r.CR)
+	if err != nil {
+		log.Error(err, "Failed to create prometheus object")
+		return err
+	}
+	model.SetConfigHash(prometheus.Spec.PodMetadata, hash)
+	if err = r.applyObject(r.CurrentState.ManagedPrometheus, prometheus); err != nil {
+		log.Error(err, "Failed to apply prometheus in cluster")
+		return err
 	}
+	r.recordConfigHash(string(model.Prometheus), hash)
 	log.Info("prometheus object is sync")
 	//service
-	if r.CurrentState.PromeSvc == nil {
-		svc := model.NewPrometheusSvc(r.CR)
-		if err := r.createObject(svc); err != nil {
-			return err
-		}
-	} else {
-		svc := model.UpdatedPrometheusSvc(r.CR, r.CurrentState.PromeSvc)
-		if err := r.updateObject(svc); err != nil {
-			return nil
-		}
-
+	svc := model.NewPrometheusSvc(r.CR)
+	if err := r.applyObject(r.CurrentState.PromeSvc, svc); err != nil {
+		log.Error(err, "Failed to apply service for prometheus in cluster")
+		return err
 	}
 	log.Info("prometheus service object is sync")
-	//ingress
-	if r.CurrentState.PromeIngress == nil {
-		ingress := model.NewPrometheusIngress(r.CR)
-		if err := r.createObject(ingress); err != nil {
-			return err
-		}
-	} else {
-		ingress := model.UpdatedPrometheusIngress(r.CR, r.CurrentState.PromeIngress)
-		if err := r.updateObject(ingress); err != nil {
-			return nil
-		}
-
+	//ingress or route
+	if err := r.syncExposure(model.Prometheus, r.CurrentState.PromeIngress, r.CurrentState.PromeRoute); err != nil {
+		return err
+	}
+	//pod disruption budget
+	if err := r.syncPodDisruptionBudget(model.Prometheus, r.CurrentState.PromePDB); err != nil {
+		return err
+	}
+	//thanos sidecar service
+	if err := r.syncThanosGRPCService(r.CurrentState.ThanosGRPCSvc); err != nil {
+		return err
 	}
-	log.Info("prometheus ingress object is sync")
 	//prometheus rules
 	rules, err := model.DefaultPrometheusRules(r.CR)
 	if err != nil {
@@ -110,33 +85,73 @@ func (r *Reconsiler) syncPrometheus() error {
 		remoteRule := &promv1.PrometheusRule{}
 		k := client.ObjectKey{Name: string(name), Namespace: r.CR.Namespace}
 		if err := r.Client.Get(r.Context, k, remoteRule); err != nil {
-			if errors.IsNotFound(err) {
-				remoteRule = rule.DeepCopy()
-				remoteRule.ObjectMeta = metav1.ObjectMeta{
-					Name:      string(name),
-					Namespace: r.CR.Namespace,
-					Labels:    model.PrometheusLabels(r.CR),
-				}
-				if err = r.createObject(remoteRule); err != nil {
-					log.Error(err, "Failed to create PrometheusRule: "+string(name))
-					return err
-				}
-
-			} else {
+			if !errors.IsNotFound(err) {
 				log.Error(err, "Failed to get PrometheusRule: "+string(name))
 				return err
 			}
-		} else {
-			updatedRule := remoteRule.DeepCopy()
-			updatedRule.Labels = model.PrometheusLabels(r.CR)
-			updatedRule.Spec = rule.Spec
-			if err := r.updateObject(updatedRule); err != nil {
-				log.Error(err, "Failed to update prometheus rule: "+string(name))
-				return err
-			}
-
+			remoteRule = nil
+		}
+		desiredRule := rule.DeepCopy()
+		desiredRule.ObjectMeta = metav1.ObjectMeta{
+			Name:      string(name),
+			Namespace: r.CR.Namespace,
+			Labels:    model.DefaultRuleLabels(r.CR),
+		}
+		if err := r.applyObject(remoteRule, desiredRule); err != nil {
+			log.Error(err, "Failed to apply PrometheusRule: "+string(name))
+			return err
 		}
 	}
+	if err := r.deleteUnusedDefaultRules(rules); err != nil {
+		return err
+	}
 	log.Info("default prometheus rules are created")
 	return nil
 }
+
+//deleteUnusedDefaultRules deletes default rules of CR which are disabled in CR or not default rules any more
+func (r *Reconsiler) deleteUnusedDefaultRules(rules map[model.PrometheusRuleName]*promv1.PrometheusRule) error {
+	ownedRules, err := r.listOwnedDefaultRules()
+	if err != nil {
+		return err
+	}
+	for _, rule := range ownedRules {
+		if _, ok := rules[model.PrometheusRuleName(rule.Name)]; ok {
+			continue
+		}
+		if err := r.deleteObject(rule); err != nil {
+			log.Error(err, "Failed to delete prometheus rule: "+rule.Name)
+			return err
+		}
+	}
+	return nil
+}
+
+//listOwnedDefaultRules lists PrometheusRules which are created from default rules and controlled by CR.
+//Rules created by users or by other CRs in the same namespace are not returned even if they have the same name
+func (r *Reconsiler) listOwnedDefaultRules() ([]*promv1.PrometheusRule, error) {
+	remoteRules := &promv1.PrometheusRuleList{}
+	if err := r.Client.List(r.Context, remoteRules, client.InNamespace(r.CR.Namespace), client.MatchingLabels{model.DefaultRuleLabelKey: "true"}); err != nil {
+		log.Error(err, "Failed to list default prometheus rules")
+		return nil, err
+	}
+	var owned []*promv1.PrometheusRule
+	for _, rule := range remoteRules.Items {
+		if metav1.IsControlledBy(rule, r.CR) {
+			owned = append(owned, rule)
+		}
+	}
+	return owned, nil
+}
+
+//missingRemoteStorageSecrets returns secrets referred by remote endpoints which do not exist.
+//Prometheus pods can not start until all of them are created
+func (r *Reconsiler) missingRemoteStorageSecrets() []string {
+	var missing []string
+	for _, name := range model.RemoteStorageSecrets(r.CR) {
+		if r.CurrentState.RemoteStorageSecrets[name] == nil {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
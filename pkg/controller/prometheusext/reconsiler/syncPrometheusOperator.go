@@ -19,20 +19,10 @@ package reconsiler
 import "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/controller/prometheusext/model"
 
 func (r *Reconsiler) syncProOperatorDeployment() error {
-
-	if r.CurrentState.PrometheusOperatorDeployment == nil {
-		deployment := model.NewProOperatorDeployment(r.CR)
-		if err := r.createObject(deployment); err != nil {
-			log.Error(err, "Failed to create deployment for prometheus operator in cluster")
-			return err
-		}
-	} else {
-		deployment := model.UpdatedProOperatorDeployment(r.CR, r.CurrentState.PrometheusOperatorDeployment)
-
-		if err := r.updateObject(deployment); err != nil {
-			log.Error(err, "Failed to update prometheus operator deployment in cluster")
-			return err
-		}
+	deployment := model.NewProOperatorDeployment(r.CR)
+	if err := r.applyObject(r.CurrentState.PrometheusOperatorDeployment, deployment); err != nil {
+		log.Error(err, "Failed to apply deployment for prometheus operator in cluster")
+		return err
 	}
 	log.Info("prometheus operator deployment is sync")
 
@@ -0,0 +1,92 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package reconsiler
+
+import (
+	"testing"
+
+	promv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	monitoringv1alpha1 "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/controller/prometheusext/model"
+)
+
+func TestDeleteDefaultRulesOfCR(t *testing.T) {
+	controlled := true
+	rule := func(name string, labels map[string]string, ownerUID types.UID) *promv1.PrometheusRule {
+		rule := &promv1.PrometheusRule{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", Labels: labels}}
+		if ownerUID != "" {
+			rule.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: monitoringv1alpha1.SchemeGroupVersion.String(),
+				Kind:       "PrometheusExt",
+				Name:       string(ownerUID),
+				UID:        ownerUID,
+				Controller: &controlled,
+			}}
+		}
+		return rule
+	}
+	defaultLabel := map[string]string{model.DefaultRuleLabelKey: "true"}
+	newRules := func() []*promv1.PrometheusRule {
+		return []*promv1.PrometheusRule{
+			rule(string(model.NodeMem), defaultLabel, "uid"),
+			rule(string(model.NodeCPU), defaultLabel, "other-uid"),
+			rule(string(model.PodTerm), nil, ""),
+			rule(string(model.PodRestart), nil, "uid"),
+		}
+	}
+	wantKept := []string{string(model.NodeCPU), string(model.PodTerm), string(model.PodRestart)}
+
+	tests := []struct {
+		name    string
+		cleanup func(r *Reconsiler) error
+	}{
+		{name: "unused default rules", cleanup: func(r *Reconsiler) error {
+			return r.deleteUnusedDefaultRules(map[model.PrometheusRuleName]*promv1.PrometheusRule{})
+		}},
+		{name: "finalizer", cleanup: func(r *Reconsiler) error {
+			return r.cleanupPrometheusRules()
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := newTestScheme(t)
+			c := fake.NewFakeClientWithScheme(scheme)
+			r := newTestReconsiler(t, c)
+			for _, rule := range newRules() {
+				if err := c.Create(r.Context, rule); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := tt.cleanup(r); err != nil {
+				t.Fatalf("cleanup error = %v", err)
+			}
+			if err := c.Get(r.Context, client.ObjectKey{Namespace: "ns", Name: string(model.NodeMem)}, &promv1.PrometheusRule{}); err == nil {
+				t.Errorf("default rule %s of CR is not deleted", model.NodeMem)
+			}
+			for _, name := range wantKept {
+				if err := c.Get(r.Context, client.ObjectKey{Namespace: "ns", Name: name}, &promv1.PrometheusRule{}); err != nil {
+					t.Errorf("rule %s which is not a default rule of CR is deleted: %v", name, err)
+				}
+			}
+		})
+	}
+}
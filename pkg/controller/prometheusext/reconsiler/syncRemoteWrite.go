@@ -0,0 +1,169 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package reconsiler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	monitoringv1alpha1 "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/controller/prometheusext/model"
+)
+
+//remoteWriteProbeInterval is how often health of remote write endpoints is refreshed
+const remoteWriteProbeInterval = 5 * time.Minute
+
+//prometheusClient calls Prometheus API
+var prometheusClient = &http.Client{Timeout: 10 * time.Second}
+
+//syncRemoteWriteStatus reports health of remote write endpoints in status of CR by querying
+//prometheus_remote_storage_* metrics of managed prometheus. Failures of the queries do not fail reconciliation
+func (r *Reconsiler) syncRemoteWriteStatus() error {
+	endpoints := r.CR.Spec.PrometheusConfig.RemoteWrite
+	if len(endpoints) == 0 {
+		r.CR.Status.RemoteWrite = nil
+		return nil
+	}
+	now := metav1.Now()
+	old := make(map[string]monitoringv1alpha1.RemoteWriteStatus)
+	for _, status := range r.CR.Status.RemoteWrite {
+		old[status.URL] = status
+	}
+	// status is kept until the oldest probe of endpoints expires, so reconciliations in between do not change it
+	if next, ok := nextRemoteWriteProbe(endpoints, old); ok && now.Time.Before(next) {
+		statuses := make([]monitoringv1alpha1.RemoteWriteStatus, 0, len(endpoints))
+		for _, endpoint := range endpoints {
+			statuses = append(statuses, old[endpoint.URL])
+		}
+		r.CR.Status.RemoteWrite = statuses
+		r.requeueAt(next)
+		return nil
+	}
+	r.requeueAt(now.Add(remoteWriteProbeInterval))
+
+	var results []map[string]float64
+	var queryErr error
+	sts := r.CurrentState.PrometheusStatefulSet
+	if sts == nil || sts.Status.ReadyReplicas == 0 {
+		queryErr = fmt.Errorf("prometheus is not ready")
+	} else {
+		for _, query := range []string{model.RemoteWriteSucceededQuery, model.RemoteWriteFailedQuery, model.RemoteWriteLagQuery} {
+			result, err := r.queryPrometheus(query)
+			if err != nil {
+				log.Error(err, "failed to query remote write metrics of prometheus")
+				queryErr = err
+				break
+			}
+			results = append(results, result)
+		}
+	}
+
+	var statuses []monitoringv1alpha1.RemoteWriteStatus
+	for _, endpoint := range endpoints {
+		if queryErr != nil {
+			statuses = append(statuses, monitoringv1alpha1.RemoteWriteStatus{
+				URL:           endpoint.URL,
+				Healthy:       v1.ConditionUnknown,
+				Message:       "failed to query prometheus_remote_storage metrics: " + queryErr.Error(),
+				LastProbeTime: now,
+			})
+			continue
+		}
+		status := model.NewRemoteWriteStatus(endpoint.URL, results[0], results[1], results[2], now)
+		if status.Healthy == v1.ConditionFalse && old[endpoint.URL].Healthy != v1.ConditionFalse {
+			r.warningEvent(eventReasonRemoteWriteUnhealthy, "remote write to "+endpoint.URL+" is unhealthy: "+status.Message)
+		}
+		if status.Healthy == v1.ConditionTrue && old[endpoint.URL].Healthy == v1.ConditionFalse {
+			r.normalEvent(eventReasonRemoteWriteHealthy, "remote write to "+endpoint.URL+" is healthy again")
+		}
+		statuses = append(statuses, status)
+	}
+	r.CR.Status.RemoteWrite = statuses
+	return nil
+}
+
+//nextRemoteWriteProbe returns when status of endpoints should be probed again. It is false if an endpoint was never probed
+func nextRemoteWriteProbe(endpoints []monitoringv1alpha1.RemoteWrite, old map[string]monitoringv1alpha1.RemoteWriteStatus) (time.Time, bool) {
+	var next time.Time
+	for _, endpoint := range endpoints {
+		status, ok := old[endpoint.URL]
+		if !ok || status.LastProbeTime.IsZero() {
+			return time.Time{}, false
+		}
+		probe := status.LastProbeTime.Add(remoteWriteProbeInterval)
+		if next.IsZero() || probe.Before(next) {
+			next = probe
+		}
+	}
+	return next, true
+}
+
+//queryPrometheus runs instant query in managed prometheus and returns values of result series keyed by remote write url
+func (r *Reconsiler) queryPrometheus(query string) (map[string]float64, error) {
+	req, err := http.NewRequest(http.MethodGet, model.PrometheusAPIURL(r.CR)+"/query?query="+url.QueryEscape(query), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := prometheusClient.Do(req.WithContext(r.Context))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("query of prometheus failed with %s: %s", resp.Status, string(data))
+	}
+	result := struct {
+		Data struct {
+			Result []struct {
+				Metric map[string]string `json:"metric"`
+				Value  []interface{}     `json:"value"`
+			} `json:"result"`
+		} `json:"data"`
+	}{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("invalid response of prometheus: %v", err)
+	}
+	values := make(map[string]float64)
+	for _, series := range result.Data.Result {
+		if len(series.Value) != 2 {
+			continue
+		}
+		str, ok := series.Value[1].(string)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(str, 64)
+		if err != nil || math.IsNaN(value) {
+			continue
+		}
+		values[model.RemoteWriteSeriesURL(series.Metric)] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,142 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package reconsiler
+
+import (
+	"bytes"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/controller/prometheusext/model"
+)
+
+//syncSelfManagedSecrets creates CA and tls secrets by operator itself and rotates them before expiry.
+//When CA is rotated, tls secrets trust both CAs first, and are signed by new CA after CATrustOverlap
+func (r *Reconsiler) syncSelfManagedSecrets() error {
+	ca := r.CurrentState.MonitoringCASecret
+	rotate, reason := ca == nil, "CA is not created"
+	if ca != nil {
+		rotate, reason = model.CertNeedsRotation(r.CR, ca, nil, nil, nil)
+	}
+	if rotate {
+		log.Info("rotating monitoring CA: " + reason)
+		newCA, err := model.NewCASecret(r.CR, ca)
+		if err != nil {
+			log.Error(err, "failed to create CA for monitoring certificates")
+			return err
+		}
+		if err = r.applyObject(ca, newCA); err != nil {
+			log.Error(err, "failed to apply CA secret in cluster")
+			return err
+		}
+		ca = newCA
+	}
+	r.CurrentState.MonitoringCASecret = ca
+	log.Info("monitoring CA is sync")
+
+	secret, err := r.syncSelfManagedSecret(r.CurrentState.MonitoringSecret, r.CR.Spec.Certs.MonitoringSecret, ca,
+		model.MonitoringDNSNames(r.CR), model.MonitoringIPAddresses(r.CR))
+	if err != nil {
+		return err
+	}
+	r.CurrentState.MonitoringSecret = secret
+	log.Info("monitoring certificate is sync")
+
+	secret, err = r.syncSelfManagedSecret(r.CurrentState.MonitoringClientSecret, r.CR.Spec.Certs.MonitoringClientSecret, ca,
+		[]string{}, []string{})
+	if err != nil {
+		return err
+	}
+	r.CurrentState.MonitoringClientSecret = secret
+	log.Info("monitoring client certificate is sync")
+
+	r.requeueBeforeRotation(ca, r.CurrentState.MonitoringSecret, r.CurrentState.MonitoringClientSecret)
+	return nil
+}
+
+func (r *Reconsiler) syncSelfManagedSecret(currentSecret *v1.Secret, secretName string, ca *v1.Secret,
+	dnsNames []string, ipAddresses []string) (*v1.Secret, error) {
+	if currentSecret == nil {
+		secret, err := model.NewSelfManagedCertSecret(r.CR, secretName, ca, dnsNames, ipAddresses)
+		if err != nil {
+			log.Error(err, "failed to create tls secret object: "+secretName)
+			return nil, err
+		}
+		if err = r.applyObject(nil, secret); err != nil {
+			log.Error(err, "failed to create tls secret in cluster: "+secretName)
+			return nil, err
+		}
+		return secret, nil
+	}
+
+	if !model.IsSelfManagedSecret(currentSecret) && !r.CR.Spec.Certs.AutoClean {
+		// when it is not autoclean keep secret no matter who created it
+		log.Info("cert secret exists: " + secretName)
+		return currentSecret, nil
+	}
+	rotate, reason := model.CertNeedsRotation(r.CR, currentSecret, ca, dnsNames, ipAddresses)
+	if trustedAt := model.CATrustedAt(ca); rotate && reason == model.NotSignedByCurrentCA && time.Now().Before(trustedAt) {
+		//pods may not trust new CA yet, so only CA bundle is updated until the overlap ends
+		log.Info("tls secret " + secretName + " is signed by new CA at " + trustedAt.String())
+		rotate = false
+		r.requeueAt(trustedAt)
+	}
+	if !rotate && model.IsSelfManagedSecret(currentSecret) {
+		if bytes.Equal(currentSecret.Data[model.CACertKey], ca.Data[model.CACertKey]) {
+			return currentSecret, nil
+		}
+		log.Info("updating CA bundle of tls secret " + secretName)
+		secret := model.WithCABundle(currentSecret, ca)
+		if err := r.applyObject(currentSecret, secret); err != nil {
+			log.Error(err, "failed to update CA bundle of tls secret in cluster: "+secretName)
+			return nil, err
+		}
+		return secret, nil
+	}
+	log.Info("rotating tls secret " + secretName + ": " + reason)
+	secret, err := model.NewSelfManagedCertSecret(r.CR, secretName, ca, dnsNames, ipAddresses)
+	if err != nil {
+		log.Error(err, "failed to create updated tls secret object: "+secretName)
+		return nil, err
+	}
+	if err = r.applyObject(currentSecret, secret); err != nil {
+		log.Error(err, "failed to update tls secret in cluster: "+secretName)
+		return nil, err
+	}
+	return secret, nil
+}
+
+//requeueBeforeRotation makes sure the CR is reconciled again when the first certificate needs to be rotated
+func (r *Reconsiler) requeueBeforeRotation(secrets ...*v1.Secret) {
+	for _, secret := range secrets {
+		if secret == nil || !model.IsSelfManagedSecret(secret) {
+			continue
+		}
+		rotateAt, err := model.CertRotationTime(r.CR, secret)
+		if err != nil {
+			continue
+		}
+		after := time.Until(*rotateAt)
+		if after < time.Minute {
+			after = time.Minute
+		}
+		if r.RequeueAfter == 0 || after < r.RequeueAfter {
+			r.RequeueAfter = after
+		}
+	}
+}
@@ -0,0 +1,72 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package reconsiler
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	monitoringv1alpha1 "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/controller/prometheusext/model"
+)
+
+func TestCARotationOverlap(t *testing.T) {
+	r := newTestReconsiler(t, &patchClient{Client: fake.NewFakeClientWithScheme(newTestScheme(t))})
+	r.CR.Spec.Certs = monitoringv1alpha1.Certs{Mode: monitoringv1alpha1.CertsModeSelfManaged, KeyAlgorithm: "ecdsa",
+		MonitoringSecret: "monitoring-certs", MonitoringClientSecret: "monitoring-client-certs"}
+	if err := r.syncSelfManagedSecrets(); err != nil {
+		t.Fatal(err)
+	}
+	oldCA := r.CurrentState.MonitoringCASecret
+	oldCert := r.CurrentState.MonitoringSecret
+
+	newCA, err := model.NewCASecret(r.CR, oldCA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.CurrentState.MonitoringCASecret = newCA
+	r.RequeueAfter = 0
+	if err := r.syncSelfManagedSecrets(); err != nil {
+		t.Fatal(err)
+	}
+	cert := r.CurrentState.MonitoringSecret
+	if !bytes.Equal(cert.Data[v1.TLSCertKey], oldCert.Data[v1.TLSCertKey]) {
+		t.Errorf("certificate is signed by new CA before it is trusted")
+	}
+	if !bytes.Equal(cert.Data[model.CACertKey], newCA.Data[model.CACertKey]) {
+		t.Errorf("ca.crt is not CA bundle with old and new CA")
+	}
+	if r.RequeueAfter <= 0 || r.RequeueAfter > model.CATrustOverlap {
+		t.Errorf("RequeueAfter = %v, want end of overlap", r.RequeueAfter)
+	}
+
+	newCA.Annotations[model.CARotatedAnn] = time.Now().Add(-model.CATrustOverlap - time.Minute).UTC().Format(time.RFC3339)
+	if err := r.syncSelfManagedSecrets(); err != nil {
+		t.Fatal(err)
+	}
+	cert = r.CurrentState.MonitoringSecret
+	if rotate, reason := model.CertNeedsRotation(r.CR, cert, newCA, model.MonitoringDNSNames(r.CR), model.MonitoringIPAddresses(r.CR)); rotate {
+		t.Errorf("certificate is not signed by new CA after overlap: %s", reason)
+	}
+	if !bytes.Equal(cert.Data[model.CACertKey], newCA.Data[model.CACertKey]) {
+		t.Errorf("ca.crt of re-signed certificate is not CA bundle")
+	}
+}
@@ -0,0 +1,59 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package reconsiler
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/controller/prometheusext/model"
+)
+
+//readThanosGRPCService reads service of Thanos sidecar Store API. It is nil if it does not exist
+func (r *Reconsiler) readThanosGRPCService() (*v1.Service, error) {
+	svc := &v1.Service{}
+	key := client.ObjectKey{Namespace: r.CR.Namespace, Name: model.ThanosGRPCServiceName(r.CR)}
+	if err := r.Client.Get(r.Context, key, svc); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		log.Error(err, "failed to get service of Thanos sidecar")
+		return nil, err
+	}
+	return svc, nil
+}
+
+//syncThanosGRPCService applies service of Thanos sidecar Store API when Thanos is enabled and deletes it when it is disabled
+func (r *Reconsiler) syncThanosGRPCService(live *v1.Service) error {
+	if model.IsThanosEnabled(r.CR) {
+		if err := r.applyObject(live, model.NewThanosGRPCService(r.CR)); err != nil {
+			log.Error(err, "Failed to apply service for Thanos sidecar")
+			return err
+		}
+		log.Info("thanos sidecar service is sync")
+		return nil
+	}
+	if live != nil {
+		if err := r.deleteObject(live); err != nil {
+			log.Error(err, "Failed to delete service for Thanos sidecar")
+			return err
+		}
+		r.normalEvent(eventReasonDeleted, "deleted Service "+live.Name+" because Thanos sidecar is disabled")
+	}
+	return nil
+}
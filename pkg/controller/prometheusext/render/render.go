@@ -0,0 +1,278 @@
+//
+// Copyright 2020 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package render
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	promv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	certv1alpha1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	routev1 "github.com/openshift/api/route/v1"
+	secv1 "github.com/openshift/api/security/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+
+	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis"
+	promext "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1alpha1"
+	promextv1beta1 "github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/apis/monitoring/v1beta1"
+	"github.com/IBM/ibm-monitoring-prometheus-operator-ext/pkg/controller/prometheusext/model"
+)
+
+//Options are inputs of rendering which are read from cluster by the controller
+type Options struct {
+	//Storage class of prometheus and alertmanager volumes if it is not set in CR
+	StorageClass string
+	//Host name of cluster which is used in external urls of prometheus and alertmanager
+	ClusterHost string
+}
+
+//ReadCR reads PrometheusExt object from yaml
+//v1beta1 object is converted to v1alpha1 which is used by controller
+func ReadCR(data []byte, namespace string) (*promext.PrometheusExt, error) {
+	typeMeta := &metav1.TypeMeta{}
+	if err := yaml.Unmarshal(data, typeMeta); err != nil {
+		return nil, fmt.Errorf("failed to parse PrometheusExt: %v", err)
+	}
+	cr := &promext.PrometheusExt{}
+	if typeMeta.APIVersion == promextv1beta1.SchemeGroupVersion.String() {
+		betaCR := &promextv1beta1.PrometheusExt{}
+		if err := yaml.UnmarshalStrict(data, betaCR); err != nil {
+			return nil, fmt.Errorf("failed to parse PrometheusExt: %v", err)
+		}
+		if err := betaCR.ConvertTo(cr); err != nil {
+			return nil, fmt.Errorf("failed to convert PrometheusExt: %v", err)
+		}
+	} else if err := yaml.UnmarshalStrict(data, cr); err != nil {
+		return nil, fmt.Errorf("failed to parse PrometheusExt: %v", err)
+	}
+	if namespace != "" {
+		cr.Namespace = namespace
+	}
+	if cr.Namespace == "" {
+		cr.Namespace = "default"
+	}
+	return cr, nil
+}
+
+//Objects returns all objects which controller creates for the CR
+//Objects depending on cluster state, like config hashes of pods and values of secrets referred by alertmanager routing, are not rendered
+func Objects(cr *promext.PrometheusExt, opts Options) ([]runtime.Object, error) {
+	cr = cr.DeepCopy()
+	if cr.Annotations == nil {
+		cr.Annotations = make(map[string]string)
+	}
+	if cr.Spec.StorageClassName != "" {
+		cr.Annotations[model.StorageClassAnn] = cr.Spec.StorageClassName
+	} else {
+		cr.Annotations[model.StorageClassAnn] = opts.StorageClass
+	}
+	cr.Annotations[model.ClusterHostAnn] = opts.ClusterHost
+
+	var objs []runtime.Object
+	if model.IsOpenShift() {
+		objs = append(objs, model.NewSCC(cr.Namespace))
+	}
+	objs = append(objs, model.NewProOperatorDeployment(cr))
+
+	objs = append(objs, certObjects(cr)...)
+
+	cms, err := routerCms(cr)
+	if err != nil {
+		return nil, err
+	}
+	objs = append(objs, cms...)
+
+	targets, err := model.NewScrapeTargetsSecret(cr)
+	if err != nil {
+		return nil, err
+	}
+	prometheus, err := model.NewPrometheus(cr)
+	if err != nil {
+		return nil, err
+	}
+	objs = append(objs, targets, prometheus, model.NewPrometheusSvc(cr))
+	objs = append(objs, exposureObjects(cr, model.Prometheus)...)
+	objs = append(objs, podDisruptionBudgets(cr, model.Prometheus)...)
+	if model.IsThanosEnabled(cr) {
+		objs = append(objs, model.NewThanosGRPCService(cr))
+	}
+
+	rules, err := prometheusRules(cr)
+	if err != nil {
+		return nil, err
+	}
+	objs = append(objs, rules...)
+
+	amConfig, err := alertmanagerConfigSecret(cr)
+	if err != nil {
+		return nil, err
+	}
+	alertmanager, err := model.NewAlertmanager(cr)
+	if err != nil {
+		return nil, err
+	}
+	objs = append(objs, amConfig, alertmanager, model.NewAlertmanagetSvc(cr))
+	objs = append(objs, exposureObjects(cr, model.Alertmanager)...)
+	objs = append(objs, podDisruptionBudgets(cr, model.Alertmanager)...)
+
+	mcmCtl, err := model.NewMCMCtlDeployment(cr)
+	if err != nil {
+		return nil, err
+	}
+	objs = append(objs, mcmCtl)
+	return objs, nil
+}
+
+func certObjects(cr *promext.PrometheusExt) []runtime.Object {
+	if !model.IsSelfManagedCerts(cr) {
+		return []runtime.Object{
+			model.NewCertitication(cr.Spec.Certs.MonitoringSecret, cr, model.MonitoringDNSNames(cr), model.MonitoringIPAddresses(cr)),
+			model.NewCertitication(cr.Spec.Certs.MonitoringClientSecret, cr, []string{}, []string{}),
+		}
+	}
+	//keys are created by operator in cluster and are not printed
+	return []runtime.Object{
+		model.NewSelfManagedCertPlaceholder(cr, model.CASecretName(cr)),
+		model.NewSelfManagedCertPlaceholder(cr, cr.Spec.Certs.MonitoringSecret),
+		model.NewSelfManagedCertPlaceholder(cr, cr.Spec.Certs.MonitoringClientSecret),
+	}
+}
+
+//exposureObjects returns ingress or route selected in exposure of prometheus or alertmanager.
+//CA certificate of route is a placeholder because certificates are created in cluster
+func exposureObjects(cr *promext.PrometheusExt, ot model.ObjectType) []runtime.Object {
+	switch model.ExposureOf(cr, ot).Type {
+	case promext.ExposureIngress:
+		return []runtime.Object{model.NewIngress(cr, ot)}
+	case promext.ExposureRoute:
+		ca := fmt.Sprintf("<secret %s/%s key %s>", cr.Namespace, cr.Spec.Certs.MonitoringSecret, model.CACertKey)
+		return []runtime.Object{model.NewRoute(cr, ot, ca)}
+	default:
+		return nil
+	}
+}
+
+//podDisruptionBudgets returns pod disruption budget of prometheus or alertmanager if it has more than one replica
+func podDisruptionBudgets(cr *promext.PrometheusExt, ot model.ObjectType) []runtime.Object {
+	if model.Replicas(cr, ot) > 1 {
+		return []runtime.Object{model.NewPodDisruptionBudget(cr, ot)}
+	}
+	return nil
+}
+
+func routerCms(cr *promext.PrometheusExt) ([]runtime.Object, error) {
+	entry, err := model.NewRouterEntryCm(cr)
+	if err != nil {
+		return nil, err
+	}
+	promeNg, err := model.NewProRouterNgCm(cr)
+	if err != nil {
+		return nil, err
+	}
+	lua, err := model.NewProLuaCm(cr)
+	if err != nil {
+		return nil, err
+	}
+	luaUtils, err := model.NewProLuaUtilsCm(cr)
+	if err != nil {
+		return nil, err
+	}
+	return []runtime.Object{entry, promeNg, lua, luaUtils, model.NewAlertmanagerRouterNgCm(cr)}, nil
+}
+
+func prometheusRules(cr *promext.PrometheusExt) ([]runtime.Object, error) {
+	rules, err := model.DefaultPrometheusRules(cr)
+	if err != nil {
+		return nil, err
+	}
+	var objs []runtime.Object
+	for _, name := range model.DefaultPrometheusRuleNames() {
+		rule, ok := rules[name]
+		if !ok {
+			continue
+		}
+		rule.ObjectMeta = metav1.ObjectMeta{
+			Name:      string(name),
+			Namespace: cr.Namespace,
+			Labels:    model.DefaultRuleLabels(cr),
+		}
+		objs = append(objs, rule)
+	}
+	return objs, nil
+}
+
+func alertmanagerConfigSecret(cr *promext.PrometheusExt) (*v1.Secret, error) {
+	secret := model.AlertmanagerConfigSecret(cr)
+	if cr.Spec.AlertManagerConfig.Routing == nil {
+		return secret, nil
+	}
+	config, _, err := model.AlertmanagerConfigYaml(cr, nil, secretPlaceholder)
+	if err != nil {
+		return nil, err
+	}
+	secret.Data[model.AlertmanagerConfigKey] = config
+	return secret, nil
+}
+
+//secretPlaceholder is used instead of values of secrets which can not be read without cluster.
+//It is an url because secrets of webhook and slack receivers store urls which are checked before configuration is written
+func secretPlaceholder(namespace string, selector *v1.SecretKeySelector) (string, error) {
+	return fmt.Sprintf("https://secret.invalid/%s/%s/%s", namespace, selector.Name, selector.Key), nil
+}
+
+//NewScheme returns scheme of all rendered object types
+func NewScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{
+		clientgoscheme.AddToScheme,
+		apis.AddToScheme,
+		promv1.AddToScheme,
+		certv1alpha1.AddToScheme,
+		secv1.Install,
+		routev1.Install,
+	} {
+		if err := add(scheme); err != nil {
+			return nil, err
+		}
+	}
+	return scheme, nil
+}
+
+//WriteYaml writes objects as multi document yaml
+func WriteYaml(w io.Writer, scheme *runtime.Scheme, objs []runtime.Object) error {
+	for _, obj := range objs {
+		gvks, _, err := scheme.ObjectKinds(obj)
+		if err != nil {
+			return err
+		}
+		sort.Slice(gvks, func(i, j int) bool { return gvks[i].String() < gvks[j].String() })
+		obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "---\n%s", data); err != nil {
+			return err
+		}
+	}
+	return nil
+}